@@ -4,9 +4,13 @@ import (
 	"avito-backend-intern-winter25/config"
 	"avito-backend-intern-winter25/internal/handlers"
 	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/domain"
 	"avito-backend-intern-winter25/internal/services"
 	"avito-backend-intern-winter25/internal/services/jwt"
+	"avito-backend-intern-winter25/internal/storage/cache"
+	"avito-backend-intern-winter25/internal/storage/localfs"
 	"avito-backend-intern-winter25/internal/storage/postgres"
+	"avito-backend-intern-winter25/internal/worker"
 	"context"
 	"database/sql"
 	"fmt"
@@ -14,6 +18,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 	"os"
 	"runtime"
@@ -67,14 +72,105 @@ func main() {
 
 	usrRepo := postgres.NewUserRepository(db)
 	purchaseRepo := postgres.NewPurchaseRepository(db)
-	merchRepo := postgres.NewMerchRepository(db)
+	merchRepo := cache.NewMerchRepository(postgres.NewMerchRepository(db), redisClient, cfg.Cache.LocalTTL, cfg.Cache.RedisTTL)
 	transactionRepo := postgres.NewTransactionRepository(db)
+	statementRepo := postgres.NewStatementRepository(db)
+	walletRepo := postgres.NewWalletRepository(db)
+	holdRepo := postgres.NewHoldRepository(db)
+	merchAuditRepo := postgres.NewMerchAuditRepository(db)
+	merchPriceRepo := postgres.NewMerchPriceRepository(db)
+	orderRepo := postgres.NewOrderRepository(db)
+	refundRepo := postgres.NewRefundRepository(db)
+	cartRepo := postgres.NewCartRepository(db)
+	promoRepo := postgres.NewPromoCodeRepository(db)
+	merchImageRepo := postgres.NewMerchImageRepository(db)
+	merchVariantRepo := postgres.NewMerchVariantRepository(db)
+	merchRuleRepo := postgres.NewMerchRuleRepository(db)
+	merchDropRepo := postgres.NewMerchDropRepository(db)
+	merchBundleRepo := postgres.NewMerchBundleRepository(db)
+	notificationRepo := postgres.NewNotificationRepository(db)
+	wishlistRepo := postgres.NewWishlistRepository(db)
+	auctionRepo := postgres.NewAuctionRepository(db)
+	raffleRepo := postgres.NewRaffleRepository(db)
+	groupBuyRepo := postgres.NewGroupBuyRepository(db)
+	reviewRepo := postgres.NewReviewRepository(db)
+	recommendationRepo := postgres.NewRecommendationRepository(db)
+
+	blobStore, err := localfs.NewBlobStore(cfg.Media.Dir, cfg.Media.PublicURL)
+	if err != nil {
+		logger.Fatal("Failed to initialize media storage", zap.Error(err))
+	}
 
-	usrService := services.NewUserService(usrRepo, jwtService, redisClient)
-	merchService := services.NewMerchService(merchRepo, purchaseRepo, usrRepo, db)
-	transactionService := services.NewTransactionService(db, usrRepo, transactionRepo)
+	feePolicy := domain.FeePolicy{
+		Flat:                  cfg.Fees.Flat,
+		Percent:               cfg.Fees.Percent,
+		Min:                   cfg.Fees.Min,
+		Max:                   cfg.Fees.Max,
+		FreeTransfersPerMonth: cfg.Fees.FreeTransfersPerMonth,
+	}
+	if cfg.Fees.Account != "" {
+		feeAccount, err := usrRepo.FindByUsername(ctx, cfg.Fees.Account)
+		if err != nil {
+			logger.Fatal("Failed to find fee account", zap.String("account", cfg.Fees.Account), zap.Error(err))
+		}
+		feePolicy.AccountID = feeAccount.ID
+	}
 
-	handler := handlers.NewHandler(usrService, merchService, transactionService, *logger)
+	usrService := services.NewUserService(usrRepo, jwtService, redisClient)
+	merchService := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, promoRepo, usrRepo, walletRepo, merchImageRepo,
+		merchVariantRepo, merchRuleRepo, merchDropRepo, merchBundleRepo, notificationRepo, blobStore, db)
+	transactionService := services.NewTransactionService(db, usrRepo, transactionRepo, walletRepo, feePolicy)
+	statementService := services.NewStatementService(statementRepo, usrRepo, db)
+	walletService := services.NewWalletService(walletRepo, usrRepo)
+	holdService := services.NewHoldService(holdRepo, usrRepo, walletRepo, db, cfg.Holds.DefaultTTL)
+	merchAdminService := services.NewMerchAdminService(merchRepo, merchAuditRepo, merchPriceRepo, walletRepo, merchImageRepo,
+		merchVariantRepo, merchRuleRepo, merchDropRepo, merchBundleRepo, blobStore, db)
+	cartService := services.NewCartService(cartRepo, merchRepo, merchService, db)
+	orderService := services.NewOrderService(orderRepo, purchaseRepo, refundRepo, merchRepo, merchVariantRepo, merchDropRepo, promoRepo, usrRepo, walletRepo, db,
+		cfg.Orders.CancellationWindow)
+	promoService := services.NewPromoService(promoRepo, walletRepo, db)
+	notificationService := services.NewNotificationService(notificationRepo)
+	wishlistService := services.NewWishlistService(wishlistRepo, merchRepo, usrRepo, notificationRepo, db)
+	auctionService := services.NewAuctionService(auctionRepo, merchRepo, merchService, orderRepo, purchaseRepo, notificationRepo, holdService, db)
+	raffleService := services.NewRaffleService(raffleRepo, merchRepo, merchService, orderRepo, purchaseRepo, usrRepo, notificationRepo, db)
+	groupBuyPolicy := domain.GroupBuyPolicy{
+		MaxQuantity:       cfg.GroupBuys.MaxQuantity,
+		MaxOpenPerCreator: cfg.GroupBuys.MaxOpenPerCreator,
+		MaxDuration:       cfg.GroupBuys.MaxDuration,
+	}
+	groupBuyService := services.NewGroupBuyService(groupBuyRepo, merchRepo, merchService, orderRepo, purchaseRepo, notificationRepo, holdService, db,
+		groupBuyPolicy)
+	reviewService := services.NewReviewService(reviewRepo, merchRepo, purchaseRepo, db)
+	recommendationService := services.NewRecommendationService(recommendationRepo, merchRepo, usrRepo, db)
+
+	catalogListener := pq.NewListener(connectionString, time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Catalog listener error", zap.Error(err))
+		}
+	})
+	defer func() {
+		if err := catalogListener.Close(); err != nil {
+			logger.Warn("Failed to close catalog listener", zap.Error(err))
+		}
+	}()
+	if err := catalogListener.Listen(cache.CatalogChannel); err != nil {
+		logger.Fatal("Failed to listen for catalog changes", zap.Error(err))
+	}
+	go merchRepo.Listen(ctx, catalogListener)
+
+	scheduler := worker.NewScheduler(logger)
+	scheduler.Add("monthly-statements", cfg.Jobs.StatementInterval, statementService.GenerateMonthlyStatements)
+	scheduler.Add("expire-holds", cfg.Jobs.HoldExpiryInterval, holdService.ExpireHolds)
+	scheduler.Add("merch-stock-metrics", cfg.Jobs.StockMetricsInterval, merchService.RefreshStockMetrics)
+	scheduler.Add("wishlist-alerts", cfg.Jobs.WishlistAlertInterval, wishlistService.SendAlerts)
+	scheduler.Add("settle-auctions", cfg.Jobs.AuctionSettleInterval, auctionService.SettleAuctions)
+	scheduler.Add("draw-raffles", cfg.Jobs.RaffleDrawInterval, raffleService.DrawRaffles)
+	scheduler.Add("expire-group-buys", cfg.Jobs.GroupBuyExpiryInterval, groupBuyService.ExpireGroupBuys)
+	scheduler.Add("refresh-recommendations", cfg.Jobs.RecommendationInterval, recommendationService.RefreshRecommendations)
+	scheduler.Add("settle-transfer-fees", cfg.Jobs.FeeSettlementInterval, transactionService.SettleFees)
+	scheduler.Start(ctx)
+
+	handler := handlers.NewHandler(usrService, merchService, transactionService, statementService, walletService, holdService, merchAdminService, cartService, orderService, promoService, notificationService, wishlistService, auctionService, raffleService, groupBuyService, reviewService, recommendationService, blobStore, *logger)
 
 	r := gin.Default()
 	r.Use(
@@ -8,10 +8,17 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Postgres PostgresConfig `yaml:"postgres"`
-	JWT      JWTConfig      `yaml:"jwt"`
-	Redis    RedisConfig    `yaml:"redis"`
+	Server    ServerConfig    `yaml:"server"`
+	Postgres  PostgresConfig  `yaml:"postgres"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Redis     RedisConfig     `yaml:"redis"`
+	Jobs      JobsConfig      `yaml:"jobs"`
+	Fees      FeesConfig      `yaml:"fees"`
+	Holds     HoldsConfig     `yaml:"holds"`
+	Orders    OrdersConfig    `yaml:"orders"`
+	GroupBuys GroupBuysConfig `yaml:"group_buys"`
+	Media     MediaConfig     `yaml:"media"`
+	Cache     CacheConfig     `yaml:"cache"`
 }
 
 type ServerConfig struct {
@@ -26,6 +33,54 @@ type RedisConfig struct {
 	Db       int    `yaml:"db"`
 }
 
+type JobsConfig struct {
+	StatementInterval      time.Duration `yaml:"statement_interval"`
+	HoldExpiryInterval     time.Duration `yaml:"hold_expiry_interval"`
+	StockMetricsInterval   time.Duration `yaml:"stock_metrics_interval"`
+	WishlistAlertInterval  time.Duration `yaml:"wishlist_alert_interval"`
+	AuctionSettleInterval  time.Duration `yaml:"auction_settle_interval"`
+	RaffleDrawInterval     time.Duration `yaml:"raffle_draw_interval"`
+	GroupBuyExpiryInterval time.Duration `yaml:"group_buy_expiry_interval"`
+	RecommendationInterval time.Duration `yaml:"recommendation_interval"`
+	FeeSettlementInterval  time.Duration `yaml:"fee_settlement_interval"`
+}
+
+type HoldsConfig struct {
+	DefaultTTL time.Duration `yaml:"default_ttl"`
+}
+
+type OrdersConfig struct {
+	CancellationWindow time.Duration `yaml:"cancellation_window"`
+}
+
+type GroupBuysConfig struct {
+	MaxQuantity       int           `yaml:"max_quantity"`
+	MaxOpenPerCreator int           `yaml:"max_open_per_creator"`
+	MaxDuration       time.Duration `yaml:"max_duration"`
+}
+
+// MediaConfig - локальное хранилище загруженных картинок и путь, по которому приложение их раздает.
+type MediaConfig struct {
+	Dir       string `yaml:"dir"`
+	PublicURL string `yaml:"public_url"`
+}
+
+// CacheConfig - время жизни снимка каталога в памяти процесса и в Redis. Изменения каталога сбрасывают
+// кэш сразу через LISTEN/NOTIFY, TTL ограничивает устаревание остатков и работу без уведомлений.
+type CacheConfig struct {
+	LocalTTL time.Duration `yaml:"local_ttl"`
+	RedisTTL time.Duration `yaml:"redis_ttl"`
+}
+
+type FeesConfig struct {
+	Account               string  `yaml:"account"`
+	Flat                  int     `yaml:"flat"`
+	Percent               float64 `yaml:"percent"`
+	Min                   int     `yaml:"min"`
+	Max                   int     `yaml:"max"`
+	FreeTransfersPerMonth int     `yaml:"free_transfers_per_month"`
+}
+
 type PostgresConfig struct {
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
@@ -91,5 +146,65 @@ func validateConfig(cfg *Config) error {
 	if cfg.Server.Port <= 0 {
 		return fmt.Errorf("server port must be positive")
 	}
+	if cfg.Fees.Flat < 0 || cfg.Fees.Percent < 0 || cfg.Fees.Min < 0 || cfg.Fees.Max < 0 {
+		return fmt.Errorf("transfer fees must not be negative")
+	}
+	if cfg.Fees.Max > 0 && cfg.Fees.Min > cfg.Fees.Max {
+		return fmt.Errorf("minimal transfer fee must not exceed maximal")
+	}
+	if cfg.Jobs.StatementInterval <= 0 {
+		cfg.Jobs.StatementInterval = time.Hour
+	}
+	if cfg.Jobs.HoldExpiryInterval <= 0 {
+		cfg.Jobs.HoldExpiryInterval = time.Minute
+	}
+	if cfg.Jobs.StockMetricsInterval <= 0 {
+		cfg.Jobs.StockMetricsInterval = time.Minute
+	}
+	if cfg.Jobs.WishlistAlertInterval <= 0 {
+		cfg.Jobs.WishlistAlertInterval = 5 * time.Minute
+	}
+	if cfg.Jobs.AuctionSettleInterval <= 0 {
+		cfg.Jobs.AuctionSettleInterval = time.Minute
+	}
+	if cfg.Jobs.RaffleDrawInterval <= 0 {
+		cfg.Jobs.RaffleDrawInterval = time.Minute
+	}
+	if cfg.Jobs.GroupBuyExpiryInterval <= 0 {
+		cfg.Jobs.GroupBuyExpiryInterval = time.Minute
+	}
+	if cfg.Jobs.RecommendationInterval <= 0 {
+		cfg.Jobs.RecommendationInterval = time.Hour
+	}
+	if cfg.Jobs.FeeSettlementInterval <= 0 {
+		cfg.Jobs.FeeSettlementInterval = time.Minute
+	}
+	if cfg.Orders.CancellationWindow < 0 {
+		return fmt.Errorf("order cancellation window must not be negative")
+	}
+	if cfg.GroupBuys.MaxQuantity <= 0 {
+		cfg.GroupBuys.MaxQuantity = 10
+	}
+	if cfg.GroupBuys.MaxOpenPerCreator <= 0 {
+		cfg.GroupBuys.MaxOpenPerCreator = 3
+	}
+	if cfg.GroupBuys.MaxDuration <= 0 {
+		cfg.GroupBuys.MaxDuration = 7 * 24 * time.Hour
+	}
+	if cfg.Media.Dir == "" {
+		cfg.Media.Dir = "media"
+	}
+	if cfg.Media.PublicURL == "" {
+		cfg.Media.PublicURL = "/media"
+	}
+	if cfg.Holds.DefaultTTL <= 0 {
+		cfg.Holds.DefaultTTL = 72 * time.Hour
+	}
+	if cfg.Cache.LocalTTL <= 0 {
+		cfg.Cache.LocalTTL = 10 * time.Second
+	}
+	if cfg.Cache.RedisTTL <= 0 {
+		cfg.Cache.RedisTTL = time.Minute
+	}
 	return nil
 }
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (h *Handler) ListAuctions(c *gin.Context) {
+	auctions, err := h.auctionService.ListAuctions(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get auctions"})
+		return
+	}
+
+	resp := make([]*response.AuctionResponse, len(auctions))
+	for i, a := range auctions {
+		resp[i] = response.AuctionResponseFromModel(a, nil)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) GetAuction(c *gin.Context) {
+	auctionID, ok := auctionIDParam(c)
+	if !ok {
+		return
+	}
+
+	auction, bids, err := h.auctionService.GetAuction(c, auctionID)
+	if err != nil {
+		h.writeAuctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AuctionResponseFromModel(auction, bids))
+}
+
+func (h *Handler) PlaceBid(c *gin.Context) {
+	auctionID, ok := auctionIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.PlaceBidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	auction, err := h.auctionService.PlaceBid(c, middleware.GetUserID(c), auctionID, req.Amount)
+	if err != nil {
+		h.writeAuctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AuctionResponseFromModel(auction, nil))
+}
+
+func (h *Handler) AdminCreateAuction(c *gin.Context) {
+	var req request.CreateAuctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+	if req.StartsAt.IsZero() {
+		req.StartsAt = time.Now()
+	}
+
+	auction, err := h.auctionService.CreateAuction(c, middleware.GetUserID(c), req.Item, &domain.Auction{
+		StartingPrice: req.StartingPrice,
+		MinIncrement:  req.MinIncrement,
+		AntiSnipe:     time.Duration(req.AntiSnipeSeconds) * time.Second,
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
+	})
+	if err != nil {
+		h.writeAuctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.AuctionResponseFromModel(auction, nil))
+}
+
+func (h *Handler) AdminCancelAuction(c *gin.Context) {
+	auctionID, ok := auctionIDParam(c)
+	if !ok {
+		return
+	}
+
+	auction, err := h.auctionService.CancelAuction(c, auctionID)
+	if err != nil {
+		h.writeAuctionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AuctionResponseFromModel(auction, nil))
+}
+
+func auctionIDParam(c *gin.Context) (int64, bool) {
+	auctionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid auction id"})
+		return 0, false
+	}
+	return auctionID, true
+}
+
+func (h *Handler) writeAuctionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrAuctionNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "auction not found"})
+	case errors.Is(err, storage.ErrMerchNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "merch not found"})
+	case errors.Is(err, services.ErrAuctionNotOpen),
+		errors.Is(err, services.ErrOutOfStock):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrInsufficientAvailable):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "insufficient available balance"})
+	case errors.Is(err, services.ErrPurchaseRestricted),
+		errors.Is(err, services.ErrAccountTooNew),
+		errors.Is(err, services.ErrPurchaseLimitExceeded):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrBidTooLow),
+		errors.Is(err, services.ErrInvalidAuction),
+		errors.Is(err, services.ErrBundleUnsupported),
+		errors.Is(err, services.ErrVariantsUnsupported),
+		errors.Is(err, services.ErrUnknownCurrency):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	default:
+		h.logger.Error("auction failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to process auction"})
+	}
+}
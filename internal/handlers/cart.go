@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (h *Handler) GetCart(c *gin.Context) {
+	items, err := h.cartService.GetCart(c, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get cart"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CartResponseFromModel(items))
+}
+
+func (h *Handler) AddCartItem(c *gin.Context) {
+	var req request.AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	if err := h.cartService.AddItem(c, middleware.GetUserID(c), req.Item, req.Variant, req.Quantity); err != nil {
+		h.writeOrderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// RemoveCartItem удаляет товар из корзины, ?variant=SKU - только один вариант.
+func (h *Handler) RemoveCartItem(c *gin.Context) {
+	if err := h.cartService.RemoveItem(c, middleware.GetUserID(c), c.Param("item"), c.Query("variant")); err != nil {
+		h.writeOrderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) Checkout(c *gin.Context) {
+	// тело необязательно: без него корзина оформляется без промокода
+	var req request.CheckoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+			return
+		}
+	}
+
+	order, err := h.cartService.Checkout(c, middleware.GetUserID(c), req.PromoCode)
+	if err != nil {
+		h.writeOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.OrderResponseFromModel(order))
+}
+
+func (h *Handler) writeOrderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInsufficientCoins):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "insufficient coins"})
+	case errors.Is(err, services.ErrInvalidQuantity),
+		errors.Is(err, services.ErrCartEmpty),
+		errors.Is(err, services.ErrVariantRequired),
+		errors.Is(err, services.ErrGiftToSelf),
+		errors.Is(err, services.ErrInvalidGiftMessage):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrPurchaseRestricted),
+		errors.Is(err, services.ErrAccountTooNew),
+		errors.Is(err, services.ErrPurchaseLimitExceeded):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrInvalidPromoCode):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrPromoCodeExpired),
+		errors.Is(err, services.ErrPromoCodeExhausted),
+		errors.Is(err, services.ErrPromoCodeUserLimit),
+		errors.Is(err, services.ErrPromoCodeNotApplicable),
+		errors.Is(err, services.ErrPromoCodeMinSpend):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, storage.ErrMerchNotFound),
+		errors.Is(err, storage.ErrCartItemNotFound),
+		errors.Is(err, services.ErrVariantNotFound),
+		errors.Is(err, services.ErrGiftRecipientNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrOutOfStock),
+		errors.Is(err, services.ErrMerchUnavailable),
+		errors.Is(err, services.ErrNotOnSaleYet),
+		errors.Is(err, services.ErrSaleEnded):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: err.Error()})
+	default:
+		h.logger.Error("order failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to purchase item"})
+	}
+}
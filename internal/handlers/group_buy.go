@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) ListGroupBuys(c *gin.Context) {
+	groupBuys, err := h.groupBuyService.ListGroupBuys(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get group buys"})
+		return
+	}
+
+	resp := make([]*response.GroupBuyResponse, len(groupBuys))
+	for i, g := range groupBuys {
+		resp[i] = response.GroupBuyResponseFromModel(g, nil)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) GetGroupBuy(c *gin.Context) {
+	groupBuyID, ok := groupBuyIDParam(c)
+	if !ok {
+		return
+	}
+
+	groupBuy, pledges, err := h.groupBuyService.GetGroupBuy(c, groupBuyID)
+	if err != nil {
+		h.writeGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.GroupBuyResponseFromModel(groupBuy, pledges))
+}
+
+func (h *Handler) CreateGroupBuy(c *gin.Context) {
+	var req request.CreateGroupBuyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	groupBuy, err := h.groupBuyService.CreateGroupBuy(c, middleware.GetUserID(c), req.Item, req.Quantity, req.Deadline)
+	if err != nil {
+		h.writeGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.GroupBuyResponseFromModel(groupBuy, nil))
+}
+
+func (h *Handler) PledgeGroupBuy(c *gin.Context) {
+	groupBuyID, ok := groupBuyIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.PledgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	groupBuy, err := h.groupBuyService.Pledge(c, middleware.GetUserID(c), groupBuyID, req.Amount)
+	if err != nil {
+		h.writeGroupBuyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.GroupBuyResponseFromModel(groupBuy, nil))
+}
+
+func groupBuyIDParam(c *gin.Context) (int64, bool) {
+	groupBuyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid group buy id"})
+		return 0, false
+	}
+	return groupBuyID, true
+}
+
+func (h *Handler) writeGroupBuyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrGroupBuyNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "group buy not found"})
+	case errors.Is(err, storage.ErrMerchNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "merch not found"})
+	case errors.Is(err, services.ErrGroupBuyNotOpen),
+		errors.Is(err, services.ErrOutOfStock),
+		errors.Is(err, services.ErrMerchUnavailable),
+		errors.Is(err, services.ErrBundleUnsupported),
+		errors.Is(err, services.ErrTooManyGroupBuys):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrInsufficientAvailable):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "insufficient available balance"})
+	case errors.Is(err, services.ErrPurchaseRestricted),
+		errors.Is(err, services.ErrAccountTooNew),
+		errors.Is(err, services.ErrPurchaseLimitExceeded):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrPledgeExceedsTarget),
+		errors.Is(err, services.ErrInvalidGroupBuy),
+		errors.Is(err, services.ErrVariantsUnsupported),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnknownCurrency):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	default:
+		h.logger.Error("group buy failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to process group buy"})
+	}
+}
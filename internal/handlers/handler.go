@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/domain"
 	"avito-backend-intern-winter25/internal/models/http/request"
 	"avito-backend-intern-winter25/internal/models/http/response"
 	"avito-backend-intern-winter25/internal/services"
 	"avito-backend-intern-winter25/internal/services/jwt"
+	"avito-backend-intern-winter25/internal/storage"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -15,23 +17,68 @@ import (
 )
 
 type Handler struct {
-	userService        *services.UserService
-	merchService       *services.MerchService
-	transactionService *services.TransactionService
-	logger             zap.Logger
+	userService           *services.UserService
+	merchService          *services.MerchService
+	transactionService    *services.TransactionService
+	statementService      *services.StatementService
+	walletService         *services.WalletService
+	holdService           *services.HoldService
+	merchAdminService     *services.MerchAdminService
+	cartService           *services.CartService
+	orderService          *services.OrderService
+	promoService          *services.PromoService
+	notifyService         *services.NotificationService
+	wishlistService       *services.WishlistService
+	auctionService        *services.AuctionService
+	raffleService         *services.RaffleService
+	groupBuyService       *services.GroupBuyService
+	reviewService         *services.ReviewService
+	recommendationService *services.RecommendationService
+	blobStore             storage.BlobStore
+	logger                zap.Logger
 }
 
 func NewHandler(
 	userService *services.UserService,
 	merchService *services.MerchService,
 	transactionService *services.TransactionService,
+	statementService *services.StatementService,
+	walletService *services.WalletService,
+	holdService *services.HoldService,
+	merchAdminService *services.MerchAdminService,
+	cartService *services.CartService,
+	orderService *services.OrderService,
+	promoService *services.PromoService,
+	notifyService *services.NotificationService,
+	wishlistService *services.WishlistService,
+	auctionService *services.AuctionService,
+	raffleService *services.RaffleService,
+	groupBuyService *services.GroupBuyService,
+	reviewService *services.ReviewService,
+	recommendationService *services.RecommendationService,
+	blobStore storage.BlobStore,
 	writer zap.Logger,
 ) *Handler {
 	return &Handler{
-		userService:        userService,
-		merchService:       merchService,
-		transactionService: transactionService,
-		logger:             writer,
+		userService:           userService,
+		merchService:          merchService,
+		transactionService:    transactionService,
+		statementService:      statementService,
+		walletService:         walletService,
+		holdService:           holdService,
+		merchAdminService:     merchAdminService,
+		cartService:           cartService,
+		orderService:          orderService,
+		promoService:          promoService,
+		notifyService:         notifyService,
+		wishlistService:       wishlistService,
+		auctionService:        auctionService,
+		raffleService:         raffleService,
+		groupBuyService:       groupBuyService,
+		reviewService:         reviewService,
+		recommendationService: recommendationService,
+		blobStore:             blobStore,
+		logger:                writer,
 	}
 }
 
@@ -52,11 +99,96 @@ func (h *Handler) SetupRoutes(r *gin.Engine, jwtService *jwt.Service) {
 			secured.GET("/info", h.GetInfo)
 			secured.GET("/balance", h.Balance)
 			secured.POST("/sendCoin", h.SendCoin)
+			secured.GET("/sendCoin/quote", h.QuoteTransfer)
+			secured.GET("/merch", h.SearchMerch)
 			secured.GET("/merch/list", h.ListMerch)
+			secured.GET("/merch/:id", h.GetMerch)
+			secured.GET("/merch/:id/prices", h.GetMerchPriceHistory)
+			secured.GET("/merch/:id/reviews", h.GetMerchReviews)
+			secured.POST("/merch/:id/reviews", h.CreateMerchReview)
+			secured.PUT("/merch/:id/reviews/mine", h.UpdateMerchReview)
+			secured.DELETE("/merch/:id/reviews/mine", h.DeleteMerchReview)
+			secured.GET("/merch/:id/recommendations", h.GetMerchRecommendations)
+			secured.GET("/recommendations", h.GetRecommendations)
 			secured.GET("/buy/:item", h.BuyItem)
+			secured.POST("/gift", h.GiftItem)
+			secured.GET("/notifications", h.ListNotifications)
+			secured.POST("/notifications/:id/read", h.MarkNotificationRead)
+			secured.POST("/notifications/read", h.MarkAllNotificationsRead)
+			secured.GET("/wishlist", h.GetWishlist)
+			secured.PUT("/wishlist/items/:item", h.AddWishlistItem)
+			secured.DELETE("/wishlist/items/:item", h.RemoveWishlistItem)
+			secured.PUT("/wishlist/sharing", h.SetWishlistSharing)
+			secured.GET("/users/:username/wishlist", h.GetUserWishlist)
+			secured.GET("/auctions", h.ListAuctions)
+			secured.GET("/auctions/:id", h.GetAuction)
+			secured.POST("/auctions/:id/bids", h.PlaceBid)
+			secured.GET("/raffles", h.ListRaffles)
+			secured.GET("/raffles/:id", h.GetRaffle)
+			secured.GET("/raffles/:id/tickets", h.GetRaffleTickets)
+			secured.POST("/raffles/:id/tickets", h.BuyRaffleTickets)
+			secured.GET("/group-buys", h.ListGroupBuys)
+			secured.POST("/group-buys", h.CreateGroupBuy)
+			secured.GET("/group-buys/:id", h.GetGroupBuy)
+			secured.POST("/group-buys/:id/pledges", h.PledgeGroupBuy)
+			secured.GET("/statements/:period", h.GetStatement)
+			secured.GET("/holds", h.ListHolds)
+			secured.GET("/cart", h.GetCart)
+			secured.POST("/cart/items", h.AddCartItem)
+			secured.DELETE("/cart/items/:item", h.RemoveCartItem)
+			secured.POST("/cart/checkout", h.Checkout)
+			secured.GET("/orders", h.ListOrders)
+			secured.GET("/orders/:id", h.GetOrder)
+			secured.POST("/orders/:id/cancel", h.CancelOrder)
+
+			admin := secured.Group("/admin")
+			admin.Use(middleware.AdminMiddleware(h.userService))
+			{
+				admin.POST("/holds", h.PlaceHold)
+				admin.POST("/holds/:id/capture", h.CaptureHold)
+				admin.POST("/holds/:id/release", h.ReleaseHold)
+
+				admin.GET("/merch", h.AdminListMerch)
+				admin.POST("/merch", h.AdminCreateMerch)
+				admin.POST("/bundles", h.AdminCreateBundle)
+				admin.PUT("/merch/:id", h.AdminUpdateMerch)
+				admin.POST("/merch/:id/rename", h.AdminRenameMerch)
+				admin.DELETE("/merch/:id", h.AdminRetireMerch)
+				admin.GET("/merch/:id/audit", h.AdminMerchAudit)
+				admin.POST("/merch/:id/prices", h.AdminScheduleMerchPrice)
+				admin.POST("/merch/:id/images", h.AdminUploadMerchImage)
+				admin.DELETE("/merch/:id/images/:imageId", h.AdminDeleteMerchImage)
+				admin.GET("/merch/:id/variants", h.AdminListVariants)
+				admin.POST("/merch/:id/variants", h.AdminCreateVariant)
+				admin.PUT("/merch/:id/variants/:variantId", h.AdminUpdateVariant)
+				admin.GET("/merch/:id/rules", h.AdminGetPurchaseRule)
+				admin.PUT("/merch/:id/rules", h.AdminSetPurchaseRule)
+				admin.DELETE("/merch/:id/rules", h.AdminDeletePurchaseRule)
+				admin.PUT("/merch/:id/drop", h.AdminSetMerchDrop)
+				admin.DELETE("/merch/:id/drop", h.AdminClearMerchDrop)
+
+				admin.POST("/auctions", h.AdminCreateAuction)
+				admin.POST("/auctions/:id/cancel", h.AdminCancelAuction)
+				admin.POST("/raffles", h.AdminCreateRaffle)
+				admin.POST("/raffles/:id/cancel", h.AdminCancelRaffle)
+
+				admin.GET("/reviews", h.AdminListReviews)
+				admin.POST("/reviews/:id/moderate", h.AdminModerateReview)
+
+				admin.PUT("/users/:username/team", h.AdminSetUserTeam)
+
+				admin.GET("/orders", h.AdminListOrders)
+				admin.POST("/orders/:id/status", h.AdminUpdateOrderStatus)
+
+				admin.GET("/promo-codes", h.AdminListPromoCodes)
+				admin.POST("/promo-codes", h.AdminCreatePromoCode)
+				admin.POST("/promo-codes/:id/deactivate", h.AdminDeactivatePromoCode)
+			}
 		}
 	}
 
+	// картинки без авторизации: их грузят теги <img>, которые не передают токен
+	r.GET("/media/:key", h.GetMedia)
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	r.GET("/health", h.Health)
 	h.logger.Info("Routes setup complete.")
@@ -128,12 +260,18 @@ func (h *Handler) SendCoin(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "recipient user not found"})
 		return
 	}
+	if toUser.ID == fromUserID {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "cannot transfer to yourself"})
+		return
+	}
 
-	err = h.transactionService.TransferCoins(c, fromUserID, toUser.ID, req.Amount)
+	err = h.transactionService.TransferCurrency(c, fromUserID, toUser.ID, req.Currency, req.Amount)
 	if err != nil {
 		switch err {
 		case services.ErrInvalidAmount:
 			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid amount"})
+		case services.ErrUnknownCurrency:
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "unknown currency"})
 		case services.ErrLackOfFundsOnAccount:
 			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "insufficient funds"})
 		default:
@@ -145,45 +283,140 @@ func (h *Handler) SendCoin(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
-func (h *Handler) BuyItem(c *gin.Context) {
-	userID := middleware.GetUserID(c)
-	itemName := c.Param("item")
+func (h *Handler) QuoteTransfer(c *gin.Context) {
+	var req request.TransferQuoteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
 
-	err := h.merchService.PurchaseItem(c, userID, itemName)
+	quote, err := h.transactionService.QuoteTransfer(c, middleware.GetUserID(c), req.Amount)
 	if err != nil {
 		switch {
-		case errors.Is(err, services.ErrInsufficientCoins):
-			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "insufficient coins"})
+		case errors.Is(err, services.ErrInvalidAmount):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid amount"})
 		default:
-			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to purchase item"})
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to quote transfer"})
 		}
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, response.TransferQuoteResponseFromModel(quote))
+}
+
+func (h *Handler) BuyItem(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	itemName := c.Param("item")
+
+	var req request.BuyItemRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid quantity"})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	order, err := h.merchService.PurchaseItem(c, userID, itemName, req.Variant, req.Quantity, req.Promo)
+	if err != nil {
+		h.writeOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrderResponseFromModel(order))
+}
+
+// GiftItem покупает товар в подарок другому пользователю.
+func (h *Handler) GiftItem(c *gin.Context) {
+	var req request.GiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	order, err := h.merchService.GiftItem(c, middleware.GetUserID(c), req.ToUser, req.Item, req.Variant, req.Quantity,
+		req.Message, req.PromoCode)
+	if err != nil {
+		h.writeOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrderResponseFromModel(order))
 }
 
 func (h *Handler) ListMerch(c *gin.Context) {
 	merch, err := h.merchService.GetAllAvailableMerch(c)
+	if err != nil {
+		switch {
+		default:
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to purchase item"})
+		}
+		return
+	}
+	wishlist, err := h.wishlistService.WishlistMerchIDs(c, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get wishlist"})
+		return
+	}
 
 	resp := make([]*response.MerchResponse, len(merch))
 	for i, v := range merch {
 		resp[i] = response.MerchResponseFromModel(v)
+		resp[i].InWishlist = wishlist[v.ID]
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SearchMerch - постраничный поиск по каталогу, /merch/list остается для старых клиентов.
+func (h *Handler) SearchMerch(c *gin.Context) {
+	var req request.SearchMerchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid search parameters"})
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = services.DefaultMerchPageSize
 	}
+	if req.PageSize > services.MaxMerchPageSize {
+		req.PageSize = services.MaxMerchPageSize
+	}
+
+	page, err := h.merchService.SearchMerch(c, domain.MerchFilter{
+		Query:    req.Query,
+		Category: req.Category,
+		Tags:     req.Tags,
+		Currency: req.Currency,
+		MinPrice: req.MinPrice,
+		MaxPrice: req.MaxPrice,
+		Sort:     req.Sort,
+		Limit:    req.PageSize,
+		Offset:   (req.Page - 1) * req.PageSize,
+	})
 	if err != nil {
 		switch {
+		case errors.Is(err, services.ErrInvalidMerchSort),
+			errors.Is(err, services.ErrInvalidPriceRange):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
 		default:
-			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to purchase item"})
+			h.logger.Error("merch search failed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to search merch"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	c.JSON(http.StatusOK, response.MerchPageResponseFromModel(page, req.Page, req.PageSize))
 }
 
 func (h *Handler) Balance(c *gin.Context) {
 	userID := middleware.GetUserID(c)
-	balance, err := h.userService.GetUserBalance(c, userID)
+	wallets, err := h.walletService.GetWallets(c, userID)
 	if err != nil {
 		switch {
 		default:
@@ -192,5 +425,14 @@ func (h *Handler) Balance(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"balance": balance})
+	// balance оставлен для старых клиентов и всегда содержит основную валюту
+	resp := make([]*response.WalletResponse, len(wallets))
+	for i, w := range wallets {
+		resp[i] = response.WalletResponseFromModel(w)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"balance":   wallets[0].Balance,
+		"available": wallets[0].Available(),
+		"wallets":   resp,
+	})
 }
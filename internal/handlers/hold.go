@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (h *Handler) ListHolds(c *gin.Context) {
+	holds, err := h.holdService.GetActiveHolds(c, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get holds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, holdsResponse(holds))
+}
+
+func (h *Handler) PlaceHold(c *gin.Context) {
+	var req request.PlaceHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	user, err := h.userService.GetUserByUsername(c, req.Username)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "user not found"})
+		return
+	}
+
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	hold, err := h.holdService.PlaceHold(c, user.ID, req.Currency, req.Amount, req.Reason, ttl)
+	if err != nil {
+		h.writeHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.HoldResponseFromModel(hold))
+}
+
+func (h *Handler) CaptureHold(c *gin.Context) {
+	holdID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid hold id"})
+		return
+	}
+
+	var req request.CaptureHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	hold, err := h.holdService.Capture(c, holdID, req.Amount)
+	if err != nil {
+		h.writeHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.HoldResponseFromModel(hold))
+}
+
+func (h *Handler) ReleaseHold(c *gin.Context) {
+	holdID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid hold id"})
+		return
+	}
+
+	hold, err := h.holdService.Release(c, holdID)
+	if err != nil {
+		h.writeHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.HoldResponseFromModel(hold))
+}
+
+func (h *Handler) writeHoldError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrHoldNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "hold not found"})
+	case errors.Is(err, services.ErrInsufficientAvailable):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "insufficient available balance"})
+	case errors.Is(err, services.ErrHoldNotActive),
+		errors.Is(err, services.ErrInvalidCaptureAmount),
+		errors.Is(err, services.ErrInvalidAmount),
+		errors.Is(err, services.ErrUnknownCurrency),
+		errors.Is(err, services.ErrInvalidHoldExpiration):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to process hold"})
+	}
+}
+
+func holdsResponse(holds []*domain.Hold) []*response.HoldResponse {
+	resp := make([]*response.HoldResponse, len(holds))
+	for i, hold := range holds {
+		resp[i] = response.HoldResponseFromModel(hold)
+	}
+	return resp
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/storage"
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+const maxImageSize = 5 << 20
+
+func (h *Handler) GetMerch(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.merchService.GetItem(c, merchID)
+	if err != nil {
+		if errors.Is(err, storage.ErrMerchNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "merch not found"})
+			return
+		}
+		h.logger.Error("failed to get merch", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get merch"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.MerchDetailResponseFromModel(item))
+}
+
+func (h *Handler) GetMedia(c *gin.Context) {
+	key := c.Param("key")
+	blob, err := h.blobStore.Open(c, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to open media", zap.String("key", key), zap.Error(err))
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	defer blob.Close()
+
+	// ключи не переиспользуются, поэтому файл можно кешировать бессрочно
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.DataFromReader(http.StatusOK, -1, mime.TypeByExtension(filepath.Ext(key)), blob, nil)
+}
+
+// AdminUploadMerchImage принимает multipart-форму с файлом в поле image.
+// Тип определяется по содержимому файла, а не по заголовку клиента.
+func (h *Handler) AdminUploadMerchImage(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageSize+1<<20)
+	header, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "image file is required"})
+		return
+	}
+	if header.Size > maxImageSize {
+		c.JSON(http.StatusRequestEntityTooLarge, response.ErrorResponse{Errors: "image must not exceed 5 MB"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid image file"})
+		return
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid image file"})
+		return
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+
+	image, err := h.merchAdminService.AddImage(c, middleware.GetUserID(c), merchID, contentType,
+		io.MultiReader(bytes.NewReader(head), file))
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.MerchImageResponseFromModel(image))
+}
+
+func (h *Handler) AdminDeleteMerchImage(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+	imageID, err := strconv.ParseInt(c.Param("imageId"), 10, 64)
+	if err != nil || imageID <= 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid image id"})
+		return
+	}
+
+	if err := h.merchAdminService.DeleteImage(c, middleware.GetUserID(c), merchID, imageID); err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (h *Handler) AdminListMerch(c *gin.Context) {
+	items, err := h.merchAdminService.ListItems(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to list merch"})
+		return
+	}
+
+	resp := make([]*response.AdminMerchResponse, len(items))
+	for i, item := range items {
+		resp[i] = response.AdminMerchResponseFromModel(item)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) AdminCreateMerch(c *gin.Context) {
+	var req request.CreateMerchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	item, err := h.merchAdminService.CreateItem(c, middleware.GetUserID(c), req.Name, req.Price, req.Currency, req.Stock)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.AdminMerchResponseFromModel(item))
+}
+
+func (h *Handler) AdminCreateBundle(c *gin.Context) {
+	var req request.CreateBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	components := make([]services.BundleComponentInput, len(req.Items))
+	for i, item := range req.Items {
+		components[i] = services.BundleComponentInput{Item: item.Item, Quantity: item.Quantity}
+	}
+	item, err := h.merchAdminService.CreateBundle(c, middleware.GetUserID(c), req.Name, req.Price, req.Currency, components)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.AdminMerchResponseFromModel(item))
+}
+
+func (h *Handler) AdminUpdateMerch(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateMerchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	item, err := h.merchAdminService.UpdateItem(c, middleware.GetUserID(c), merchID, services.MerchUpdate{
+		Price:       req.Price,
+		Currency:    req.Currency,
+		Category:    req.Category,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Attributes:  req.Attributes,
+		Stock:       req.Stock,
+	})
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AdminMerchResponseFromModel(item))
+}
+
+func (h *Handler) AdminRenameMerch(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.RenameMerchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	item, err := h.merchAdminService.RenameItem(c, middleware.GetUserID(c), merchID, req.Name)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AdminMerchResponseFromModel(item))
+}
+
+func (h *Handler) AdminRetireMerch(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.merchAdminService.RetireItem(c, middleware.GetUserID(c), merchID)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AdminMerchResponseFromModel(item))
+}
+
+func (h *Handler) AdminMerchAudit(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.merchAdminService.GetAudit(c, merchID)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	resp := make([]*response.MerchAuditResponse, len(entries))
+	for i, entry := range entries {
+		resp[i] = response.MerchAuditResponseFromModel(entry)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) AdminScheduleMerchPrice(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.SchedulePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	price, err := h.merchAdminService.SchedulePriceChange(c, middleware.GetUserID(c), merchID, req.Price, req.EffectiveFrom)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.MerchPriceResponseFromModel(price, time.Now()))
+}
+
+func (h *Handler) GetMerchPriceHistory(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	prices, err := h.merchAdminService.GetPriceHistory(c, merchID)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	now := time.Now()
+	resp := make([]*response.MerchPriceResponse, len(prices))
+	for i, p := range prices {
+		resp[i] = response.MerchPriceResponseFromModel(p, now)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) AdminListVariants(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	variants, err := h.merchAdminService.ListVariants(c, merchID)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	resp := make([]*response.MerchVariantResponse, len(variants))
+	for i, v := range variants {
+		resp[i] = response.MerchVariantResponseFromModel(v)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) AdminCreateVariant(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.CreateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	variant, err := h.merchAdminService.CreateVariant(c, middleware.GetUserID(c), merchID, &domain.MerchVariant{
+		SKU:        req.SKU,
+		Size:       req.Size,
+		Color:      req.Color,
+		PriceDelta: req.PriceDelta,
+		Stock:      req.Stock,
+	})
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.MerchVariantResponseFromModel(variant))
+}
+
+func (h *Handler) AdminUpdateVariant(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+	variantID, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil || variantID <= 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid variant id"})
+		return
+	}
+
+	var req request.UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	variant, err := h.merchAdminService.UpdateVariant(c, middleware.GetUserID(c), merchID, variantID, services.VariantUpdate{
+		PriceDelta: req.PriceDelta,
+		Stock:      req.Stock,
+		Active:     req.Active,
+	})
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.MerchVariantResponseFromModel(variant))
+}
+
+func (h *Handler) AdminGetPurchaseRule(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.merchAdminService.GetPurchaseRule(c, merchID)
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.PurchaseRuleResponseFromModel(rule))
+}
+
+func (h *Handler) AdminSetPurchaseRule(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.PurchaseRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	rule, err := h.merchAdminService.SetPurchaseRule(c, middleware.GetUserID(c), merchID, &domain.MerchPurchaseRule{
+		MaxQuantity:       req.MaxQuantity,
+		PeriodDays:        req.PeriodDays,
+		MinAccountAgeDays: req.MinAccountAgeDays,
+		AllowedRoles:      req.AllowedRoles,
+		AllowedTeams:      req.AllowedTeams,
+	})
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.PurchaseRuleResponseFromModel(rule))
+}
+
+func (h *Handler) AdminDeletePurchaseRule(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.merchAdminService.DeletePurchaseRule(c, middleware.GetUserID(c), merchID); err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) AdminSetMerchDrop(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.MerchDropRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	item, err := h.merchAdminService.SetDrop(c, middleware.GetUserID(c), merchID, &domain.MerchDrop{
+		AvailableFrom:  req.AvailableFrom,
+		AvailableUntil: req.AvailableUntil,
+		Cap:            req.Cap,
+	})
+	if err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AdminMerchResponseFromModel(item))
+}
+
+func (h *Handler) AdminClearMerchDrop(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.merchAdminService.ClearDrop(c, middleware.GetUserID(c), merchID); err != nil {
+		h.writeMerchAdminError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) writeMerchAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrMerchNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "merch not found"})
+	case errors.Is(err, storage.ErrMerchImageNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "image not found"})
+	case errors.Is(err, storage.ErrMerchRuleNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "purchase rule not found"})
+	case errors.Is(err, storage.ErrVariantNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "variant not found"})
+	case errors.Is(err, storage.ErrVariantTaken):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: "variant sku or size/color is already taken"})
+	case errors.Is(err, storage.ErrMerchNameTaken):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: "merch name is already taken"})
+	case errors.Is(err, services.ErrInvalidMerchName),
+		errors.Is(err, services.ErrInvalidMerchPrice),
+		errors.Is(err, services.ErrInvalidMerchStock),
+		errors.Is(err, services.ErrInvalidMerchCategory),
+		errors.Is(err, services.ErrInvalidMerchTags),
+		errors.Is(err, services.ErrInvalidDescription),
+		errors.Is(err, services.ErrInvalidAttributes),
+		errors.Is(err, services.ErrUnsupportedImage),
+		errors.Is(err, services.ErrInvalidVariant),
+		errors.Is(err, services.ErrInvalidVariantPrice),
+		errors.Is(err, services.ErrInvalidPurchaseRule),
+		errors.Is(err, services.ErrInvalidDrop),
+		errors.Is(err, services.ErrInvalidBundle),
+		errors.Is(err, services.ErrBundleUnsupported),
+		errors.Is(err, services.ErrInvalidEffectiveDate),
+		errors.Is(err, services.ErrUnknownCurrency),
+		errors.Is(err, services.ErrMerchRetired):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	default:
+		h.logger.Error("merch administration failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to update merch"})
+	}
+}
+
+func merchIDParam(c *gin.Context) (int, bool) {
+	merchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || merchID <= 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid merch id"})
+		return 0, false
+	}
+	return merchID, true
+}
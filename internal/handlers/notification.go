@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// ListNotifications возвращает уведомления пользователя, ?unread=true - только непрочитанные.
+func (h *Handler) ListNotifications(c *gin.Context) {
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	notifications, err := h.notifyService.List(c, middleware.GetUserID(c), unreadOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get notifications"})
+		return
+	}
+
+	resp := make([]*response.NotificationResponse, len(notifications))
+	for i, n := range notifications {
+		resp[i] = response.NotificationResponseFromModel(n)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) MarkNotificationRead(c *gin.Context) {
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid notification id"})
+		return
+	}
+
+	if err := h.notifyService.MarkRead(c, middleware.GetUserID(c), notificationID); err != nil {
+		if errors.Is(err, storage.ErrNotificationNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to update notification"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) MarkAllNotificationsRead(c *gin.Context) {
+	if _, err := h.notifyService.MarkAllRead(c, middleware.GetUserID(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to update notifications"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) ListOrders(c *gin.Context) {
+	orders, err := h.orderService.GetUserOrders(c, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrdersResponseFromModel(orders))
+}
+
+func (h *Handler) GetOrder(c *gin.Context) {
+	orderID, ok := orderIDParam(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.GetUserOrder(c, middleware.GetUserID(c), orderID)
+	if err != nil {
+		h.writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrderResponseFromModel(order))
+}
+
+func (h *Handler) CancelOrder(c *gin.Context) {
+	orderID, ok := orderIDParam(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.CancelOrder(c, middleware.GetUserID(c), orderID)
+	if err != nil {
+		h.writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrderResponseFromModel(order))
+}
+
+func (h *Handler) AdminListOrders(c *gin.Context) {
+	var req request.ListOrdersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	orders, err := h.orderService.ListOrders(c, req.Status)
+	if err != nil {
+		h.writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrdersResponseFromModel(orders))
+}
+
+func (h *Handler) AdminUpdateOrderStatus(c *gin.Context) {
+	orderID, ok := orderIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	order, err := h.orderService.TransitionOrder(c, middleware.GetUserID(c), orderID, req.Status)
+	if err != nil {
+		h.writeOrderStatusError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrderResponseFromModel(order))
+}
+
+func (h *Handler) writeOrderStatusError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "order not found"})
+	case errors.Is(err, services.ErrUnknownOrderStatus):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrInvalidOrderTransition),
+		errors.Is(err, services.ErrOrderNotCancellable),
+		errors.Is(err, services.ErrGroupOrderNotCancellable),
+		errors.Is(err, services.ErrPrizeOrderNotCancellable),
+		errors.Is(err, services.ErrCancellationWindowExpired):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: err.Error()})
+	default:
+		h.logger.Error("order status update failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to process order"})
+	}
+}
+
+func orderIDParam(c *gin.Context) (int64, bool) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || orderID <= 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid order id"})
+		return 0, false
+	}
+	return orderID, true
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) AdminListPromoCodes(c *gin.Context) {
+	promos, err := h.promoService.ListPromoCodes(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to list promo codes"})
+		return
+	}
+
+	resp := make([]*response.PromoCodeResponse, len(promos))
+	for i, p := range promos {
+		resp[i] = response.PromoCodeResponseFromModel(p)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) AdminCreatePromoCode(c *gin.Context) {
+	var req request.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	promo, err := h.promoService.CreatePromoCode(c, middleware.GetUserID(c), &domain.PromoCode{
+		Code:           req.Code,
+		Kind:           req.Kind,
+		Value:          req.Value,
+		MerchIDs:       req.MerchIDs,
+		Categories:     req.Categories,
+		Currency:       req.Currency,
+		MinSpend:       req.MinSpend,
+		ValidFrom:      req.ValidFrom,
+		ValidUntil:     req.ValidUntil,
+		MaxUses:        req.MaxUses,
+		MaxUsesPerUser: req.MaxUsesPerUser,
+	})
+	if err != nil {
+		h.writePromoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.PromoCodeResponseFromModel(promo))
+}
+
+func (h *Handler) AdminDeactivatePromoCode(c *gin.Context) {
+	promoID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || promoID <= 0 {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid promo code id"})
+		return
+	}
+
+	if err := h.promoService.DeactivatePromoCode(c, promoID); err != nil {
+		h.writePromoError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) writePromoError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrPromoCodeNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, storage.ErrPromoCodeTaken):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrInvalidPromoRule),
+		errors.Is(err, services.ErrUnknownCurrency):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	default:
+		h.logger.Error("promo code administration failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to update promo code"})
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) ListRaffles(c *gin.Context) {
+	raffles, err := h.raffleService.ListRaffles(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get raffles"})
+		return
+	}
+
+	resp := make([]*response.RaffleResponse, len(raffles))
+	for i, r := range raffles {
+		resp[i] = response.RaffleResponseFromModel(r, nil)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) GetRaffle(c *gin.Context) {
+	raffleID, ok := raffleIDParam(c)
+	if !ok {
+		return
+	}
+
+	raffle, winners, err := h.raffleService.GetRaffle(c, raffleID)
+	if err != nil {
+		h.writeRaffleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.RaffleResponseFromModel(raffle, winners))
+}
+
+func (h *Handler) GetRaffleTickets(c *gin.Context) {
+	raffleID, ok := raffleIDParam(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.raffleService.GetTickets(c, raffleID)
+	if err != nil {
+		h.writeRaffleError(c, err)
+		return
+	}
+
+	resp := make([]*response.RaffleEntryResponse, len(entries))
+	for i, e := range entries {
+		resp[i] = response.RaffleEntryResponseFromModel(e)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) BuyRaffleTickets(c *gin.Context) {
+	raffleID, ok := raffleIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.BuyTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	entry, err := h.raffleService.BuyTickets(c, middleware.GetUserID(c), raffleID, req.Quantity)
+	if err != nil {
+		h.writeRaffleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.RaffleEntryResponseFromModel(entry))
+}
+
+func (h *Handler) AdminCreateRaffle(c *gin.Context) {
+	var req request.CreateRaffleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	raffle, err := h.raffleService.CreateRaffle(c, middleware.GetUserID(c), req.Item, &domain.Raffle{
+		TicketPrice:       req.TicketPrice,
+		MaxTicketsPerUser: req.MaxTicketsPerUser,
+		Prizes:            req.Prizes,
+		DrawAt:            req.DrawAt,
+	})
+	if err != nil {
+		h.writeRaffleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.RaffleResponseFromModel(raffle, nil))
+}
+
+func (h *Handler) AdminCancelRaffle(c *gin.Context) {
+	raffleID, ok := raffleIDParam(c)
+	if !ok {
+		return
+	}
+
+	raffle, err := h.raffleService.CancelRaffle(c, raffleID)
+	if err != nil {
+		h.writeRaffleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.RaffleResponseFromModel(raffle, nil))
+}
+
+func raffleIDParam(c *gin.Context) (int64, bool) {
+	raffleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid raffle id"})
+		return 0, false
+	}
+	return raffleID, true
+}
+
+func (h *Handler) writeRaffleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrRaffleNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "raffle not found"})
+	case errors.Is(err, storage.ErrMerchNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "merch not found"})
+	case errors.Is(err, services.ErrRaffleClosed),
+		errors.Is(err, services.ErrRaffleTicketLimit),
+		errors.Is(err, services.ErrOutOfStock):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrInsufficientCoins):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "insufficient coins"})
+	case errors.Is(err, services.ErrPurchaseRestricted),
+		errors.Is(err, services.ErrAccountTooNew),
+		errors.Is(err, services.ErrPurchaseLimitExceeded):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrInvalidRaffle),
+		errors.Is(err, services.ErrBundleUnsupported),
+		errors.Is(err, services.ErrVariantsUnsupported),
+		errors.Is(err, services.ErrInvalidQuantity):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	default:
+		h.logger.Error("raffle failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to process raffle"})
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (h *Handler) GetRecommendations(c *gin.Context) {
+	recs, err := h.recommendationService.ForUser(c, middleware.GetUserID(c))
+	if err != nil {
+		h.writeRecommendationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.UserRecommendationsResponseFromModel(recs))
+}
+
+func (h *Handler) GetMerchRecommendations(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	recs, err := h.recommendationService.ForItem(c, middleware.GetUserID(c), merchID)
+	if err != nil {
+		h.writeRecommendationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.RecommendationsFromModel(recs))
+}
+
+func (h *Handler) writeRecommendationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrMerchNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "merch not found"})
+	case errors.Is(err, storage.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "user not found"})
+	default:
+		h.logger.Error("recommendations failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get recommendations"})
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetMerchReviews(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	item, reviews, err := h.reviewService.GetReviews(c, merchID)
+	if err != nil {
+		h.writeReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.MerchReviewsResponseFromModel(item, reviews))
+}
+
+func (h *Handler) CreateMerchReview(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	review, err := h.reviewService.CreateReview(c, middleware.GetUserID(c), merchID, req.Rating, req.Body)
+	if err != nil {
+		h.writeReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.ReviewResponseFromModel(review))
+}
+
+func (h *Handler) UpdateMerchReview(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req request.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	review, err := h.reviewService.UpdateReview(c, middleware.GetUserID(c), merchID, req.Rating, req.Body)
+	if err != nil {
+		h.writeReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.ReviewResponseFromModel(review))
+}
+
+func (h *Handler) DeleteMerchReview(c *gin.Context) {
+	merchID, ok := merchIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.reviewService.DeleteReview(c, middleware.GetUserID(c), merchID); err != nil {
+		h.writeReviewError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) AdminListReviews(c *gin.Context) {
+	reviews, err := h.reviewService.ListForModeration(c, c.Query("status"))
+	if err != nil {
+		h.writeReviewError(c, err)
+		return
+	}
+
+	resp := make([]*response.ReviewResponse, len(reviews))
+	for i, r := range reviews {
+		resp[i] = response.ReviewResponseFromModel(r)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) AdminModerateReview(c *gin.Context) {
+	reviewID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid review id"})
+		return
+	}
+
+	var req request.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	review, err := h.reviewService.ModerateReview(c, middleware.GetUserID(c), reviewID, req.Status)
+	if err != nil {
+		h.writeReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.ReviewResponseFromModel(review))
+}
+
+func (h *Handler) writeReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrMerchNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "merch not found"})
+	case errors.Is(err, storage.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "review not found"})
+	case errors.Is(err, storage.ErrReviewExists):
+		c.JSON(http.StatusConflict, response.ErrorResponse{Errors: "you have already reviewed this item"})
+	case errors.Is(err, services.ErrNotVerifiedBuyer):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrInvalidReview),
+		errors.Is(err, services.ErrUnknownReviewStatus):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+	default:
+		h.logger.Error("review failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to process review"})
+	}
+}
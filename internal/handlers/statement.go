@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"bytes"
+	"embed"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+//go:embed templates/statement.html
+var templatesFS embed.FS
+
+var statementTemplate = template.Must(template.ParseFS(templatesFS, "templates/statement.html"))
+
+func (h *Handler) GetStatement(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	period, err := time.Parse(response.StatementPeriodLayout, c.Param("period"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "period must be in YYYY-MM format"})
+		return
+	}
+
+	statement, err := h.statementService.GetStatement(c.Request.Context(), userID, period)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrStatementPeriodNotClosed):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "statement period is not closed yet"})
+		default:
+			h.logger.Error("failed to get statement", zap.Int64("user_id", userID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get statement"})
+		}
+		return
+	}
+
+	resp := response.StatementResponseFromModel(statement)
+	if c.Query("format") != "html" {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := statementTemplate.Execute(&buf, resp); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to render statement"})
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
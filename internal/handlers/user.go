@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (h *Handler) AdminSetUserTeam(c *gin.Context) {
+	var req request.SetTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request format"})
+		return
+	}
+
+	user, err := h.userService.GetUserByUsername(c, c.Param("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "user not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get user"})
+		return
+	}
+
+	user, err = h.userService.SetTeam(c, user.ID, req.Team)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTeam):
+			c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: err.Error()})
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: "user not found"})
+		default:
+			h.logger.Error("failed to set team", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to set team"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response.UserTeamResponse{Username: user.Username, Team: user.Team})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"avito-backend-intern-winter25/internal/middleware"
+	"avito-backend-intern-winter25/internal/models/http/request"
+	"avito-backend-intern-winter25/internal/models/http/response"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/storage"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func (h *Handler) GetWishlist(c *gin.Context) {
+	items, shared, err := h.wishlistService.GetWishlist(c, middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to get wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WishlistResponseFromModel("", shared, items))
+}
+
+// GetUserWishlist - вишлист коллеги из той же команды, если он открыт.
+func (h *Handler) GetUserWishlist(c *gin.Context) {
+	owner := c.Param("username")
+
+	items, err := h.wishlistService.GetSharedWishlist(c, middleware.GetUserID(c), owner)
+	if err != nil {
+		h.writeWishlistError(c, err, "failed to get wishlist")
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WishlistResponseFromModel(owner, true, items))
+}
+
+func (h *Handler) AddWishlistItem(c *gin.Context) {
+	if err := h.wishlistService.AddItem(c, middleware.GetUserID(c), c.Param("item")); err != nil {
+		h.writeWishlistError(c, err, "failed to add item to wishlist")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) RemoveWishlistItem(c *gin.Context) {
+	if err := h.wishlistService.RemoveItem(c, middleware.GetUserID(c), c.Param("item")); err != nil {
+		h.writeWishlistError(c, err, "failed to remove item from wishlist")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) SetWishlistSharing(c *gin.Context) {
+	var req request.WishlistSharingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{Errors: "invalid request body"})
+		return
+	}
+
+	if err := h.wishlistService.SetShared(c, middleware.GetUserID(c), *req.Shared); err != nil {
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: "failed to update wishlist sharing"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) writeWishlistError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrWishlistNotShared):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{Errors: err.Error()})
+	case errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, storage.ErrMerchNotFound),
+		errors.Is(err, storage.ErrWishlistItemNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{Errors: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{Errors: fallback})
+	}
+}
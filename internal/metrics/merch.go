@@ -0,0 +1,24 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var merchStockRemaining = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "merch_stock_remaining",
+		Help: "Remaining stock of limited merch items",
+	},
+	[]string{"item"},
+)
+
+func init() {
+	prometheus.MustRegister(merchStockRemaining)
+}
+
+func SetMerchStock(item string, stock int) {
+	merchStockRemaining.WithLabelValues(item).Set(float64(stock))
+}
+
+// ResetMerchStock убирает серии снятых с продажи и переименованных товаров перед полным обновлением.
+func ResetMerchStock() {
+	merchStockRemaining.Reset()
+}
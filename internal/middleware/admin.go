@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
+
+// AdminMiddleware должен стоять после AuthMiddleware.
+func AdminMiddleware(checker AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		isAdmin, err := checker.IsAdmin(c.Request.Context(), GetUserID(c))
+		if err != nil {
+			c.AbortWithStatusJSON(500, gin.H{"errors": "failed to check permissions"})
+			return
+		}
+		if !isAdmin {
+			c.AbortWithStatusJSON(403, gin.H{"errors": "admin access required"})
+			return
+		}
+		c.Next()
+	}
+}
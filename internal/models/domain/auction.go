@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+const (
+	AuctionStatusOpen      = "open"
+	AuctionStatusSettled   = "settled"
+	AuctionStatusUnsold    = "unsold"
+	AuctionStatusCancelled = "cancelled"
+)
+
+type Auction struct {
+	ID            int64
+	MerchID       int
+	Item          string
+	Currency      string
+	StartingPrice int
+	MinIncrement  int
+	// AntiSnipe - ставка ближе к концу, чем это окно, продлевает аукцион до now + AntiSnipe
+	AntiSnipe time.Duration
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Status    string
+	TopBid    *AuctionBid
+	OrderID   int64
+	CreatedBy int64
+	CreatedAt time.Time
+	SettledAt *time.Time
+}
+
+type AuctionBid struct {
+	ID        int64
+	AuctionID int64
+	UserID    int64
+	Username  string
+	Amount    int
+	HoldID    int64
+	CreatedAt time.Time
+}
+
+// MinBid - минимальная сумма следующей ставки.
+func (a *Auction) MinBid() int {
+	if a.TopBid == nil {
+		return a.StartingPrice
+	}
+	return a.TopBid.Amount + a.MinIncrement
+}
+
+func (a *Auction) AcceptsBidsAt(t time.Time) bool {
+	return a.Status == AuctionStatusOpen && !t.Before(a.StartsAt) && t.Before(a.EndsAt)
+}
+
+// ExtendForBidAt продлевает аукцион, если ставка сделана в последние AntiSnipe до конца.
+func (a *Auction) ExtendForBidAt(t time.Time) bool {
+	if a.AntiSnipe <= 0 || a.EndsAt.Sub(t) >= a.AntiSnipe {
+		return false
+	}
+	a.EndsAt = t.Add(a.AntiSnipe)
+	return true
+}
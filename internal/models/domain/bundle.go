@@ -0,0 +1,8 @@
+package domain
+
+// BundleComponent - товар в составе набора и его количество в одном наборе.
+type BundleComponent struct {
+	BundleID int
+	Item     *Merch
+	Quantity int
+}
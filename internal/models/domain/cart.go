@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+type CartItem struct {
+	UserID   int64
+	Item     *Merch
+	Variant  *MerchVariant
+	Quantity int
+	AddedAt  time.Time
+}
+
+func (c *CartItem) Total() int {
+	return UnitPrice(c.Item, c.Variant) * c.Quantity
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "math"
+
+type FeePolicy struct {
+	Flat                  int
+	Percent               float64
+	Min                   int
+	Max                   int
+	FreeTransfersPerMonth int
+	AccountID             int64
+}
+
+type TransferQuote struct {
+	Amount int
+	Fee    int
+	Total  int
+}
+
+func (p FeePolicy) Enabled() bool {
+	return p.AccountID != 0 && (p.Flat > 0 || p.Percent > 0 || p.Min > 0)
+}
+
+// Calculate считает комиссию за перевод с учетом уже сделанных в этом месяце переводов.
+func (p FeePolicy) Calculate(amount, transfersThisMonth int) int {
+	if !p.Enabled() || transfersThisMonth < p.FreeTransfersPerMonth {
+		return 0
+	}
+
+	fee := p.Flat + int(math.Ceil(float64(amount)*p.Percent/100))
+	if fee < p.Min {
+		fee = p.Min
+	}
+	if p.Max > 0 && fee > p.Max {
+		fee = p.Max
+	}
+	return fee
+}
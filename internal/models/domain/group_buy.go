@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+const (
+	GroupBuyStatusOpen      = "open"
+	GroupBuyStatusCompleted = "completed"
+	GroupBuyStatusExpired   = "expired"
+)
+
+type GroupBuy struct {
+	ID       int64
+	MerchID  int
+	Item     string
+	Quantity int
+	Currency string
+	// Target - цена Quantity штук на момент создания
+	Target int
+	// Pledged - сумма взносов с действующими или списанными холдами
+	Pledged   int
+	CreatorID int64
+	Creator   string
+	Deadline  time.Time
+	Status    string
+	OrderID   int64
+	CreatedAt time.Time
+	ClosedAt  *time.Time
+}
+
+type GroupBuyPledge struct {
+	ID         int64
+	GroupBuyID int64
+	UserID     int64
+	Username   string
+	Amount     int
+	HoldID     int64
+	CreatedAt  time.Time
+}
+
+func (g *GroupBuy) Remaining() int {
+	return max(g.Target-g.Pledged, 0)
+}
+
+func (g *GroupBuy) AcceptsPledgesAt(t time.Time) bool {
+	return g.Status == GroupBuyStatusOpen && t.Before(g.Deadline)
+}
+
+// GroupBuyPolicy ограничивает сборы, которые может открыть сотрудник: штук в одном сборе, одновременно открытых
+// сборов одного создателя и срок до дедлайна.
+type GroupBuyPolicy struct {
+	MaxQuantity       int
+	MaxOpenPerCreator int
+	MaxDuration       time.Duration
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+type Hold struct {
+	ID             int64
+	UserID         int64
+	Currency       string
+	Amount         int
+	CapturedAmount int
+	Status         string
+	Reason         string
+	ExpiresAt      time.Time
+	CapturedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+func (h *Hold) IsActive(now time.Time) bool {
+	return h.Status == HoldStatusActive && now.Before(h.ExpiresAt)
+}
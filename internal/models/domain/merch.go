@@ -1,7 +1,154 @@
 package domain
 
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	MerchAuditCreate = "create"
+	MerchAuditUpdate = "update"
+	MerchAuditRename = "rename"
+	MerchAuditRetire = "retire"
+
+	MerchAuditSchedulePrice = "schedule_price"
+)
+
 type Merch struct {
-	ID    int
-	Name  string
-	Price int
+	ID          int
+	Name        string
+	Price       int
+	Currency    string
+	Category    string
+	Description string
+	Tags        []string
+	Attributes  map[string]string
+	Images      []*MerchImage
+	Variants    []*MerchVariant
+	// Stock набора считается по составляющим, Components заполняется только в карточке товара
+	Stock      *int
+	Bundle     bool
+	Components []*BundleComponent
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	RetiredAt  *time.Time
+	// окно продаж и тираж дропа, nil - без ограничений; DropLeft - сколько осталось от тиража
+	AvailableFrom  *time.Time
+	AvailableUntil *time.Time
+	DropCap        *int
+	DropLeft       *int
+	// NextPriceAt - когда вступит в силу следующая запланированная цена, nil - изменений не запланировано
+	NextPriceAt *time.Time
+	// ReviewCount и RatingSum - по видимым отзывам
+	ReviewCount int
+	RatingSum   int
+}
+
+// MerchStock - текущие остатки товара, nil - без ограничения. У набора Stock - сколько наборов собирается
+// из составляющих.
+type MerchStock struct {
+	Stock    *int
+	DropLeft *int
+}
+
+// Rating - средняя оценка, 0 у товара без отзывов.
+func (m *Merch) Rating() float64 {
+	if m.ReviewCount == 0 {
+		return 0
+	}
+	return float64(m.RatingSum) / float64(m.ReviewCount)
+}
+
+// Limited сообщает, ведется ли учет остатков: Stock == nil - количество не ограничено.
+func (m *Merch) Limited() bool {
+	return m.Stock != nil
+}
+
+// OnSaleAt сообщает, попадает ли момент в окно продаж товара.
+func (m *Merch) OnSaleAt(t time.Time) bool {
+	if m.AvailableFrom != nil && t.Before(*m.AvailableFrom) {
+		return false
+	}
+	return m.AvailableUntil == nil || t.Before(*m.AvailableUntil)
+}
+
+func (m *Merch) Drop() *MerchDrop {
+	return &MerchDrop{MerchID: m.ID, AvailableFrom: m.AvailableFrom, AvailableUntil: m.AvailableUntil, Cap: m.DropCap}
+}
+
+// MerchDrop - окно продаж и тираж товара. Тираж считается по всем покупкам с момента, когда он задан.
+type MerchDrop struct {
+	MerchID        int
+	AvailableFrom  *time.Time
+	AvailableUntil *time.Time
+	Cap            *int
+}
+
+func (d *MerchDrop) IsEmpty() bool {
+	return d.AvailableFrom == nil && d.AvailableUntil == nil && d.Cap == nil
+}
+
+// String - описание дропа для журнала изменений товара.
+func (d *MerchDrop) String() string {
+	if d.IsEmpty() {
+		return ""
+	}
+	format := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	limit := "-"
+	if d.Cap != nil {
+		limit = strconv.Itoa(*d.Cap)
+	}
+	return fmt.Sprintf("from=%s until=%s cap=%s", format(d.AvailableFrom), format(d.AvailableUntil), limit)
+}
+
+const (
+	MerchSortRelevance = "relevance"
+	MerchSortPriceAsc  = "price_asc"
+	MerchSortPriceDesc = "price_desc"
+	MerchSortPopular   = "popular"
+	MerchSortNewest    = "newest"
+)
+
+func IsMerchSort(sort string) bool {
+	switch sort {
+	case MerchSortRelevance, MerchSortPriceAsc, MerchSortPriceDesc, MerchSortPopular, MerchSortNewest:
+		return true
+	}
+	return false
+}
+
+// MerchFilter - параметры поиска по каталогу, пустые поля не ограничивают выборку.
+type MerchFilter struct {
+	Query    string
+	Category string
+	Tags     []string
+	Currency string
+	MinPrice *int
+	MaxPrice *int
+	Sort     string
+	Limit    int
+	Offset   int
+}
+
+type MerchPage struct {
+	Items []*Merch
+	Total int
+}
+
+type MerchAudit struct {
+	ID        int64
+	MerchID   int
+	AdminID   int64
+	Action    string
+	Field     string
+	OldValue  string
+	NewValue  string
+	CreatedAt time.Time
 }
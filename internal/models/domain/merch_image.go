@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+type MerchImage struct {
+	ID          int64
+	MerchID     int
+	Key         string
+	ContentType string
+	Size        int64
+	Position    int
+	URL         string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+type MerchPrice struct {
+	ID            int64
+	MerchID       int
+	Price         int
+	EffectiveFrom time.Time
+	CreatedBy     int64
+	CreatedAt     time.Time
+}
+
+func (p *MerchPrice) IsScheduled(now time.Time) bool {
+	return p.EffectiveFrom.After(now)
+}
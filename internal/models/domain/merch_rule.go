@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MerchPurchaseRule - ограничения на покупку товара. Нулевые значения и пустые списки ничего не ограничивают.
+type MerchPurchaseRule struct {
+	MerchID int
+	// MaxQuantity - сколько штук один пользователь может купить за PeriodDays (0 - за все время)
+	MaxQuantity       int
+	PeriodDays        int
+	MinAccountAgeDays int
+	AllowedRoles      []string
+	AllowedTeams      []string
+	UpdatedBy         int64
+	UpdatedAt         time.Time
+}
+
+func (r *MerchPurchaseRule) IsEmpty() bool {
+	return r.MaxQuantity == 0 && r.MinAccountAgeDays == 0 && len(r.AllowedRoles) == 0 && len(r.AllowedTeams) == 0
+}
+
+// String - описание правила для журнала изменений товара.
+func (r *MerchPurchaseRule) String() string {
+	return fmt.Sprintf("max=%d period=%dd min_age=%dd roles=%s teams=%s", r.MaxQuantity, r.PeriodDays,
+		r.MinAccountAgeDays, strings.Join(r.AllowedRoles, ","), strings.Join(r.AllowedTeams, ","))
+}
+
+// LimitSince - начало окна, за которое считаются покупки; нулевое время - за все время.
+func (r *MerchPurchaseRule) LimitSince(now time.Time) time.Time {
+	if r.PeriodDays == 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -r.PeriodDays)
+}
+
+// EligibleAt - дата, с которой аккаунт достаточно старый для покупки.
+func (r *MerchPurchaseRule) EligibleAt(user *User) time.Time {
+	return user.CreatedAt.AddDate(0, 0, r.MinAccountAgeDays)
+}
+
+func (r *MerchPurchaseRule) AllowsRole(role string) bool {
+	return len(r.AllowedRoles) == 0 || containsString(r.AllowedRoles, role)
+}
+
+func (r *MerchPurchaseRule) AllowsTeam(team string) bool {
+	return len(r.AllowedTeams) == 0 || team != "" && containsString(r.AllowedTeams, team)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// MerchVariant - SKU товара для конкретного размера и цвета.
+type MerchVariant struct {
+	ID         int
+	MerchID    int
+	SKU        string
+	Size       string
+	Color      string
+	PriceDelta int
+	Stock      *int
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (v *MerchVariant) Limited() bool {
+	return v.Stock != nil
+}
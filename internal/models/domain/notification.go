@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+const (
+	NotificationGiftReceived      = "gift_received"
+	NotificationPriceDrop         = "price_drop"
+	NotificationBackInStock       = "back_in_stock"
+	NotificationAuctionOutbid     = "auction_outbid"
+	NotificationAuctionWon        = "auction_won"
+	NotificationRaffleWon         = "raffle_won"
+	NotificationGroupBuyCompleted = "group_buy_completed"
+	NotificationGroupBuyExpired   = "group_buy_expired"
+)
+
+type Notification struct {
+	ID      int64
+	UserID  int64
+	Kind    string
+	Message string
+	// Data - параметры для клиента (id товара, отправитель и т.п.)
+	Data      map[string]string
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
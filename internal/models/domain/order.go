@@ -0,0 +1,99 @@
+package domain
+
+import "time"
+
+const (
+	OrderStatusPlaced         = "placed"
+	OrderStatusApproved       = "approved"
+	OrderStatusPacked         = "packed"
+	OrderStatusShipped        = "shipped"
+	OrderStatusReadyForPickup = "ready_for_pickup"
+	OrderStatusDelivered      = "delivered"
+	OrderStatusCancelled      = "cancelled"
+)
+
+// orderTransitions - допустимые переходы статусов заказа. Отменить можно только до отправки.
+var orderTransitions = map[string][]string{
+	OrderStatusPlaced:         {OrderStatusApproved, OrderStatusCancelled},
+	OrderStatusApproved:       {OrderStatusPacked, OrderStatusCancelled},
+	OrderStatusPacked:         {OrderStatusShipped, OrderStatusReadyForPickup, OrderStatusCancelled},
+	OrderStatusShipped:        {OrderStatusDelivered},
+	OrderStatusReadyForPickup: {OrderStatusDelivered},
+}
+
+type Order struct {
+	ID        int64
+	UserID    int64
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Purchases []*Purchase
+	History   []*OrderStatusChange
+	// StockLeft - остатки товаров после оформления заказа для метрик, заполняется только при оформлении
+	StockLeft map[string]int
+}
+
+type OrderStatusChange struct {
+	ID         int64
+	OrderID    int64
+	FromStatus string
+	ToStatus   string
+	ChangedBy  int64
+	CreatedAt  time.Time
+}
+
+// Gift - получатель подарка. Заказ оформляет и оплачивает покупатель, товар попадает к получателю.
+type Gift struct {
+	RecipientID   int64
+	RecipientName string
+	Message       string
+}
+
+// OrderLine - позиция заказа до оформления, Variant == nil для товаров без вариантов.
+type OrderLine struct {
+	Item     *Merch
+	Variant  *MerchVariant
+	Quantity int
+}
+
+func (l OrderLine) UnitPrice() int {
+	return UnitPrice(l.Item, l.Variant)
+}
+
+func (l OrderLine) Total() int {
+	return l.UnitPrice() * l.Quantity
+}
+
+// UnitPrice - цена товара с учетом надбавки варианта.
+func UnitPrice(item *Merch, variant *MerchVariant) int {
+	if variant == nil {
+		return item.Price
+	}
+	return item.Price + variant.PriceDelta
+}
+
+func IsOrderStatus(status string) bool {
+	if status == OrderStatusDelivered || status == OrderStatusCancelled {
+		return true
+	}
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+func (o *Order) CanTransitionTo(status string) bool {
+	for _, next := range orderTransitions[o.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Totals возвращает сумму заказа по валютам.
+func (o *Order) Totals() map[string]int {
+	totals := make(map[string]int)
+	for _, p := range o.Purchases {
+		totals[p.Currency] += p.Price
+	}
+	return totals
+}
@@ -0,0 +1,125 @@
+package domain
+
+import "time"
+
+const (
+	PromoKindPercent = "percent"
+	PromoKindFixed   = "fixed"
+)
+
+type PromoCode struct {
+	ID             int64
+	Code           string
+	Kind           string
+	Value          int
+	MerchIDs       []int
+	Categories     []string
+	Currency       string
+	MinSpend       int
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	MaxUses        *int
+	MaxUsesPerUser *int
+	Uses           int
+	Active         bool
+	CreatedBy      int64
+	CreatedAt      time.Time
+}
+
+type PromoRedemption struct {
+	ID          int64
+	PromoCodeID int64
+	UserID      int64
+	OrderID     int64
+	Discount    int
+	CreatedAt   time.Time
+}
+
+func (p *PromoCode) IsValidAt(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.ValidFrom != nil && now.Before(*p.ValidFrom) {
+		return false
+	}
+	if p.ValidUntil != nil && !now.Before(*p.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+func (p *PromoCode) Exhausted() bool {
+	return p.MaxUses != nil && p.Uses >= *p.MaxUses
+}
+
+// AppliesTo проверяет, действует ли код на товар: валюта должна совпадать,
+// а при заданных списках товар должен входить в товары или категории кода.
+func (p *PromoCode) AppliesTo(item *Merch) bool {
+	currency := item.Currency
+	if IsPrimaryCurrency(currency) {
+		currency = PrimaryCurrency
+	}
+	if currency != p.Currency {
+		return false
+	}
+	if len(p.MerchIDs) == 0 && len(p.Categories) == 0 {
+		return true
+	}
+	for _, id := range p.MerchIDs {
+		if id == item.ID {
+			return true
+		}
+	}
+	for _, category := range p.Categories {
+		if item.Category != "" && category == item.Category {
+			return true
+		}
+	}
+	return false
+}
+
+// EligibleSubtotal - сумма строк, на которые действует код.
+func (p *PromoCode) EligibleSubtotal(lines []OrderLine) int {
+	var subtotal int
+	for _, line := range lines {
+		if p.AppliesTo(line.Item) {
+			subtotal += line.Total()
+		}
+	}
+	return subtotal
+}
+
+// Discounts распределяет скидку по строкам заказа (индекс строки -> скидка).
+// Фиксированная скидка делится пропорционально сумме строк, остаток от округления уходит в последнюю строку.
+func (p *PromoCode) Discounts(lines []OrderLine) map[int]int {
+	discounts := make(map[int]int)
+	subtotal := p.EligibleSubtotal(lines)
+	if subtotal == 0 {
+		return discounts
+	}
+
+	if p.Kind == PromoKindPercent {
+		for i, line := range lines {
+			if p.AppliesTo(line.Item) {
+				discounts[i] = line.Total() * p.Value / 100
+			}
+		}
+		return discounts
+	}
+
+	total := p.Value
+	if total > subtotal {
+		total = subtotal
+	}
+	remaining, last := total, -1
+	for i, line := range lines {
+		if !p.AppliesTo(line.Item) {
+			continue
+		}
+		discounts[i] = total * line.Total() / subtotal
+		remaining -= discounts[i]
+		last = i
+	}
+	discounts[last] += remaining
+	return discounts
+}
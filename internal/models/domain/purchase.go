@@ -5,7 +5,33 @@ import "time"
 type Purchase struct {
 	ID           int64
 	UserID       int64
+	OrderID      int64
+	MerchID      int
+	VariantID    int
+	SKU          string
 	Item         string
+	Quantity     int
+	UnitPrice    int
 	Price        int
+	Discount     int
+	PromoCodeID  int64
+	Currency     string
 	PurchaseDate time.Time
+	// подарок: UserID - получатель, GiftedBy - покупатель
+	GiftedBy    int64
+	GiftMessage string
+	GiftFrom    string
+	GiftTo      string
+	// HoldID - покупка оплачена списанием холда (выигранный аукцион)
+	HoldID int64
+	// GroupBuyID - покупка оплачена взносами участников совместной покупки
+	GroupBuyID int64
+	// RaffleID - бесплатный приз розыгрыша
+	RaffleID int64
+	// BundlePurchaseID - покупка составляющей набора, цена учтена в покупке набора
+	BundlePurchaseID int64
+}
+
+func (p *Purchase) IsGift() bool {
+	return p.GiftedBy != 0
 }
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	RaffleStatusOpen      = "open"
+	RaffleStatusDrawn     = "drawn"
+	RaffleStatusCancelled = "cancelled"
+)
+
+type Raffle struct {
+	ID          int64
+	MerchID     int
+	Item        string
+	TicketPrice int
+	// MaxTicketsPerUser == 0 - без ограничения
+	MaxTicketsPerUser int
+	Prizes            int
+	DrawAt            time.Time
+	// Seed держится в секрете до розыгрыша, SeedHash публикуется сразу. Одного seed для выбора победителей
+	// недостаточно: к нему подмешивается RaffleTicketsHash, известный только после закрытия продаж
+	Seed        string
+	SeedHash    string
+	Status      string
+	TicketsSold int
+	CreatedBy   int64
+	CreatedAt   time.Time
+	DrawnAt     *time.Time
+}
+
+// RaffleEntry - одна покупка билетов с номерами FirstTicket .. FirstTicket+Quantity-1.
+type RaffleEntry struct {
+	ID          int64
+	RaffleID    int64
+	UserID      int64
+	Username    string
+	FirstTicket int
+	Quantity    int
+	Amount      int
+	CreatedAt   time.Time
+	RefundedAt  *time.Time
+}
+
+type RaffleWinner struct {
+	RaffleID int64
+	Place    int
+	Ticket   int
+	UserID   int64
+	Username string
+	OrderID  int64
+}
+
+func (r *Raffle) SellsTicketsAt(t time.Time) bool {
+	return r.Status == RaffleStatusOpen && t.Before(r.DrawAt)
+}
+
+// PublishedSeed возвращает seed только после розыгрыша.
+func (r *Raffle) PublishedSeed() string {
+	if r.Status != RaffleStatusDrawn {
+		return ""
+	}
+	return r.Seed
+}
+
+func RaffleSeedHash(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// RaffleTicketsHash - sha256 от списка невозвращенных покупок билетов по возрастанию номеров,
+// по строке "first-last:username\n" на покупку. Список совпадает с /api/raffles/{id}/tickets без возвратов.
+func RaffleTicketsHash(entries []*RaffleEntry) string {
+	active := make([]*RaffleEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.RefundedAt == nil {
+			active = append(active, e)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].FirstTicket < active[j].FirstTicket })
+
+	h := sha256.New()
+	for _, e := range active {
+		h.Write([]byte(strconv.Itoa(e.FirstTicket) + "-" + strconv.Itoa(e.FirstTicket+e.Quantity-1) + ":" + e.Username + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DrawRaffleWinners выбирает победителей по раскрытому seed и итоговому списку билетов. Билеты упорядочиваются
+// по номеру, на место k (с нуля) выигрывает билет с индексом
+// uint64(первые 8 байт sha256(seed + ":" + RaffleTicketsHash + ":" + k)) mod число оставшихся билетов.
+// Билеты выигравшего пользователя выбывают: один пользователь получает не больше одного приза.
+func DrawRaffleWinners(seed string, entries []*RaffleEntry, prizes int) []*RaffleWinner {
+	type ticket struct {
+		number int
+		entry  *RaffleEntry
+	}
+	var tickets []ticket
+	for _, e := range entries {
+		if e.RefundedAt != nil {
+			continue
+		}
+		for i := 0; i < e.Quantity; i++ {
+			tickets = append(tickets, ticket{number: e.FirstTicket + i, entry: e})
+		}
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].number < tickets[j].number })
+
+	key := seed + ":" + RaffleTicketsHash(entries)
+	var winners []*RaffleWinner
+	for place := 0; place < prizes && len(tickets) > 0; place++ {
+		sum := sha256.Sum256([]byte(key + ":" + strconv.Itoa(place)))
+		won := tickets[binary.BigEndian.Uint64(sum[:8])%uint64(len(tickets))]
+		winners = append(winners, &RaffleWinner{
+			RaffleID: won.entry.RaffleID,
+			Place:    place + 1,
+			Ticket:   won.number,
+			UserID:   won.entry.UserID,
+			Username: won.entry.Username,
+		})
+
+		remaining := tickets[:0]
+		for _, t := range tickets {
+			if t.entry.UserID != won.entry.UserID {
+				remaining = append(remaining, t)
+			}
+		}
+		tickets = remaining
+	}
+	return winners
+}
@@ -0,0 +1,13 @@
+package domain
+
+// Recommendation - товар и число покупателей, на котором основана рекомендация.
+type Recommendation struct {
+	Merch  *Merch
+	Buyers int
+}
+
+type UserRecommendations struct {
+	AlsoBought    []*Recommendation
+	Team          string
+	PopularInTeam []*Recommendation
+}
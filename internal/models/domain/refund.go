@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+type Refund struct {
+	ID         int64
+	OrderID    int64
+	PurchaseID int64
+	UserID     int64
+	Amount     int
+	Currency   string
+	CreatedAt  time.Time
+}
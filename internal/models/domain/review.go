@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+const (
+	ReviewStatusPublished = "published"
+	// ReviewStatusFlagged - отзыв виден, но отмечен модератором для проверки
+	ReviewStatusFlagged = "flagged"
+	ReviewStatusHidden  = "hidden"
+)
+
+// MaxReviewLength - предел длины текста отзыва в символах
+const MaxReviewLength = 500
+
+type Review struct {
+	ID          int64
+	MerchID     int
+	Item        string
+	UserID      int64
+	Username    string
+	Rating      int
+	Body        string
+	Status      string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ModeratedBy int64
+	ModeratedAt *time.Time
+}
+
+func IsReviewStatus(status string) bool {
+	switch status {
+	case ReviewStatusPublished, ReviewStatusFlagged, ReviewStatusHidden:
+		return true
+	}
+	return false
+}
+
+// Counted сообщает, входит ли отзыв в рейтинг товара.
+func (r *Review) Counted() bool {
+	return r.Status != ReviewStatusHidden
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+const (
+	MovementTransferIn  = "transfer_in"
+	MovementTransferOut = "transfer_out"
+	MovementPurchase    = "purchase"
+	MovementGift        = "gift"
+	MovementTransferFee = "transfer_fee"
+	MovementFeeIncome   = "fee_income"
+	MovementHoldCapture = "hold_capture"
+	MovementRefund      = "refund"
+)
+
+type Statement struct {
+	ID             int64
+	UserID         int64
+	Period         time.Time
+	OpeningBalance int
+	ClosingBalance int
+	TotalCredits   int
+	TotalDebits    int
+	Movements      []StatementMovement
+	GeneratedAt    time.Time
+}
+
+type StatementMovement struct {
+	Kind         string    `json:"kind"`
+	Amount       int       `json:"amount"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Description  string    `json:"description,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
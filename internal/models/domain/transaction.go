@@ -3,9 +3,12 @@ package domain
 import "time"
 
 type CoinTransaction struct {
-	ID         int64
-	FromUserID int64
-	ToUserID   int64
-	Amount     int
-	CreatedAt  time.Time
+	ID           int64
+	FromUserID   int64
+	ToUserID     int64
+	Amount       int
+	Currency     string
+	Fee          int
+	FeeAccountID int64
+	CreatedAt    time.Time
 }
@@ -2,10 +2,23 @@ package domain
 
 import "time"
 
+const (
+	RoleEmployee = "employee"
+	RoleAdmin    = "admin"
+)
+
 type User struct {
 	ID           int64
 	Username     string
 	PasswordHash string
 	Coins        int
+	HeldCoins    int
+	Role         string
+	Team         string
 	CreatedAt    time.Time
 }
+
+// AvailableCoins - монеты, которые можно потратить с учетом активных холдов.
+func (u *User) AvailableCoins() int {
+	return u.Coins - u.HeldCoins
+}
@@ -0,0 +1,18 @@
+package domain
+
+const PrimaryCurrency = "coin"
+
+type Wallet struct {
+	UserID   int64
+	Currency string
+	Balance  int
+	Held     int
+}
+
+func (w *Wallet) Available() int {
+	return w.Balance - w.Held
+}
+
+func IsPrimaryCurrency(currency string) bool {
+	return currency == "" || currency == PrimaryCurrency
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type WishlistItem struct {
+	UserID      int64
+	Item        *Merch
+	SeenPrice   int
+	SeenInStock bool
+	AddedAt     time.Time
+}
+
+// WishlistAlert - изменение товара из вишлиста с момента прошлой проверки.
+type WishlistAlert struct {
+	UserID     int64
+	MerchID    int
+	Name       string
+	Currency   string
+	OldPrice   int
+	Price      int
+	WasInStock bool
+	InStock    bool
+}
+
+func (a *WishlistAlert) PriceDropped() bool {
+	return a.Price < a.OldPrice
+}
+
+func (a *WishlistAlert) BackInStock() bool {
+	return !a.WasInStock && a.InStock
+}
@@ -1,11 +1,208 @@
 package request
 
+import "time"
+
 type AuthRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
 type SendCoinRequest struct {
-	ToUser string `json:"toUser" binding:"required"`
-	Amount int    `json:"amount" binding:"required,gt=0"`
+	ToUser   string `json:"toUser" binding:"required"`
+	Amount   int    `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency"`
+}
+
+type TransferQuoteRequest struct {
+	Amount int `form:"amount" binding:"required,gt=0"`
+}
+
+type BuyItemRequest struct {
+	Quantity int    `form:"quantity" binding:"omitempty,gt=0"`
+	Variant  string `form:"variant"`
+	Promo    string `form:"promo"`
+}
+
+type CheckoutRequest struct {
+	PromoCode string `json:"promoCode"`
+}
+
+type AddCartItemRequest struct {
+	Item     string `json:"item" binding:"required"`
+	Variant  string `json:"variant"`
+	Quantity int    `json:"quantity" binding:"omitempty,gt=0"`
+}
+
+type CreateVariantRequest struct {
+	SKU        string `json:"sku" binding:"required"`
+	Size       string `json:"size"`
+	Color      string `json:"color"`
+	PriceDelta int    `json:"priceDelta"`
+	Stock      *int   `json:"stock" binding:"omitempty,gte=0"`
+}
+
+type UpdateVariantRequest struct {
+	PriceDelta *int  `json:"priceDelta"`
+	Stock      *int  `json:"stock" binding:"omitempty,gte=0"`
+	Active     *bool `json:"active"`
+}
+
+type ListOrdersRequest struct {
+	Status string `form:"status"`
+}
+
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+type PlaceHoldRequest struct {
+	Username   string `json:"username" binding:"required"`
+	Amount     int    `json:"amount" binding:"required,gt=0"`
+	Currency   string `json:"currency"`
+	Reason     string `json:"reason"`
+	TTLSeconds int    `json:"ttlSeconds" binding:"gte=0"`
+}
+
+type CaptureHoldRequest struct {
+	Amount int `json:"amount" binding:"required,gt=0"`
+}
+
+type CreateMerchRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Price    int    `json:"price" binding:"required,gt=0"`
+	Currency string `json:"currency"`
+	Stock    *int   `json:"stock" binding:"omitempty,gte=0"`
+}
+
+type CreateBundleRequest struct {
+	Name     string                `json:"name" binding:"required"`
+	Price    int                   `json:"price" binding:"required,gt=0"`
+	Currency string                `json:"currency"`
+	Items    []BundleComponentItem `json:"items" binding:"required"`
+}
+
+type BundleComponentItem struct {
+	Item     string `json:"item" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,gt=0"`
+}
+
+type UpdateMerchRequest struct {
+	Price       *int               `json:"price" binding:"omitempty,gt=0"`
+	Currency    *string            `json:"currency"`
+	Category    *string            `json:"category"`
+	Description *string            `json:"description"`
+	Tags        *[]string          `json:"tags"`
+	Attributes  *map[string]string `json:"attributes"`
+	Stock       *int               `json:"stock" binding:"omitempty,gte=0"`
+}
+
+type SearchMerchRequest struct {
+	Query    string   `form:"q"`
+	Category string   `form:"category"`
+	Tags     []string `form:"tag"`
+	Currency string   `form:"currency"`
+	MinPrice *int     `form:"minPrice"`
+	MaxPrice *int     `form:"maxPrice"`
+	Sort     string   `form:"sort"`
+	Page     int      `form:"page" binding:"omitempty,gt=0"`
+	PageSize int      `form:"pageSize" binding:"omitempty,gt=0"`
+}
+
+type SchedulePriceRequest struct {
+	Price         int       `json:"price" binding:"required,gt=0"`
+	EffectiveFrom time.Time `json:"effectiveFrom" binding:"required"`
+}
+
+type RenameMerchRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type CreatePromoCodeRequest struct {
+	Code           string     `json:"code" binding:"required"`
+	Kind           string     `json:"kind" binding:"required,oneof=percent fixed"`
+	Value          int        `json:"value" binding:"required,gt=0"`
+	MerchIDs       []int      `json:"merchIds"`
+	Categories     []string   `json:"categories"`
+	Currency       string     `json:"currency"`
+	MinSpend       int        `json:"minSpend" binding:"gte=0"`
+	ValidFrom      *time.Time `json:"validFrom"`
+	ValidUntil     *time.Time `json:"validUntil"`
+	MaxUses        *int       `json:"maxUses" binding:"omitempty,gt=0"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser" binding:"omitempty,gt=0"`
+}
+
+type PurchaseRuleRequest struct {
+	MaxQuantity       int      `json:"maxQuantity" binding:"gte=0"`
+	PeriodDays        int      `json:"periodDays" binding:"gte=0"`
+	MinAccountAgeDays int      `json:"minAccountAgeDays" binding:"gte=0"`
+	AllowedRoles      []string `json:"allowedRoles"`
+	AllowedTeams      []string `json:"allowedTeams"`
+}
+
+// MerchDropRequest - пустые поля снимают соответствующее ограничение.
+type MerchDropRequest struct {
+	AvailableFrom  *time.Time `json:"availableFrom"`
+	AvailableUntil *time.Time `json:"availableUntil"`
+	Cap            *int       `json:"cap"`
+}
+
+type SetTeamRequest struct {
+	Team string `json:"team"`
+}
+
+type GiftRequest struct {
+	ToUser    string `json:"toUser" binding:"required"`
+	Item      string `json:"item" binding:"required"`
+	Variant   string `json:"variant"`
+	Quantity  int    `json:"quantity" binding:"omitempty,gt=0"`
+	Message   string `json:"message"`
+	PromoCode string `json:"promoCode"`
+}
+
+type WishlistSharingRequest struct {
+	Shared *bool `json:"shared" binding:"required"`
+}
+
+type CreateAuctionRequest struct {
+	Item             string    `json:"item" binding:"required"`
+	StartingPrice    int       `json:"startingPrice" binding:"required,gt=0"`
+	MinIncrement     int       `json:"minIncrement" binding:"required,gt=0"`
+	StartsAt         time.Time `json:"startsAt"`
+	EndsAt           time.Time `json:"endsAt" binding:"required"`
+	AntiSnipeSeconds int       `json:"antiSnipeSeconds" binding:"gte=0"`
+}
+
+type PlaceBidRequest struct {
+	Amount int `json:"amount" binding:"required,gt=0"`
+}
+
+type CreateRaffleRequest struct {
+	Item              string    `json:"item" binding:"required"`
+	TicketPrice       int       `json:"ticketPrice" binding:"required,gt=0"`
+	MaxTicketsPerUser int       `json:"maxTicketsPerUser" binding:"gte=0"`
+	Prizes            int       `json:"prizes" binding:"required,gt=0"`
+	DrawAt            time.Time `json:"drawAt" binding:"required"`
+}
+
+type BuyTicketsRequest struct {
+	Quantity int `json:"quantity" binding:"required,gt=0"`
+}
+
+type CreateGroupBuyRequest struct {
+	Item     string    `json:"item" binding:"required"`
+	Quantity int       `json:"quantity" binding:"required,gt=0"`
+	Deadline time.Time `json:"deadline" binding:"required"`
+}
+
+type PledgeRequest struct {
+	Amount int `json:"amount" binding:"required,gt=0"`
+}
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Body   string `json:"body"`
+}
+
+type ModerateReviewRequest struct {
+	Status string `json:"status" binding:"required"`
 }
@@ -1,6 +1,12 @@
 package response
 
-import "avito-backend-intern-winter25/internal/models/domain"
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"math"
+	"time"
+)
+
+const StatementPeriodLayout = "2006-01"
 
 type AuthResponse struct {
 	Token string `json:"token"`
@@ -37,8 +43,23 @@ type SentTransaction struct {
 }
 
 type MerchResponse struct {
-	Name  string `json:"name"`
-	Price int    `json:"price"`
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	Price    int      `json:"price"`
+	Currency string   `json:"currency"`
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Stock    *int     `json:"stock"`
+	Bundle   bool     `json:"bundle,omitempty"`
+	// окно продаж и остаток тиража - только у товаров дропа
+	AvailableFrom  *time.Time `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time `json:"availableUntil,omitempty"`
+	DropLeft       *int       `json:"dropLeft,omitempty"`
+	// Rating - средняя оценка, округленная до десятых
+	Rating      float64 `json:"rating,omitempty"`
+	ReviewCount int     `json:"reviewCount"`
+	// InWishlist заполняется только в каталоге для текущего пользователя
+	InWishlist bool `json:"inWishlist,omitempty"`
 }
 
 func MerchResponseFromModel(m *domain.Merch) *MerchResponse {
@@ -46,7 +67,763 @@ func MerchResponseFromModel(m *domain.Merch) *MerchResponse {
 		return nil
 	}
 	return &MerchResponse{
-		Name:  m.Name,
-		Price: m.Price,
+		ID:       m.ID,
+		Name:     m.Name,
+		Price:    m.Price,
+		Currency: m.Currency,
+		Category: m.Category,
+		Tags:     m.Tags,
+		Stock:    m.Stock,
+		Bundle:   m.Bundle,
+
+		AvailableFrom:  m.AvailableFrom,
+		AvailableUntil: m.AvailableUntil,
+		DropLeft:       m.DropLeft,
+
+		Rating:      roundRating(m.Rating()),
+		ReviewCount: m.ReviewCount,
+	}
+}
+
+func roundRating(rating float64) float64 {
+	return math.Round(rating*10) / 10
+}
+
+type MerchImageResponse struct {
+	ID          int64  `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+}
+
+func MerchImageResponseFromModel(img *domain.MerchImage) *MerchImageResponse {
+	return &MerchImageResponse{
+		ID:          img.ID,
+		URL:         img.URL,
+		ContentType: img.ContentType,
+	}
+}
+
+type MerchVariantResponse struct {
+	ID         int    `json:"id"`
+	SKU        string `json:"sku"`
+	Size       string `json:"size,omitempty"`
+	Color      string `json:"color,omitempty"`
+	PriceDelta int    `json:"priceDelta"`
+	Stock      *int   `json:"stock"`
+	Active     bool   `json:"active"`
+}
+
+func MerchVariantResponseFromModel(v *domain.MerchVariant) *MerchVariantResponse {
+	return &MerchVariantResponse{
+		ID:         v.ID,
+		SKU:        v.SKU,
+		Size:       v.Size,
+		Color:      v.Color,
+		PriceDelta: v.PriceDelta,
+		Stock:      v.Stock,
+		Active:     v.Active,
+	}
+}
+
+type MerchDetailResponse struct {
+	MerchResponse
+	Description string                     `json:"description"`
+	Attributes  map[string]string          `json:"attributes"`
+	Images      []*MerchImageResponse      `json:"images"`
+	Variants    []*MerchVariantResponse    `json:"variants"`
+	Components  []*BundleComponentResponse `json:"components,omitempty"`
+}
+
+type BundleComponentResponse struct {
+	ID       int    `json:"id"`
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+func BundleComponentsFromModel(components []*domain.BundleComponent) []*BundleComponentResponse {
+	if len(components) == 0 {
+		return nil
+	}
+	resp := make([]*BundleComponentResponse, len(components))
+	for i, c := range components {
+		resp[i] = &BundleComponentResponse{ID: c.Item.ID, Item: c.Item.Name, Quantity: c.Quantity}
+	}
+	return resp
+}
+
+func MerchDetailResponseFromModel(m *domain.Merch) *MerchDetailResponse {
+	resp := &MerchDetailResponse{
+		MerchResponse: *MerchResponseFromModel(m),
+		Description:   m.Description,
+		Attributes:    m.Attributes,
+		Images:        make([]*MerchImageResponse, len(m.Images)),
+		Variants:      make([]*MerchVariantResponse, len(m.Variants)),
+		Components:    BundleComponentsFromModel(m.Components),
+	}
+	if resp.Attributes == nil {
+		resp.Attributes = map[string]string{}
+	}
+	for i, img := range m.Images {
+		resp.Images[i] = MerchImageResponseFromModel(img)
+	}
+	for i, v := range m.Variants {
+		resp.Variants[i] = MerchVariantResponseFromModel(v)
+	}
+	return resp
+}
+
+type MerchPageResponse struct {
+	Items    []*MerchResponse `json:"items"`
+	Total    int              `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"pageSize"`
+}
+
+func MerchPageResponseFromModel(p *domain.MerchPage, page, pageSize int) *MerchPageResponse {
+	resp := &MerchPageResponse{
+		Items:    make([]*MerchResponse, len(p.Items)),
+		Total:    p.Total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for i, m := range p.Items {
+		resp.Items[i] = MerchResponseFromModel(m)
+	}
+	return resp
+}
+
+type WalletResponse struct {
+	Currency  string `json:"currency"`
+	Balance   int    `json:"balance"`
+	Available int    `json:"available"`
+}
+
+func WalletResponseFromModel(w *domain.Wallet) *WalletResponse {
+	if w == nil {
+		return nil
+	}
+	return &WalletResponse{
+		Currency:  w.Currency,
+		Balance:   w.Balance,
+		Available: w.Available(),
+	}
+}
+
+type StatementResponse struct {
+	Period         string                     `json:"period"`
+	OpeningBalance int                        `json:"openingBalance"`
+	ClosingBalance int                        `json:"closingBalance"`
+	TotalCredits   int                        `json:"totalCredits"`
+	TotalDebits    int                        `json:"totalDebits"`
+	Movements      []domain.StatementMovement `json:"movements"`
+	GeneratedAt    time.Time                  `json:"generatedAt"`
+}
+
+func StatementResponseFromModel(s *domain.Statement) *StatementResponse {
+	if s == nil {
+		return nil
+	}
+	movements := s.Movements
+	if movements == nil {
+		movements = []domain.StatementMovement{}
+	}
+	return &StatementResponse{
+		Period:         s.Period.Format(StatementPeriodLayout),
+		OpeningBalance: s.OpeningBalance,
+		ClosingBalance: s.ClosingBalance,
+		TotalCredits:   s.TotalCredits,
+		TotalDebits:    s.TotalDebits,
+		Movements:      movements,
+		GeneratedAt:    s.GeneratedAt,
+	}
+}
+
+type TransferQuoteResponse struct {
+	Amount int `json:"amount"`
+	Fee    int `json:"fee"`
+	Total  int `json:"total"`
+}
+
+func TransferQuoteResponseFromModel(q *domain.TransferQuote) *TransferQuoteResponse {
+	if q == nil {
+		return nil
+	}
+	return &TransferQuoteResponse{
+		Amount: q.Amount,
+		Fee:    q.Fee,
+		Total:  q.Total,
+	}
+}
+
+type HoldResponse struct {
+	ID             int64     `json:"id"`
+	Currency       string    `json:"currency"`
+	Amount         int       `json:"amount"`
+	CapturedAmount int       `json:"capturedAmount"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+func HoldResponseFromModel(h *domain.Hold) *HoldResponse {
+	if h == nil {
+		return nil
+	}
+	return &HoldResponse{
+		ID:             h.ID,
+		Currency:       h.Currency,
+		Amount:         h.Amount,
+		CapturedAmount: h.CapturedAmount,
+		Status:         h.Status,
+		Reason:         h.Reason,
+		ExpiresAt:      h.ExpiresAt,
+	}
+}
+
+type AdminMerchResponse struct {
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	Price       int               `json:"price"`
+	Currency    string            `json:"currency"`
+	Category    string            `json:"category,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	Stock       *int              `json:"stock"`
+	Active      bool              `json:"active"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+	RetiredAt   *time.Time        `json:"retiredAt,omitempty"`
+
+	AvailableFrom  *time.Time `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time `json:"availableUntil,omitempty"`
+	DropCap        *int       `json:"dropCap,omitempty"`
+	DropLeft       *int       `json:"dropLeft,omitempty"`
+
+	Bundle     bool                       `json:"bundle,omitempty"`
+	Components []*BundleComponentResponse `json:"components,omitempty"`
+}
+
+func AdminMerchResponseFromModel(m *domain.Merch) *AdminMerchResponse {
+	if m == nil {
+		return nil
+	}
+	return &AdminMerchResponse{
+		ID:          m.ID,
+		Name:        m.Name,
+		Price:       m.Price,
+		Currency:    m.Currency,
+		Category:    m.Category,
+		Description: m.Description,
+		Tags:        m.Tags,
+		Attributes:  m.Attributes,
+		Stock:       m.Stock,
+		Active:      m.Active,
+		UpdatedAt:   m.UpdatedAt,
+		RetiredAt:   m.RetiredAt,
+
+		AvailableFrom:  m.AvailableFrom,
+		AvailableUntil: m.AvailableUntil,
+		DropCap:        m.DropCap,
+		DropLeft:       m.DropLeft,
+
+		Bundle:     m.Bundle,
+		Components: BundleComponentsFromModel(m.Components),
+	}
+}
+
+type MerchAuditResponse struct {
+	AdminID   int64     `json:"adminId"`
+	Action    string    `json:"action"`
+	Field     string    `json:"field,omitempty"`
+	OldValue  string    `json:"oldValue,omitempty"`
+	NewValue  string    `json:"newValue,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func MerchAuditResponseFromModel(a *domain.MerchAudit) *MerchAuditResponse {
+	if a == nil {
+		return nil
+	}
+	return &MerchAuditResponse{
+		AdminID:   a.AdminID,
+		Action:    a.Action,
+		Field:     a.Field,
+		OldValue:  a.OldValue,
+		NewValue:  a.NewValue,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+type OrderItemResponse struct {
+	Name     string `json:"name"`
+	Variant  string `json:"variant,omitempty"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"`
+	Discount int    `json:"discount,omitempty"`
+	Currency string `json:"currency"`
+	GiftTo   string `json:"giftTo,omitempty"`
+}
+
+type OrderStatusChangeResponse struct {
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type OrderResponse struct {
+	ID        int64                        `json:"orderId"`
+	Status    string                       `json:"status"`
+	Items     []*OrderItemResponse         `json:"items"`
+	Totals    map[string]int               `json:"totals"`
+	History   []*OrderStatusChangeResponse `json:"history"`
+	CreatedAt time.Time                    `json:"createdAt"`
+	UpdatedAt time.Time                    `json:"updatedAt"`
+}
+
+func OrderResponseFromModel(o *domain.Order) *OrderResponse {
+	if o == nil {
+		return nil
+	}
+	resp := &OrderResponse{
+		ID:        o.ID,
+		Status:    o.Status,
+		Items:     make([]*OrderItemResponse, len(o.Purchases)),
+		Totals:    o.Totals(),
+		History:   make([]*OrderStatusChangeResponse, len(o.History)),
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+	for i, p := range o.Purchases {
+		resp.Items[i] = &OrderItemResponse{
+			Name:     p.Item,
+			Variant:  p.SKU,
+			GiftTo:   p.GiftTo,
+			Quantity: p.Quantity,
+			Price:    p.Price,
+			Discount: p.Discount,
+			Currency: p.Currency,
+		}
+	}
+	for i, c := range o.History {
+		resp.History[i] = &OrderStatusChangeResponse{
+			Status:    c.ToStatus,
+			ChangedAt: c.CreatedAt,
+		}
+	}
+	return resp
+}
+
+func OrdersResponseFromModel(orders []*domain.Order) []*OrderResponse {
+	resp := make([]*OrderResponse, len(orders))
+	for i, o := range orders {
+		resp[i] = OrderResponseFromModel(o)
+	}
+	return resp
+}
+
+type CartItemResponse struct {
+	Name      string `json:"name"`
+	Variant   string `json:"variant,omitempty"`
+	Quantity  int    `json:"quantity"`
+	Price     int    `json:"price"`
+	Total     int    `json:"total"`
+	Currency  string `json:"currency"`
+	Stock     *int   `json:"stock"`
+	Available bool   `json:"available"`
+}
+
+type CartResponse struct {
+	Items  []*CartItemResponse `json:"items"`
+	Totals map[string]int      `json:"totals"`
+}
+
+func CartResponseFromModel(items []*domain.CartItem) *CartResponse {
+	resp := &CartResponse{
+		Items:  make([]*CartItemResponse, len(items)),
+		Totals: make(map[string]int),
+	}
+	for i, item := range items {
+		available := item.Item.Active && (item.Variant == nil || item.Variant.Active)
+		resp.Items[i] = &CartItemResponse{
+			Name:      item.Item.Name,
+			Quantity:  item.Quantity,
+			Price:     domain.UnitPrice(item.Item, item.Variant),
+			Total:     item.Total(),
+			Currency:  item.Item.Currency,
+			Stock:     item.Item.Stock,
+			Available: available,
+		}
+		if item.Variant != nil {
+			resp.Items[i].Variant = item.Variant.SKU
+			resp.Items[i].Stock = item.Variant.Stock
+		}
+		if available {
+			resp.Totals[item.Item.Currency] += item.Total()
+		}
+	}
+	return resp
+}
+
+type MerchPriceResponse struct {
+	Price         int       `json:"price"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	Scheduled     bool      `json:"scheduled"`
+}
+
+func MerchPriceResponseFromModel(p *domain.MerchPrice, now time.Time) *MerchPriceResponse {
+	if p == nil {
+		return nil
+	}
+	return &MerchPriceResponse{
+		Price:         p.Price,
+		EffectiveFrom: p.EffectiveFrom,
+		Scheduled:     p.IsScheduled(now),
+	}
+}
+
+type PromoCodeResponse struct {
+	ID             int64      `json:"id"`
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	Value          int        `json:"value"`
+	MerchIDs       []int      `json:"merchIds,omitempty"`
+	Categories     []string   `json:"categories,omitempty"`
+	Currency       string     `json:"currency"`
+	MinSpend       int        `json:"minSpend"`
+	ValidFrom      *time.Time `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time `json:"validUntil,omitempty"`
+	MaxUses        *int       `json:"maxUses,omitempty"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser,omitempty"`
+	Uses           int        `json:"uses"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func PromoCodeResponseFromModel(p *domain.PromoCode) *PromoCodeResponse {
+	if p == nil {
+		return nil
+	}
+	return &PromoCodeResponse{
+		ID:             p.ID,
+		Code:           p.Code,
+		Kind:           p.Kind,
+		Value:          p.Value,
+		MerchIDs:       p.MerchIDs,
+		Categories:     p.Categories,
+		Currency:       p.Currency,
+		MinSpend:       p.MinSpend,
+		ValidFrom:      p.ValidFrom,
+		ValidUntil:     p.ValidUntil,
+		MaxUses:        p.MaxUses,
+		MaxUsesPerUser: p.MaxUsesPerUser,
+		Uses:           p.Uses,
+		Active:         p.Active,
+		CreatedAt:      p.CreatedAt,
+	}
+}
+
+type PurchaseRuleResponse struct {
+	MerchID           int       `json:"merchId"`
+	MaxQuantity       int       `json:"maxQuantity,omitempty"`
+	PeriodDays        int       `json:"periodDays,omitempty"`
+	MinAccountAgeDays int       `json:"minAccountAgeDays,omitempty"`
+	AllowedRoles      []string  `json:"allowedRoles"`
+	AllowedTeams      []string  `json:"allowedTeams"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func PurchaseRuleResponseFromModel(r *domain.MerchPurchaseRule) *PurchaseRuleResponse {
+	resp := &PurchaseRuleResponse{
+		MerchID:           r.MerchID,
+		MaxQuantity:       r.MaxQuantity,
+		PeriodDays:        r.PeriodDays,
+		MinAccountAgeDays: r.MinAccountAgeDays,
+		AllowedRoles:      r.AllowedRoles,
+		AllowedTeams:      r.AllowedTeams,
+		UpdatedAt:         r.UpdatedAt,
+	}
+	if resp.AllowedRoles == nil {
+		resp.AllowedRoles = []string{}
+	}
+	if resp.AllowedTeams == nil {
+		resp.AllowedTeams = []string{}
+	}
+	return resp
+}
+
+type UserTeamResponse struct {
+	Username string `json:"username"`
+	Team     string `json:"team"`
+}
+
+type NotificationResponse struct {
+	ID        int64             `json:"id"`
+	Kind      string            `json:"kind"`
+	Message   string            `json:"message"`
+	Data      map[string]string `json:"data"`
+	Read      bool              `json:"read"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+func NotificationResponseFromModel(n *domain.Notification) *NotificationResponse {
+	return &NotificationResponse{
+		ID:        n.ID,
+		Kind:      n.Kind,
+		Message:   n.Message,
+		Data:      n.Data,
+		Read:      n.IsRead(),
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+type WishlistItemResponse struct {
+	*MerchResponse
+	Available bool      `json:"available"`
+	AddedAt   time.Time `json:"addedAt"`
+}
+
+type WishlistResponse struct {
+	Owner  string                  `json:"owner,omitempty"`
+	Shared bool                    `json:"shared"`
+	Items  []*WishlistItemResponse `json:"items"`
+}
+
+func WishlistResponseFromModel(owner string, shared bool, items []*domain.WishlistItem) *WishlistResponse {
+	resp := &WishlistResponse{
+		Owner:  owner,
+		Shared: shared,
+		Items:  make([]*WishlistItemResponse, len(items)),
+	}
+	for i, item := range items {
+		resp.Items[i] = &WishlistItemResponse{
+			MerchResponse: MerchResponseFromModel(item.Item),
+			Available:     item.Item.Active && (item.Item.Stock == nil || *item.Item.Stock > 0),
+			AddedAt:       item.AddedAt,
+		}
+	}
+	return resp
+}
+
+type AuctionBidResponse struct {
+	User      string    `json:"user"`
+	Amount    int       `json:"amount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func AuctionBidResponseFromModel(b *domain.AuctionBid) *AuctionBidResponse {
+	if b == nil {
+		return nil
+	}
+	return &AuctionBidResponse{User: b.Username, Amount: b.Amount, CreatedAt: b.CreatedAt}
+}
+
+type AuctionResponse struct {
+	ID               int64                 `json:"id"`
+	Item             string                `json:"item"`
+	Currency         string                `json:"currency"`
+	StartingPrice    int                   `json:"startingPrice"`
+	MinIncrement     int                   `json:"minIncrement"`
+	MinBid           int                   `json:"minBid"`
+	AntiSnipeSeconds int                   `json:"antiSnipeSeconds"`
+	StartsAt         time.Time             `json:"startsAt"`
+	EndsAt           time.Time             `json:"endsAt"`
+	Status           string                `json:"status"`
+	TopBid           *AuctionBidResponse   `json:"topBid,omitempty"`
+	OrderID          int64                 `json:"orderId,omitempty"`
+	Bids             []*AuctionBidResponse `json:"bids,omitempty"`
+}
+
+func AuctionResponseFromModel(a *domain.Auction, bids []*domain.AuctionBid) *AuctionResponse {
+	resp := &AuctionResponse{
+		ID:               a.ID,
+		Item:             a.Item,
+		Currency:         a.Currency,
+		StartingPrice:    a.StartingPrice,
+		MinIncrement:     a.MinIncrement,
+		MinBid:           a.MinBid(),
+		AntiSnipeSeconds: int(a.AntiSnipe / time.Second),
+		StartsAt:         a.StartsAt,
+		EndsAt:           a.EndsAt,
+		Status:           a.Status,
+		TopBid:           AuctionBidResponseFromModel(a.TopBid),
+		OrderID:          a.OrderID,
+	}
+	for _, b := range bids {
+		resp.Bids = append(resp.Bids, AuctionBidResponseFromModel(b))
+	}
+	return resp
+}
+
+type RaffleWinnerResponse struct {
+	Place  int    `json:"place"`
+	Ticket int    `json:"ticket"`
+	User   string `json:"user"`
+}
+
+type RaffleResponse struct {
+	ID                int64                   `json:"id"`
+	Item              string                  `json:"item"`
+	TicketPrice       int                     `json:"ticketPrice"`
+	MaxTicketsPerUser int                     `json:"maxTicketsPerUser,omitempty"`
+	Prizes            int                     `json:"prizes"`
+	DrawAt            time.Time               `json:"drawAt"`
+	SeedHash          string                  `json:"seedHash"`
+	Seed              string                  `json:"seed,omitempty"`
+	Status            string                  `json:"status"`
+	TicketsSold       int                     `json:"ticketsSold"`
+	DrawnAt           *time.Time              `json:"drawnAt,omitempty"`
+	Winners           []*RaffleWinnerResponse `json:"winners,omitempty"`
+}
+
+func RaffleResponseFromModel(r *domain.Raffle, winners []*domain.RaffleWinner) *RaffleResponse {
+	resp := &RaffleResponse{
+		ID:                r.ID,
+		Item:              r.Item,
+		TicketPrice:       r.TicketPrice,
+		MaxTicketsPerUser: r.MaxTicketsPerUser,
+		Prizes:            r.Prizes,
+		DrawAt:            r.DrawAt,
+		SeedHash:          r.SeedHash,
+		Seed:              r.PublishedSeed(),
+		Status:            r.Status,
+		TicketsSold:       r.TicketsSold,
+		DrawnAt:           r.DrawnAt,
+	}
+	for _, w := range winners {
+		resp.Winners = append(resp.Winners, &RaffleWinnerResponse{Place: w.Place, Ticket: w.Ticket, User: w.Username})
+	}
+	return resp
+}
+
+type RaffleEntryResponse struct {
+	User        string     `json:"user"`
+	FirstTicket int        `json:"firstTicket"`
+	LastTicket  int        `json:"lastTicket"`
+	Amount      int        `json:"amount"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RefundedAt  *time.Time `json:"refundedAt,omitempty"`
+}
+
+func RaffleEntryResponseFromModel(e *domain.RaffleEntry) *RaffleEntryResponse {
+	return &RaffleEntryResponse{
+		User:        e.Username,
+		FirstTicket: e.FirstTicket,
+		LastTicket:  e.FirstTicket + e.Quantity - 1,
+		Amount:      e.Amount,
+		CreatedAt:   e.CreatedAt,
+		RefundedAt:  e.RefundedAt,
+	}
+}
+
+type GroupBuyPledgeResponse struct {
+	User      string    `json:"user"`
+	Amount    int       `json:"amount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type GroupBuyResponse struct {
+	ID        int64                     `json:"id"`
+	Item      string                    `json:"item"`
+	Quantity  int                       `json:"quantity"`
+	Currency  string                    `json:"currency"`
+	Target    int                       `json:"target"`
+	Pledged   int                       `json:"pledged"`
+	Remaining int                       `json:"remaining"`
+	Creator   string                    `json:"creator"`
+	Deadline  time.Time                 `json:"deadline"`
+	Status    string                    `json:"status"`
+	OrderID   int64                     `json:"orderId,omitempty"`
+	Pledges   []*GroupBuyPledgeResponse `json:"pledges,omitempty"`
+}
+
+func GroupBuyResponseFromModel(g *domain.GroupBuy, pledges []*domain.GroupBuyPledge) *GroupBuyResponse {
+	resp := &GroupBuyResponse{
+		ID:        g.ID,
+		Item:      g.Item,
+		Quantity:  g.Quantity,
+		Currency:  g.Currency,
+		Target:    g.Target,
+		Pledged:   g.Pledged,
+		Remaining: g.Remaining(),
+		Creator:   g.Creator,
+		Deadline:  g.Deadline,
+		Status:    g.Status,
+		OrderID:   g.OrderID,
+	}
+	for _, p := range pledges {
+		resp.Pledges = append(resp.Pledges, &GroupBuyPledgeResponse{User: p.Username, Amount: p.Amount, CreatedAt: p.CreatedAt})
+	}
+	return resp
+}
+
+type ReviewResponse struct {
+	ID        int64     `json:"id"`
+	Item      string    `json:"item"`
+	User      string    `json:"user"`
+	Rating    int       `json:"rating"`
+	Body      string    `json:"body"`
+	Status    string    `json:"status,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func ReviewResponseFromModel(r *domain.Review) *ReviewResponse {
+	return &ReviewResponse{
+		ID:        r.ID,
+		Item:      r.Item,
+		User:      r.Username,
+		Rating:    r.Rating,
+		Body:      r.Body,
+		Status:    r.Status,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+type MerchReviewsResponse struct {
+	Item        string            `json:"item"`
+	Rating      float64           `json:"rating"`
+	ReviewCount int               `json:"reviewCount"`
+	Reviews     []*ReviewResponse `json:"reviews"`
+}
+
+func MerchReviewsResponseFromModel(m *domain.Merch, reviews []*domain.Review) *MerchReviewsResponse {
+	resp := &MerchReviewsResponse{
+		Item:        m.Name,
+		Rating:      roundRating(m.Rating()),
+		ReviewCount: m.ReviewCount,
+		Reviews:     make([]*ReviewResponse, len(reviews)),
+	}
+	for i, r := range reviews {
+		resp.Reviews[i] = ReviewResponseFromModel(r)
+		// статус модерации виден только администраторам
+		resp.Reviews[i].Status = ""
+	}
+	return resp
+}
+
+type RecommendationResponse struct {
+	*MerchResponse
+	Buyers int `json:"buyers"`
+}
+
+func RecommendationsFromModel(recs []*domain.Recommendation) []*RecommendationResponse {
+	resp := make([]*RecommendationResponse, len(recs))
+	for i, r := range recs {
+		resp[i] = &RecommendationResponse{MerchResponse: MerchResponseFromModel(r.Merch), Buyers: r.Buyers}
+	}
+	return resp
+}
+
+type UserRecommendationsResponse struct {
+	AlsoBought    []*RecommendationResponse `json:"alsoBought"`
+	Team          string                    `json:"team,omitempty"`
+	PopularInTeam []*RecommendationResponse `json:"popularInTeam"`
+}
+
+func UserRecommendationsResponseFromModel(r *domain.UserRecommendations) *UserRecommendationsResponse {
+	return &UserRecommendationsResponse{
+		AlsoBought:    RecommendationsFromModel(r.AlsoBought),
+		Team:          r.Team,
+		PopularInTeam: RecommendationsFromModel(r.PopularInTeam),
 	}
 }
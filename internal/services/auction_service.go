@@ -0,0 +1,314 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidAuction = errors.New("auction needs a positive starting price and increment and must end in the future after it starts")
+	ErrAuctionNotOpen = errors.New("auction is not accepting bids")
+	ErrBidTooLow      = errors.New("bid is too low")
+)
+
+const (
+	// auctionHoldGrace - запас срока холда после конца аукциона, чтобы задача расчета успела его списать
+	auctionHoldGrace = 7 * 24 * time.Hour
+	auctionBidsLimit = 50
+)
+
+type AuctionService struct {
+	auctionRepo  storage.AuctionRepository
+	merchRepo    storage.MerchRepository
+	merchService *MerchService
+	orderRepo    storage.OrderRepository
+	purchaseRepo storage.PurchaseRepository
+	notifyRepo   storage.NotificationRepository
+	holdService  *HoldService
+	db           *sql.DB
+}
+
+func NewAuctionService(
+	auctionRepo storage.AuctionRepository,
+	merchRepo storage.MerchRepository,
+	merchService *MerchService,
+	orderRepo storage.OrderRepository,
+	purchaseRepo storage.PurchaseRepository,
+	notifyRepo storage.NotificationRepository,
+	holdService *HoldService,
+	db *sql.DB,
+) *AuctionService {
+	return &AuctionService{
+		auctionRepo:  auctionRepo,
+		merchRepo:    merchRepo,
+		merchService: merchService,
+		orderRepo:    orderRepo,
+		purchaseRepo: purchaseRepo,
+		notifyRepo:   notifyRepo,
+		holdService:  holdService,
+		db:           db,
+	}
+}
+
+// CreateAuction выставляет товар на аукцион. Штука товара сразу снимается с остатка и возвращается,
+// если аукцион отменен или закончился без ставок.
+func (s *AuctionService) CreateAuction(ctx context.Context, adminID int64, itemName string, auction *domain.Auction) (*domain.Auction, error) {
+	now := time.Now()
+	if auction.StartingPrice <= 0 || auction.MinIncrement <= 0 || auction.AntiSnipe < 0 ||
+		!auction.EndsAt.After(auction.StartsAt) || !auction.EndsAt.After(now) {
+		return nil, ErrInvalidAuction
+	}
+	item, err := s.merchRepo.FindByName(ctx, itemName)
+	if err != nil {
+		return nil, err
+	}
+	if item.Bundle {
+		return nil, fmt.Errorf("%w: %s is a bundle", ErrBundleUnsupported, item.Name)
+	}
+	if err := s.merchService.RequireNoVariants(ctx, item); err != nil {
+		return nil, err
+	}
+
+	auction.MerchID = item.ID
+	auction.Item = item.Name
+	auction.Currency = orderCurrency(item)
+	auction.CreatedBy = adminID
+	auction.CreatedAt = now
+	err = runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := s.merchRepo.DecrementStock(ctx, tx, item.ID, 1); err != nil {
+			if errors.Is(err, storage.ErrMerchOutOfStock) {
+				return fmt.Errorf("%w: %s", ErrOutOfStock, item.Name)
+			}
+			return fmt.Errorf("failed to decrement stock: %w", err)
+		}
+		return s.auctionRepo.Create(ctx, tx, auction)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return auction, nil
+}
+
+// ListAuctions возвращает идущие и будущие аукционы.
+func (s *AuctionService) ListAuctions(ctx context.Context) ([]*domain.Auction, error) {
+	return s.auctionRepo.GetActive(ctx, time.Now())
+}
+
+// GetAuction возвращает аукцион и последние ставки, старшие первыми.
+func (s *AuctionService) GetAuction(ctx context.Context, auctionID int64) (*domain.Auction, []*domain.AuctionBid, error) {
+	auction, err := s.auctionRepo.FindByID(ctx, auctionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	bids, err := s.auctionRepo.GetBids(ctx, auctionID, auctionBidsLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return auction, bids, nil
+}
+
+// PlaceBid резервирует сумму ставки холдом и освобождает холд перебитой ставки. Строка аукциона
+// заблокирована до конца транзакции, поэтому ставки одного аукциона применяются строго по очереди.
+// Ставка в последние AntiSnipe до конца продлевает аукцион.
+func (s *AuctionService) PlaceBid(ctx context.Context, userID, auctionID int64, amount int) (*domain.Auction, error) {
+	var auction *domain.Auction
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		auction, err = s.auctionRepo.FindByIDForUpdate(ctx, tx, auctionID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if !auction.AcceptsBidsAt(now) {
+			return fmt.Errorf("%w: bidding is open from %s to %s", ErrAuctionNotOpen,
+				auction.StartsAt.UTC().Format(time.RFC3339), auction.EndsAt.UTC().Format(time.RFC3339))
+		}
+		if amount < auction.MinBid() {
+			return fmt.Errorf("%w: minimum bid is %d %s", ErrBidTooLow, auction.MinBid(), auction.Currency)
+		}
+		if err := s.merchService.CheckRulesTx(ctx, tx, userID, &domain.Merch{ID: auction.MerchID, Name: auction.Item}, 1); err != nil {
+			return err
+		}
+
+		// холд перебитой ставки освобождается до нового резерва: поднимающему свою ставку хватит своих же денег
+		previous := auction.TopBid
+		if previous != nil {
+			if _, err := s.holdService.ReleaseTx(ctx, tx, previous.HoldID); err != nil && !errors.Is(err, ErrHoldNotActive) {
+				return fmt.Errorf("failed to release outbid hold: %w", err)
+			}
+		}
+
+		auction.ExtendForBidAt(now)
+		reason := fmt.Sprintf("auction #%d: %s", auction.ID, auction.Item)
+		hold, err := s.holdService.PlaceHoldTx(ctx, tx, userID, auction.Currency, amount, reason,
+			auction.EndsAt.Sub(now)+auctionHoldGrace)
+		if err != nil {
+			return err
+		}
+
+		bid := &domain.AuctionBid{AuctionID: auction.ID, UserID: userID, Amount: amount, HoldID: hold.ID, CreatedAt: now}
+		if err := s.auctionRepo.CreateBid(ctx, tx, bid); err != nil {
+			return err
+		}
+		auction.TopBid = bid
+		if err := s.auctionRepo.Update(ctx, tx, auction); err != nil {
+			return err
+		}
+
+		if previous != nil && previous.UserID != userID {
+			return s.notifyTx(ctx, tx, previous.UserID, domain.NotificationAuctionOutbid, auction,
+				fmt.Sprintf("Your bid on %s was outbid: the top bid is now %d %s", auction.Item, amount, auction.Currency))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.auctionRepo.FindByID(ctx, auctionID)
+}
+
+// CancelAuction снимает открытый аукцион: холд лучшей ставки освобождается, товар возвращается на остаток.
+func (s *AuctionService) CancelAuction(ctx context.Context, auctionID int64) (*domain.Auction, error) {
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		auction, err := s.auctionRepo.FindByIDForUpdate(ctx, tx, auctionID)
+		if err != nil {
+			return err
+		}
+		if auction.Status != domain.AuctionStatusOpen {
+			return fmt.Errorf("%w: auction is %s", ErrAuctionNotOpen, auction.Status)
+		}
+		if auction.TopBid != nil {
+			if _, err := s.holdService.ReleaseTx(ctx, tx, auction.TopBid.HoldID); err != nil && !errors.Is(err, ErrHoldNotActive) {
+				return fmt.Errorf("failed to release bid hold: %w", err)
+			}
+		}
+		return s.closeUnsoldTx(ctx, tx, auction, domain.AuctionStatusCancelled)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.auctionRepo.FindByID(ctx, auctionID)
+}
+
+// SettleAuctions завершает аукционы, время которых вышло. Каждый аукцион рассчитывается в своей транзакции;
+// аукционы, которые рассчитывает другой экземпляр задачи, пропускаются.
+func (s *AuctionService) SettleAuctions(ctx context.Context) error {
+	for {
+		claimed := false
+		err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+			auction, err := s.auctionRepo.ClaimDue(ctx, tx, time.Now())
+			if err != nil {
+				if errors.Is(err, storage.ErrAuctionNotFound) {
+					return nil
+				}
+				return err
+			}
+			claimed = true
+			return s.settleTx(ctx, tx, auction)
+		})
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return nil
+		}
+	}
+}
+
+// settleTx списывает холд лучшей ставки и оформляет победителю заказ с покупкой.
+// Деньги уходят списанием холда, поэтому покупка ссылается на него и в выписке не дублируется.
+func (s *AuctionService) settleTx(ctx context.Context, tx *sql.Tx, auction *domain.Auction) error {
+	bid := auction.TopBid
+	if bid == nil {
+		return s.closeUnsoldTx(ctx, tx, auction, domain.AuctionStatusUnsold)
+	}
+	// правила проверялись при ставке, но к концу аукциона победитель мог, например, исчерпать лимит покупок
+	if err := s.merchService.CheckRulesTx(ctx, tx, bid.UserID, &domain.Merch{ID: auction.MerchID, Name: auction.Item}, 1); err != nil {
+		if !isRuleViolation(err) {
+			return err
+		}
+		log.Printf("auction %d: winner %d can no longer buy the item: %v", auction.ID, bid.UserID, err)
+		if _, err := s.holdService.ReleaseTx(ctx, tx, bid.HoldID); err != nil && !errors.Is(err, ErrHoldNotActive) {
+			return fmt.Errorf("failed to release bid hold: %w", err)
+		}
+		return s.closeUnsoldTx(ctx, tx, auction, domain.AuctionStatusUnsold)
+	}
+	if _, err := s.holdService.CaptureTx(ctx, tx, bid.HoldID, bid.Amount); err != nil {
+		if errors.Is(err, ErrHoldNotActive) {
+			// холд истек, пока расчет не выполнялся: списать нечего, товар возвращается на остаток
+			log.Printf("auction %d: winning hold %d is no longer active", auction.ID, bid.HoldID)
+			return s.closeUnsoldTx(ctx, tx, auction, domain.AuctionStatusUnsold)
+		}
+		return fmt.Errorf("failed to capture winning bid: %w", err)
+	}
+
+	now := time.Now()
+	order := &domain.Order{UserID: bid.UserID, CreatedAt: now}
+	if err := s.orderRepo.Create(ctx, tx, order); err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+	purchase := &domain.Purchase{
+		UserID:       bid.UserID,
+		OrderID:      order.ID,
+		MerchID:      auction.MerchID,
+		Item:         auction.Item,
+		Quantity:     1,
+		UnitPrice:    bid.Amount,
+		Price:        bid.Amount,
+		Currency:     auction.Currency,
+		PurchaseDate: now,
+		HoldID:       bid.HoldID,
+	}
+	if err := s.purchaseRepo.Create(ctx, tx, purchase); err != nil {
+		return fmt.Errorf("failed to create purchase: %w", err)
+	}
+
+	auction.Status = domain.AuctionStatusSettled
+	auction.OrderID = order.ID
+	auction.SettledAt = &now
+	if err := s.auctionRepo.Update(ctx, tx, auction); err != nil {
+		return err
+	}
+	return s.notifyTx(ctx, tx, bid.UserID, domain.NotificationAuctionWon, auction,
+		fmt.Sprintf("You won %s for %d %s", auction.Item, bid.Amount, auction.Currency))
+}
+
+func (s *AuctionService) closeUnsoldTx(ctx context.Context, tx *sql.Tx, auction *domain.Auction, status string) error {
+	if err := s.merchRepo.IncrementStock(ctx, tx, auction.MerchID, 1); err != nil {
+		return err
+	}
+	now := time.Now()
+	auction.Status = status
+	auction.SettledAt = &now
+	return s.auctionRepo.Update(ctx, tx, auction)
+}
+
+func (s *AuctionService) notifyTx(ctx context.Context, tx *sql.Tx, userID int64, kind string, auction *domain.Auction, message string) error {
+	data := map[string]string{
+		"auctionId": strconv.FormatInt(auction.ID, 10),
+		"item":      auction.Item,
+	}
+	if auction.TopBid != nil {
+		data["amount"] = strconv.Itoa(auction.TopBid.Amount)
+		data["currency"] = auction.Currency
+	}
+	if auction.OrderID != 0 {
+		data["orderId"] = strconv.FormatInt(auction.OrderID, 10)
+	}
+	if err := s.notifyRepo.Create(ctx, tx, &domain.Notification{
+		UserID:  userID,
+		Kind:    kind,
+		Message: message,
+		Data:    data,
+	}); err != nil {
+		return fmt.Errorf("failed to create auction notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrCartEmpty        = errors.New("cart is empty")
+	ErrMerchUnavailable = errors.New("item is no longer available")
+)
+
+type CartService struct {
+	cartRepo     storage.CartRepository
+	merchRepo    storage.MerchRepository
+	merchService *MerchService
+	db           *sql.DB
+}
+
+func NewCartService(
+	cartRepo storage.CartRepository,
+	merchRepo storage.MerchRepository,
+	merchService *MerchService,
+	db *sql.DB,
+) *CartService {
+	return &CartService{
+		cartRepo:     cartRepo,
+		merchRepo:    merchRepo,
+		merchService: merchService,
+		db:           db,
+	}
+}
+
+func (s *CartService) GetCart(ctx context.Context, userID int64) ([]*domain.CartItem, error) {
+	return s.cartRepo.GetByUser(ctx, nil, userID)
+}
+
+func (s *CartService) AddItem(ctx context.Context, userID int64, itemName, variantSKU string, quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	item, err := s.merchRepo.FindByName(ctx, itemName)
+	if err != nil {
+		return err
+	}
+	variant, err := s.merchService.ResolveVariant(ctx, item, variantSKU)
+	if err != nil {
+		return err
+	}
+	// окончательно остаток проверяется при оформлении, здесь только отсекаем заведомо невозможное
+	if item.Limited() && *item.Stock < quantity {
+		return fmt.Errorf("%w: %s", ErrOutOfStock, item.Name)
+	}
+	if item.DropLeft != nil && *item.DropLeft < quantity {
+		return fmt.Errorf("%w: %s drop is sold out", ErrOutOfStock, item.Name)
+	}
+	// до начала дропа положить в корзину можно, после окончания - нет
+	if item.AvailableUntil != nil && !time.Now().Before(*item.AvailableUntil) {
+		return fmt.Errorf("%w: %s", ErrSaleEnded, item.Name)
+	}
+	if variant != nil && variant.Limited() && *variant.Stock < quantity {
+		return fmt.Errorf("%w: %s", ErrOutOfStock, variant.SKU)
+	}
+	return s.cartRepo.AddItem(ctx, userID, item.ID, variantID(variant), quantity)
+}
+
+// RemoveItem ищет товар в самой корзине, чтобы можно было убрать и снятый с продажи.
+// Без SKU удаляются все варианты товара.
+func (s *CartService) RemoveItem(ctx context.Context, userID int64, itemName, variantSKU string) error {
+	items, err := s.cartRepo.GetByUser(ctx, nil, userID)
+	if err != nil {
+		return err
+	}
+	removed := false
+	for _, item := range items {
+		if item.Item.Name != itemName {
+			continue
+		}
+		if variantSKU != "" && (item.Variant == nil || item.Variant.SKU != variantSKU) {
+			continue
+		}
+		if err := s.cartRepo.RemoveItem(ctx, userID, item.Item.ID, variantID(item.Variant)); err != nil {
+			return err
+		}
+		removed = true
+	}
+	if !removed {
+		return storage.ErrCartItemNotFound
+	}
+	return nil
+}
+
+// Checkout оформляет всю корзину одним заказом и очищает ее в той же транзакции.
+// promoCode необязателен.
+func (s *CartService) Checkout(ctx context.Context, userID int64, promoCode string) (*domain.Order, error) {
+	var order *domain.Order
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		items, err := s.cartRepo.GetByUser(ctx, tx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get cart: %w", err)
+		}
+		if len(items) == 0 {
+			return ErrCartEmpty
+		}
+
+		lines := make([]domain.OrderLine, len(items))
+		for i, item := range items {
+			if !item.Item.Active {
+				return fmt.Errorf("%w: %s", ErrMerchUnavailable, item.Item.Name)
+			}
+			if item.Variant != nil && !item.Variant.Active {
+				return fmt.Errorf("%w: %s", ErrMerchUnavailable, item.Variant.SKU)
+			}
+			lines[i] = domain.OrderLine{Item: item.Item, Variant: item.Variant, Quantity: item.Quantity}
+		}
+
+		order, err = s.merchService.PlaceOrderTx(ctx, tx, userID, lines, promoCode)
+		if err != nil {
+			return err
+		}
+		return s.cartRepo.Clear(ctx, tx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	reportStock(order)
+	return order, nil
+}
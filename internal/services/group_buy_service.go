@@ -0,0 +1,349 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidGroupBuy     = errors.New("group buy needs a positive quantity and a deadline in the future")
+	ErrGroupBuyNotOpen     = errors.New("group buy is not accepting pledges")
+	ErrPledgeExceedsTarget = errors.New("pledge exceeds the remaining amount")
+	ErrTooManyGroupBuys    = errors.New("too many open group buys")
+)
+
+// groupBuyHoldGrace - запас срока холда взноса после дедлайна, чтобы задача истечения успела его освободить
+const groupBuyHoldGrace = 24 * time.Hour
+
+type GroupBuyService struct {
+	groupBuyRepo storage.GroupBuyRepository
+	merchRepo    storage.MerchRepository
+	merchService *MerchService
+	orderRepo    storage.OrderRepository
+	purchaseRepo storage.PurchaseRepository
+	notifyRepo   storage.NotificationRepository
+	holdService  *HoldService
+	db           *sql.DB
+	policy       domain.GroupBuyPolicy
+}
+
+func NewGroupBuyService(
+	groupBuyRepo storage.GroupBuyRepository,
+	merchRepo storage.MerchRepository,
+	merchService *MerchService,
+	orderRepo storage.OrderRepository,
+	purchaseRepo storage.PurchaseRepository,
+	notifyRepo storage.NotificationRepository,
+	holdService *HoldService,
+	db *sql.DB,
+	policy domain.GroupBuyPolicy,
+) *GroupBuyService {
+	return &GroupBuyService{
+		groupBuyRepo: groupBuyRepo,
+		merchRepo:    merchRepo,
+		merchService: merchService,
+		orderRepo:    orderRepo,
+		purchaseRepo: purchaseRepo,
+		notifyRepo:   notifyRepo,
+		holdService:  holdService,
+		db:           db,
+		policy:       policy,
+	}
+}
+
+// CreateGroupBuy открывает сбор на quantity штук товара. Цель - текущая цена. Создатель ничего не платит,
+// поэтому штуки снимаются с остатка только при завершении сбора, а размер, срок и число открытых сборов
+// ограничены политикой. Товар получает создатель, поэтому правила покупки проверяются для него.
+func (s *GroupBuyService) CreateGroupBuy(ctx context.Context, creatorID int64, itemName string, quantity int, deadline time.Time) (*domain.GroupBuy, error) {
+	now := time.Now()
+	if quantity <= 0 || !deadline.After(now) {
+		return nil, ErrInvalidGroupBuy
+	}
+	if quantity > s.policy.MaxQuantity {
+		return nil, fmt.Errorf("%w: at most %d items", ErrInvalidGroupBuy, s.policy.MaxQuantity)
+	}
+	if deadline.After(now.Add(s.policy.MaxDuration)) {
+		return nil, fmt.Errorf("%w: deadline must be within %s", ErrInvalidGroupBuy, s.policy.MaxDuration)
+	}
+	item, err := s.merchRepo.FindByName(ctx, itemName)
+	if err != nil {
+		return nil, err
+	}
+	if item.Bundle {
+		return nil, fmt.Errorf("%w: %s is a bundle", ErrBundleUnsupported, item.Name)
+	}
+	if !item.Drop().IsEmpty() {
+		return nil, fmt.Errorf("%w: %s is a limited drop", ErrMerchUnavailable, item.Name)
+	}
+	if err := s.merchService.RequireNoVariants(ctx, item); err != nil {
+		return nil, err
+	}
+	// остаток окончательно проверяется при завершении, здесь только отсекаем заведомо невозможное
+	if item.Limited() && *item.Stock < quantity {
+		return nil, fmt.Errorf("%w: %s", ErrOutOfStock, item.Name)
+	}
+
+	groupBuy := &domain.GroupBuy{
+		MerchID:   item.ID,
+		Item:      item.Name,
+		Quantity:  quantity,
+		Currency:  orderCurrency(item),
+		Target:    item.Price * quantity,
+		CreatorID: creatorID,
+		Deadline:  deadline,
+		CreatedAt: now,
+	}
+	err = runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.merchService.CheckRulesTx(ctx, tx, creatorID, item, quantity); err != nil {
+			return err
+		}
+		open, err := s.groupBuyRepo.CountOpenByCreator(ctx, tx, creatorID)
+		if err != nil {
+			return err
+		}
+		if open >= s.policy.MaxOpenPerCreator {
+			return fmt.Errorf("%w: at most %d at a time", ErrTooManyGroupBuys, s.policy.MaxOpenPerCreator)
+		}
+		return s.groupBuyRepo.Create(ctx, tx, groupBuy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.groupBuyRepo.FindByID(ctx, groupBuy.ID)
+}
+
+func (s *GroupBuyService) ListGroupBuys(ctx context.Context) ([]*domain.GroupBuy, error) {
+	return s.groupBuyRepo.GetOpen(ctx)
+}
+
+func (s *GroupBuyService) GetGroupBuy(ctx context.Context, groupBuyID int64) (*domain.GroupBuy, []*domain.GroupBuyPledge, error) {
+	groupBuy, err := s.groupBuyRepo.FindByID(ctx, groupBuyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pledges, err := s.groupBuyRepo.GetPledges(ctx, nil, groupBuyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return groupBuy, pledges, nil
+}
+
+// Pledge резервирует взнос холдом. Взнос, которым набирается цель, в той же транзакции списывает
+// холды всех участников и оформляет заказ создателю: покупка либо совершается целиком, либо не совершается.
+func (s *GroupBuyService) Pledge(ctx context.Context, userID, groupBuyID int64, amount int) (*domain.GroupBuy, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		groupBuy, err := s.groupBuyRepo.FindByIDForUpdate(ctx, tx, groupBuyID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if !groupBuy.AcceptsPledgesAt(now) {
+			return fmt.Errorf("%w: group buy is %s until %s", ErrGroupBuyNotOpen, groupBuy.Status,
+				groupBuy.Deadline.UTC().Format(time.RFC3339))
+		}
+		if amount > groupBuy.Remaining() {
+			return fmt.Errorf("%w: %d %s left", ErrPledgeExceedsTarget, groupBuy.Remaining(), groupBuy.Currency)
+		}
+
+		reason := fmt.Sprintf("group buy #%d: %s", groupBuy.ID, groupBuy.Item)
+		hold, err := s.holdService.PlaceHoldTx(ctx, tx, userID, groupBuy.Currency, amount, reason,
+			groupBuy.Deadline.Sub(now)+groupBuyHoldGrace)
+		if err != nil {
+			return err
+		}
+		if err := s.groupBuyRepo.CreatePledge(ctx, tx, &domain.GroupBuyPledge{
+			GroupBuyID: groupBuy.ID,
+			UserID:     userID,
+			Amount:     amount,
+			HoldID:     hold.ID,
+			CreatedAt:  now,
+		}); err != nil {
+			return err
+		}
+
+		groupBuy.Pledged += amount
+		if groupBuy.Pledged < groupBuy.Target {
+			return nil
+		}
+		return s.completeTx(ctx, tx, groupBuy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.groupBuyRepo.FindByID(ctx, groupBuyID)
+}
+
+// ExpireGroupBuys закрывает несобранные к дедлайну покупки: холды взносов освобождаются.
+func (s *GroupBuyService) ExpireGroupBuys(ctx context.Context) error {
+	for {
+		claimed := false
+		err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+			groupBuy, err := s.groupBuyRepo.ClaimExpired(ctx, tx, time.Now())
+			if err != nil {
+				if errors.Is(err, storage.ErrGroupBuyNotFound) {
+					return nil
+				}
+				return err
+			}
+			claimed = true
+			return s.expireTx(ctx, tx, groupBuy)
+		})
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return nil
+		}
+	}
+}
+
+func (s *GroupBuyService) completeTx(ctx context.Context, tx *sql.Tx, groupBuy *domain.GroupBuy) error {
+	// правила проверялись при открытии, но за время сбора создатель мог, например, исчерпать лимит покупок.
+	// Тогда покупка закрывается как несобранная: взносы освобождаются
+	item := &domain.Merch{ID: groupBuy.MerchID, Name: groupBuy.Item}
+	if err := s.merchService.CheckRulesTx(ctx, tx, groupBuy.CreatorID, item, groupBuy.Quantity); err != nil {
+		if !isRuleViolation(err) {
+			return err
+		}
+		log.Printf("group buy %d: creator %d can no longer buy the item: %v", groupBuy.ID, groupBuy.CreatorID, err)
+		return s.expireTx(ctx, tx, groupBuy)
+	}
+
+	pledges, err := s.groupBuyRepo.GetPledges(ctx, tx, groupBuy.ID)
+	if err != nil {
+		return err
+	}
+	// холды списываются по возрастанию id пользователя: списание блокирует строку пользователя
+	sort.SliceStable(pledges, func(i, j int) bool { return pledges[i].UserID < pledges[j].UserID })
+
+	// холд взноса могли освободить или он истек после того, как сумма была посчитана. Такой взнос не входит
+	// в собранное, и если без него цель не набрана, сбор остается открытым
+	var active []*domain.GroupBuyPledge
+	pledged := 0
+	for _, p := range pledges {
+		if _, err := s.holdService.LockActiveTx(ctx, tx, p.HoldID); err != nil {
+			if errors.Is(err, ErrHoldNotActive) {
+				continue
+			}
+			return fmt.Errorf("failed to lock pledge hold: %w", err)
+		}
+		active = append(active, p)
+		pledged += p.Amount
+	}
+	if pledged < groupBuy.Target {
+		groupBuy.Pledged = pledged
+		return nil
+	}
+
+	// штуки резервируются только сейчас. Если товар за время сбора раскупили, сбор закрывается как несобранный
+	if _, err := s.merchRepo.DecrementStock(ctx, tx, groupBuy.MerchID, groupBuy.Quantity); err != nil {
+		if !errors.Is(err, storage.ErrMerchOutOfStock) {
+			return fmt.Errorf("failed to decrement stock: %w", err)
+		}
+		log.Printf("group buy %d: %s is out of stock", groupBuy.ID, groupBuy.Item)
+		return s.expireTx(ctx, tx, groupBuy)
+	}
+
+	captured := 0
+	for _, p := range active {
+		if _, err := s.holdService.CaptureTx(ctx, tx, p.HoldID, p.Amount); err != nil {
+			return fmt.Errorf("failed to capture pledge: %w", err)
+		}
+		captured += p.Amount
+	}
+	if captured != groupBuy.Target {
+		return fmt.Errorf("group buy %d: captured %d of %d", groupBuy.ID, captured, groupBuy.Target)
+	}
+
+	now := time.Now()
+	order := &domain.Order{UserID: groupBuy.CreatorID, CreatedAt: now}
+	if err := s.orderRepo.Create(ctx, tx, order); err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+	if err := s.purchaseRepo.Create(ctx, tx, &domain.Purchase{
+		UserID:       groupBuy.CreatorID,
+		OrderID:      order.ID,
+		MerchID:      groupBuy.MerchID,
+		Item:         groupBuy.Item,
+		Quantity:     groupBuy.Quantity,
+		UnitPrice:    groupBuy.Target / groupBuy.Quantity,
+		Price:        groupBuy.Target,
+		Currency:     groupBuy.Currency,
+		PurchaseDate: now,
+		GroupBuyID:   groupBuy.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to create purchase: %w", err)
+	}
+
+	groupBuy.Status = domain.GroupBuyStatusCompleted
+	groupBuy.OrderID = order.ID
+	groupBuy.ClosedAt = &now
+	if err := s.groupBuyRepo.Update(ctx, tx, groupBuy); err != nil {
+		return err
+	}
+	return s.notifyParticipantsTx(ctx, tx, groupBuy, pledges, domain.NotificationGroupBuyCompleted,
+		fmt.Sprintf("Group buy of %s reached its target", groupBuy.Item))
+}
+
+func (s *GroupBuyService) expireTx(ctx context.Context, tx *sql.Tx, groupBuy *domain.GroupBuy) error {
+	pledges, err := s.groupBuyRepo.GetPledges(ctx, tx, groupBuy.ID)
+	if err != nil {
+		return err
+	}
+	for _, p := range pledges {
+		if _, err := s.holdService.ReleaseTx(ctx, tx, p.HoldID); err != nil && !errors.Is(err, ErrHoldNotActive) {
+			return fmt.Errorf("failed to release pledge: %w", err)
+		}
+	}
+	now := time.Now()
+	groupBuy.Status = domain.GroupBuyStatusExpired
+	groupBuy.ClosedAt = &now
+	if err := s.groupBuyRepo.Update(ctx, tx, groupBuy); err != nil {
+		return err
+	}
+	return s.notifyParticipantsTx(ctx, tx, groupBuy, pledges, domain.NotificationGroupBuyExpired,
+		fmt.Sprintf("Group buy of %s did not reach its target, pledges are released", groupBuy.Item))
+}
+
+// notifyParticipantsTx уведомляет создателя и каждого участника один раз.
+func (s *GroupBuyService) notifyParticipantsTx(ctx context.Context, tx *sql.Tx, groupBuy *domain.GroupBuy,
+	pledges []*domain.GroupBuyPledge, kind, message string) error {
+	data := map[string]string{
+		"groupBuyId": strconv.FormatInt(groupBuy.ID, 10),
+		"item":       groupBuy.Item,
+	}
+	if groupBuy.OrderID != 0 {
+		data["orderId"] = strconv.FormatInt(groupBuy.OrderID, 10)
+	}
+
+	notified := map[int64]bool{groupBuy.CreatorID: true}
+	recipients := []int64{groupBuy.CreatorID}
+	for _, p := range pledges {
+		if !notified[p.UserID] {
+			notified[p.UserID] = true
+			recipients = append(recipients, p.UserID)
+		}
+	}
+	for _, userID := range recipients {
+		if err := s.notifyRepo.Create(ctx, tx, &domain.Notification{
+			UserID:  userID,
+			Kind:    kind,
+			Message: message,
+			Data:    data,
+		}); err != nil {
+			return fmt.Errorf("failed to create group buy notification: %w", err)
+		}
+	}
+	return nil
+}
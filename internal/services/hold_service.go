@@ -0,0 +1,228 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrHoldNotActive         = errors.New("hold is not active")
+	ErrInvalidCaptureAmount  = errors.New("capture amount must be positive and not exceed the hold")
+	ErrInvalidHoldExpiration = errors.New("hold expiration must be positive")
+	ErrInsufficientAvailable = errors.New("insufficient available balance")
+)
+
+type HoldService struct {
+	holdRepo   storage.HoldRepository
+	userRepo   storage.UserRepository
+	walletRepo storage.WalletRepository
+	db         *sql.DB
+	defaultTTL time.Duration
+}
+
+func NewHoldService(
+	holdRepo storage.HoldRepository,
+	userRepo storage.UserRepository,
+	walletRepo storage.WalletRepository,
+	db *sql.DB,
+	defaultTTL time.Duration,
+) *HoldService {
+	return &HoldService{
+		holdRepo:   holdRepo,
+		userRepo:   userRepo,
+		walletRepo: walletRepo,
+		db:         db,
+		defaultTTL: defaultTTL,
+	}
+}
+
+func (s *HoldService) PlaceHold(ctx context.Context, userID int64, currency string, amount int, reason string, ttl time.Duration) (*domain.Hold, error) {
+	var hold *domain.Hold
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		hold, err = s.PlaceHoldTx(ctx, tx, userID, currency, amount, reason, ttl)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// PlaceHoldTx резервирует сумму в рамках чужой транзакции, ttl == 0 означает срок по умолчанию.
+func (s *HoldService) PlaceHoldTx(ctx context.Context, tx storage.Tx, userID int64, currency string, amount int, reason string, ttl time.Duration) (*domain.Hold, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if ttl < 0 {
+		return nil, ErrInvalidHoldExpiration
+	}
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if domain.IsPrimaryCurrency(currency) {
+		currency = domain.PrimaryCurrency
+	} else {
+		exists, err := s.walletRepo.CurrencyExists(ctx, currency)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrUnknownCurrency
+		}
+	}
+
+	available, err := s.lockAvailable(ctx, tx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if available < amount {
+		return nil, ErrInsufficientAvailable
+	}
+
+	now := time.Now()
+	hold := &domain.Hold{
+		UserID:    userID,
+		Currency:  currency,
+		Amount:    amount,
+		Status:    domain.HoldStatusActive,
+		Reason:    reason,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.holdRepo.Create(ctx, tx, hold); err != nil {
+		return nil, fmt.Errorf("failed to create hold: %w", err)
+	}
+	return hold, nil
+}
+
+func (s *HoldService) Capture(ctx context.Context, holdID int64, amount int) (*domain.Hold, error) {
+	var hold *domain.Hold
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		hold, err = s.CaptureTx(ctx, tx, holdID, amount)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// CaptureTx списывает amount из холда, остаток резерва освобождается.
+func (s *HoldService) CaptureTx(ctx context.Context, tx storage.Tx, holdID int64, amount int) (*domain.Hold, error) {
+	hold, err := s.holdRepo.FindByIDForUpdate(ctx, tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !hold.IsActive(now) {
+		return nil, ErrHoldNotActive
+	}
+	if amount <= 0 || amount > hold.Amount {
+		return nil, ErrInvalidCaptureAmount
+	}
+
+	if err := s.debit(ctx, tx, hold.UserID, hold.Currency, amount); err != nil {
+		return nil, err
+	}
+
+	hold.CapturedAmount = amount
+	hold.Status = domain.HoldStatusCaptured
+	hold.CapturedAt = &now
+	if err := s.holdRepo.Update(ctx, tx, hold); err != nil {
+		return nil, fmt.Errorf("failed to update hold: %w", err)
+	}
+	return hold, nil
+}
+
+func (s *HoldService) Release(ctx context.Context, holdID int64) (*domain.Hold, error) {
+	var hold *domain.Hold
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		hold, err = s.ReleaseTx(ctx, tx, holdID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (s *HoldService) ReleaseTx(ctx context.Context, tx storage.Tx, holdID int64) (*domain.Hold, error) {
+	hold, err := s.holdRepo.FindByIDForUpdate(ctx, tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != domain.HoldStatusActive {
+		return nil, ErrHoldNotActive
+	}
+
+	hold.Status = domain.HoldStatusReleased
+	if err := s.holdRepo.Update(ctx, tx, hold); err != nil {
+		return nil, fmt.Errorf("failed to update hold: %w", err)
+	}
+	return hold, nil
+}
+
+// LockActiveTx блокирует холд до конца транзакции и проверяет, что он еще действует.
+func (s *HoldService) LockActiveTx(ctx context.Context, tx storage.Tx, holdID int64) (*domain.Hold, error) {
+	hold, err := s.holdRepo.FindByIDForUpdate(ctx, tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if !hold.IsActive(time.Now()) {
+		return nil, ErrHoldNotActive
+	}
+	return hold, nil
+}
+
+func (s *HoldService) GetActiveHolds(ctx context.Context, userID int64) ([]*domain.Hold, error) {
+	return s.holdRepo.GetActiveByUser(ctx, userID)
+}
+
+// ExpireHolds переводит просроченные холды в expired. На доступный баланс они
+// перестают влиять сразу по истечении срока, задача только наводит порядок в статусах.
+func (s *HoldService) ExpireHolds(ctx context.Context) error {
+	_, err := s.holdRepo.ExpireDue(ctx, time.Now())
+	return err
+}
+
+func (s *HoldService) lockAvailable(ctx context.Context, tx storage.Tx, userID int64, currency string) (int, error) {
+	if domain.IsPrimaryCurrency(currency) {
+		user, err := s.userRepo.FindByIDForUpdate(ctx, tx, userID)
+		if err != nil {
+			return 0, err
+		}
+		return user.AvailableCoins(), nil
+	}
+
+	wallet, err := s.walletRepo.FindForUpdate(ctx, tx, userID, currency)
+	if err != nil {
+		return 0, err
+	}
+	return wallet.Available(), nil
+}
+
+func (s *HoldService) debit(ctx context.Context, tx storage.Tx, userID int64, currency string, amount int) error {
+	if domain.IsPrimaryCurrency(currency) {
+		user, err := s.userRepo.FindByIDForUpdate(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		user.Coins -= amount
+		return s.userRepo.Update(ctx, tx, user)
+	}
+
+	wallet, err := s.walletRepo.FindForUpdate(ctx, tx, userID, currency)
+	if err != nil {
+		return err
+	}
+	wallet.Balance -= amount
+	return s.walletRepo.Update(ctx, tx, wallet)
+}
@@ -0,0 +1,874 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidMerchName     = errors.New("merch name must be 1-50 lowercase latin letters, digits or dashes")
+	ErrInvalidMerchPrice    = errors.New("merch price must be positive")
+	ErrMerchRetired         = errors.New("merch is retired")
+	ErrInvalidMerchStock    = errors.New("merch stock must not be negative")
+	ErrInvalidEffectiveDate = errors.New("scheduled price must take effect in the future")
+	ErrInvalidMerchCategory = errors.New("merch category must be at most 50 lowercase latin letters, digits or dashes")
+	ErrInvalidMerchTags     = errors.New("merch tags must be up to 10 lowercase latin letters, digits or dashes")
+	ErrInvalidDescription   = errors.New("merch description is too long")
+	ErrInvalidAttributes    = errors.New("merch attributes must be up to 20 non-empty keys of at most 50 characters with values of at most 200")
+	ErrUnsupportedImage     = errors.New("image must be jpeg, png or webp")
+	ErrInvalidVariant       = errors.New("variant needs a sku of 1-64 latin letters, digits, dashes or underscores and a size or color")
+	ErrInvalidVariantPrice  = errors.New("variant price must stay positive")
+	ErrInvalidPurchaseRule  = errors.New("invalid purchase rule")
+	ErrInvalidDrop          = errors.New("drop cap must be positive and the sales window must end after it starts")
+	ErrInvalidBundle        = errors.New("bundle needs 1-10 distinct items with positive quantities")
+	ErrBundleUnsupported    = errors.New("not supported for bundles")
+	ErrVariantsUnsupported  = errors.New("not supported for items with variants")
+)
+
+var variantSKUPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+type VariantUpdate struct {
+	PriceDelta *int
+	Stock      *int
+	Active     *bool
+}
+
+const (
+	maxMerchTags        = 10
+	maxDescriptionRunes = 2000
+	maxMerchAttributes  = 20
+	maxAttributeKey     = 50
+	maxAttributeValue   = 200
+	maxBundleComponents = 10
+)
+
+// расширение задает тип при раздаче файла из хранилища
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// название попадает в purchases.item VARCHAR(50) и в путь /api/buy/:item
+var merchNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+// категории задаются в том же формате, пустая строка снимает категорию
+var merchCategoryPattern = regexp.MustCompile(`^([a-z0-9][a-z0-9-]{0,49})?$`)
+
+type BundleComponentInput struct {
+	Item     string
+	Quantity int
+}
+
+type MerchUpdate struct {
+	Price       *int
+	Currency    *string
+	Category    *string
+	Description *string
+	Tags        *[]string
+	Attributes  *map[string]string
+	Stock       *int
+}
+
+type MerchAdminService struct {
+	merchRepo   storage.MerchRepository
+	auditRepo   storage.MerchAuditRepository
+	priceRepo   storage.MerchPriceRepository
+	walletRepo  storage.WalletRepository
+	imageRepo   storage.MerchImageRepository
+	variantRepo storage.MerchVariantRepository
+	ruleRepo    storage.MerchRuleRepository
+	dropRepo    storage.MerchDropRepository
+	bundleRepo  storage.MerchBundleRepository
+	blobs       storage.BlobStore
+	db          *sql.DB
+}
+
+func NewMerchAdminService(
+	merchRepo storage.MerchRepository,
+	auditRepo storage.MerchAuditRepository,
+	priceRepo storage.MerchPriceRepository,
+	walletRepo storage.WalletRepository,
+	imageRepo storage.MerchImageRepository,
+	variantRepo storage.MerchVariantRepository,
+	ruleRepo storage.MerchRuleRepository,
+	dropRepo storage.MerchDropRepository,
+	bundleRepo storage.MerchBundleRepository,
+	blobs storage.BlobStore,
+	db *sql.DB,
+) *MerchAdminService {
+	return &MerchAdminService{
+		merchRepo:   merchRepo,
+		auditRepo:   auditRepo,
+		priceRepo:   priceRepo,
+		walletRepo:  walletRepo,
+		imageRepo:   imageRepo,
+		variantRepo: variantRepo,
+		ruleRepo:    ruleRepo,
+		dropRepo:    dropRepo,
+		bundleRepo:  bundleRepo,
+		blobs:       blobs,
+		db:          db,
+	}
+}
+
+func (s *MerchAdminService) ListItems(ctx context.Context) ([]*domain.Merch, error) {
+	return s.merchRepo.GetAll(ctx)
+}
+
+func (s *MerchAdminService) GetAudit(ctx context.Context, merchID int) ([]*domain.MerchAudit, error) {
+	if _, err := s.merchRepo.FindByID(ctx, merchID); err != nil {
+		return nil, err
+	}
+	return s.auditRepo.GetByMerch(ctx, merchID)
+}
+
+// CreateItem добавляет товар в каталог, stock == nil - без учета остатков.
+func (s *MerchAdminService) CreateItem(ctx context.Context, adminID int64, name string, price int, currency string, stock *int) (*domain.Merch, error) {
+	if !merchNamePattern.MatchString(name) {
+		return nil, ErrInvalidMerchName
+	}
+	if price <= 0 {
+		return nil, ErrInvalidMerchPrice
+	}
+	if stock != nil && *stock < 0 {
+		return nil, ErrInvalidMerchStock
+	}
+	currency, err := s.validateCurrency(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &domain.Merch{Name: name, Price: price, Currency: currency, Stock: stock}
+	err = runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.merchRepo.Create(ctx, tx, item); err != nil {
+			return err
+		}
+		if err := s.audit(ctx, tx, adminID, item.ID, domain.MerchAuditCreate, "price", "", strconv.Itoa(price)); err != nil {
+			return err
+		}
+		if err := s.recordPrice(ctx, tx, adminID, item.ID, price, item.CreatedAt); err != nil {
+			return err
+		}
+		if stock == nil {
+			return nil
+		}
+		return s.audit(ctx, tx, adminID, item.ID, domain.MerchAuditCreate, "stock", "", strconv.Itoa(*stock))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// CreateBundle добавляет набор из существующих товаров. Своего остатка у набора нет: он считается по
+// составляющим, при покупке списываются их остатки. Состав после создания не меняется.
+func (s *MerchAdminService) CreateBundle(ctx context.Context, adminID int64, name string, price int, currency string, inputs []BundleComponentInput) (*domain.Merch, error) {
+	if !merchNamePattern.MatchString(name) {
+		return nil, ErrInvalidMerchName
+	}
+	if price <= 0 {
+		return nil, ErrInvalidMerchPrice
+	}
+	if len(inputs) == 0 || len(inputs) > maxBundleComponents {
+		return nil, ErrInvalidBundle
+	}
+	seen := make(map[string]bool)
+	for _, in := range inputs {
+		if in.Quantity <= 0 || seen[in.Item] {
+			return nil, ErrInvalidBundle
+		}
+		seen[in.Item] = true
+	}
+	currency, err := s.validateCurrency(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &domain.Merch{Name: name, Price: price, Currency: currency, Bundle: true}
+	for _, in := range inputs {
+		component, err := s.merchRepo.FindByName(ctx, in.Item)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, in.Item)
+		}
+		if component.Bundle {
+			return nil, fmt.Errorf("%w: %s is a bundle itself", ErrInvalidBundle, component.Name)
+		}
+		// у набора нет выбора варианта, а остатки вариантов учитываются отдельно от товара
+		variants, err := s.variantRepo.GetByMerch(ctx, component.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load variants: %w", err)
+		}
+		for _, v := range variants {
+			if v.Active {
+				return nil, fmt.Errorf("%w: %s has variants", ErrInvalidBundle, component.Name)
+			}
+		}
+		item.Components = append(item.Components, &domain.BundleComponent{Item: component, Quantity: in.Quantity})
+	}
+	sort.Slice(item.Components, func(i, j int) bool {
+		return item.Components[i].Item.ID < item.Components[j].Item.ID
+	})
+
+	err = runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.merchRepo.Create(ctx, tx, item); err != nil {
+			return err
+		}
+		for _, c := range item.Components {
+			c.BundleID = item.ID
+			if err := s.bundleRepo.AddComponent(ctx, tx, c); err != nil {
+				return err
+			}
+		}
+		if err := s.audit(ctx, tx, adminID, item.ID, domain.MerchAuditCreate, "price", "", strconv.Itoa(price)); err != nil {
+			return err
+		}
+		if err := s.recordPrice(ctx, tx, adminID, item.ID, price, item.CreatedAt); err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, adminID, item.ID, domain.MerchAuditCreate, "components", "", formatComponents(item.Components))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *MerchAdminService) UpdateItem(ctx context.Context, adminID int64, merchID int, update MerchUpdate) (*domain.Merch, error) {
+	if update.Price != nil && *update.Price <= 0 {
+		return nil, ErrInvalidMerchPrice
+	}
+	if update.Stock != nil && *update.Stock < 0 {
+		return nil, ErrInvalidMerchStock
+	}
+	if update.Category != nil && !merchCategoryPattern.MatchString(*update.Category) {
+		return nil, ErrInvalidMerchCategory
+	}
+	if update.Description != nil && utf8.RuneCountInString(*update.Description) > maxDescriptionRunes {
+		return nil, ErrInvalidDescription
+	}
+	if update.Attributes != nil && !validAttributes(*update.Attributes) {
+		return nil, ErrInvalidAttributes
+	}
+	if update.Tags != nil {
+		tags, err := normalizeTags(*update.Tags)
+		if err != nil {
+			return nil, err
+		}
+		update.Tags = &tags
+	}
+	if update.Currency != nil {
+		currency, err := s.validateCurrency(ctx, *update.Currency)
+		if err != nil {
+			return nil, err
+		}
+		update.Currency = &currency
+	}
+
+	var item *domain.Merch
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		item, err = s.lockActive(ctx, tx, merchID)
+		if err != nil {
+			return err
+		}
+
+		if update.Price != nil && *update.Price != item.Price {
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "price",
+				strconv.Itoa(item.Price), strconv.Itoa(*update.Price)); err != nil {
+				return err
+			}
+			if err := s.recordPrice(ctx, tx, adminID, merchID, *update.Price, time.Now()); err != nil {
+				return err
+			}
+			item.Price = *update.Price
+		}
+		if update.Currency != nil && *update.Currency != item.Currency {
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "currency",
+				item.Currency, *update.Currency); err != nil {
+				return err
+			}
+			item.Currency = *update.Currency
+		}
+		if update.Category != nil && *update.Category != item.Category {
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "category",
+				item.Category, *update.Category); err != nil {
+				return err
+			}
+			item.Category = *update.Category
+		}
+		if update.Description != nil && *update.Description != item.Description {
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "description",
+				item.Description, *update.Description); err != nil {
+				return err
+			}
+			item.Description = *update.Description
+		}
+		if update.Tags != nil && strings.Join(*update.Tags, ",") != strings.Join(item.Tags, ",") {
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "tags",
+				strings.Join(item.Tags, ","), strings.Join(*update.Tags, ",")); err != nil {
+				return err
+			}
+			item.Tags = *update.Tags
+		}
+		if update.Attributes != nil && !maps.Equal(*update.Attributes, item.Attributes) {
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "attributes",
+				formatAttributes(item.Attributes), formatAttributes(*update.Attributes)); err != nil {
+				return err
+			}
+			item.Attributes = *update.Attributes
+		}
+		if update.Stock != nil && item.Bundle {
+			return fmt.Errorf("%w: bundle stock is derived from its items", ErrBundleUnsupported)
+		}
+		if update.Stock != nil && (item.Stock == nil || *update.Stock != *item.Stock) {
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "stock",
+				formatStock(item.Stock), strconv.Itoa(*update.Stock)); err != nil {
+				return err
+			}
+			stock := *update.Stock
+			item.Stock = &stock
+		}
+
+		return s.merchRepo.Update(ctx, tx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *MerchAdminService) RenameItem(ctx context.Context, adminID int64, merchID int, name string) (*domain.Merch, error) {
+	if !merchNamePattern.MatchString(name) {
+		return nil, ErrInvalidMerchName
+	}
+
+	var item *domain.Merch
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		item, err = s.lockActive(ctx, tx, merchID)
+		if err != nil {
+			return err
+		}
+		if item.Name == name {
+			return nil
+		}
+
+		if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditRename, "name", item.Name, name); err != nil {
+			return err
+		}
+		item.Name = name
+		return s.merchRepo.Update(ctx, tx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// RetireItem снимает товар с продажи, строка остается, чтобы на нее ссылались старые покупки.
+func (s *MerchAdminService) RetireItem(ctx context.Context, adminID int64, merchID int) (*domain.Merch, error) {
+	var item *domain.Merch
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		item, err = s.lockActive(ctx, tx, merchID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		item.Active = false
+		item.RetiredAt = &now
+		if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditRetire, "active", "true", "false"); err != nil {
+			return err
+		}
+		return s.merchRepo.Update(ctx, tx, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// SchedulePriceChange планирует новую цену с даты effectiveFrom, до нее действует текущая.
+func (s *MerchAdminService) SchedulePriceChange(ctx context.Context, adminID int64, merchID int, price int, effectiveFrom time.Time) (*domain.MerchPrice, error) {
+	if price <= 0 {
+		return nil, ErrInvalidMerchPrice
+	}
+	if !effectiveFrom.After(time.Now()) {
+		return nil, ErrInvalidEffectiveDate
+	}
+
+	scheduled := &domain.MerchPrice{MerchID: merchID, Price: price, EffectiveFrom: effectiveFrom, CreatedBy: adminID}
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := s.lockActive(ctx, tx, merchID); err != nil {
+			return err
+		}
+		if err := s.priceRepo.Create(ctx, tx, scheduled); err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, adminID, merchID, domain.MerchAuditSchedulePrice, "price", "",
+			fmt.Sprintf("%d from %s", price, effectiveFrom.UTC().Format(time.RFC3339)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// GetPriceHistory возвращает все цены товара, включая запланированные.
+func (s *MerchAdminService) GetPriceHistory(ctx context.Context, merchID int) ([]*domain.MerchPrice, error) {
+	if _, err := s.merchRepo.FindByID(ctx, merchID); err != nil {
+		return nil, err
+	}
+	return s.priceRepo.GetByMerch(ctx, merchID)
+}
+
+func (s *MerchAdminService) recordPrice(ctx context.Context, tx storage.Tx, adminID int64, merchID int, price int, effectiveFrom time.Time) error {
+	if err := s.priceRepo.Create(ctx, tx, &domain.MerchPrice{
+		MerchID:       merchID,
+		Price:         price,
+		EffectiveFrom: effectiveFrom,
+		CreatedBy:     adminID,
+	}); err != nil {
+		return fmt.Errorf("failed to record price: %w", err)
+	}
+	return nil
+}
+
+func (s *MerchAdminService) lockActive(ctx context.Context, tx storage.Tx, merchID int) (*domain.Merch, error) {
+	item, err := s.merchRepo.FindByIDForUpdate(ctx, tx, merchID)
+	if err != nil {
+		return nil, err
+	}
+	if !item.Active {
+		return nil, ErrMerchRetired
+	}
+	return item, nil
+}
+
+func (s *MerchAdminService) validateCurrency(ctx context.Context, currency string) (string, error) {
+	if domain.IsPrimaryCurrency(currency) {
+		return domain.PrimaryCurrency, nil
+	}
+	exists, err := s.walletRepo.CurrencyExists(ctx, currency)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", ErrUnknownCurrency
+	}
+	return currency, nil
+}
+
+func (s *MerchAdminService) audit(ctx context.Context, tx storage.Tx, adminID int64, merchID int, action, field, oldValue, newValue string) error {
+	if err := s.auditRepo.Create(ctx, tx, &domain.MerchAudit{
+		MerchID:  merchID,
+		AdminID:  adminID,
+		Action:   action,
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+	}); err != nil {
+		return fmt.Errorf("failed to write merch audit: %w", err)
+	}
+	return nil
+}
+
+func (s *MerchAdminService) ListVariants(ctx context.Context, merchID int) ([]*domain.MerchVariant, error) {
+	if _, err := s.merchRepo.FindByID(ctx, merchID); err != nil {
+		return nil, err
+	}
+	return s.variantRepo.GetByMerch(ctx, merchID)
+}
+
+// CreateVariant добавляет вариант товара. После этого товар можно купить только с указанием варианта.
+func (s *MerchAdminService) CreateVariant(ctx context.Context, adminID int64, merchID int, variant *domain.MerchVariant) (*domain.MerchVariant, error) {
+	variant.Size = strings.TrimSpace(variant.Size)
+	variant.Color = strings.ToLower(strings.TrimSpace(variant.Color))
+	if !variantSKUPattern.MatchString(variant.SKU) || variant.Size == "" && variant.Color == "" ||
+		len(variant.Size) > 16 || len(variant.Color) > 32 {
+		return nil, ErrInvalidVariant
+	}
+	if variant.Stock != nil && *variant.Stock < 0 {
+		return nil, ErrInvalidMerchStock
+	}
+
+	variant.MerchID = merchID
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		item, err := s.lockActive(ctx, tx, merchID)
+		if err != nil {
+			return err
+		}
+		if item.Bundle {
+			return fmt.Errorf("%w: variants", ErrBundleUnsupported)
+		}
+		if item.Price+variant.PriceDelta <= 0 {
+			return ErrInvalidVariantPrice
+		}
+		if err := s.requireNotBundled(ctx, tx, item); err != nil {
+			return err
+		}
+		if err := s.variantRepo.Create(ctx, tx, variant); err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, adminID, merchID, domain.MerchAuditCreate, "variant", "",
+			fmt.Sprintf("%s size=%s color=%s delta=%d stock=%s", variant.SKU, variant.Size, variant.Color,
+				variant.PriceDelta, formatStock(variant.Stock)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (s *MerchAdminService) UpdateVariant(ctx context.Context, adminID int64, merchID, variantID int, update VariantUpdate) (*domain.MerchVariant, error) {
+	if update.Stock != nil && *update.Stock < 0 {
+		return nil, ErrInvalidMerchStock
+	}
+
+	var variant *domain.MerchVariant
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		item, err := s.lockActive(ctx, tx, merchID)
+		if err != nil {
+			return err
+		}
+		variant, err = s.variantRepo.FindByIDForUpdate(ctx, tx, variantID)
+		if err != nil {
+			return err
+		}
+		if variant.MerchID != merchID {
+			return storage.ErrVariantNotFound
+		}
+
+		field := "variant:" + variant.SKU
+		if update.PriceDelta != nil && *update.PriceDelta != variant.PriceDelta {
+			if item.Price+*update.PriceDelta <= 0 {
+				return ErrInvalidVariantPrice
+			}
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, field+":price_delta",
+				strconv.Itoa(variant.PriceDelta), strconv.Itoa(*update.PriceDelta)); err != nil {
+				return err
+			}
+			variant.PriceDelta = *update.PriceDelta
+		}
+		if update.Stock != nil && (variant.Stock == nil || *update.Stock != *variant.Stock) {
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, field+":stock",
+				formatStock(variant.Stock), strconv.Itoa(*update.Stock)); err != nil {
+				return err
+			}
+			stock := *update.Stock
+			variant.Stock = &stock
+		}
+		if update.Active != nil && *update.Active != variant.Active {
+			if *update.Active {
+				if err := s.requireNotBundled(ctx, tx, item); err != nil {
+					return err
+				}
+			}
+			if err := s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, field+":active",
+				strconv.FormatBool(variant.Active), strconv.FormatBool(*update.Active)); err != nil {
+				return err
+			}
+			variant.Active = *update.Active
+		}
+		return s.variantRepo.Update(ctx, tx, variant)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+// requireNotBundled не дает завести варианты у составляющей набора: набор продается без выбора варианта,
+// и остаток варианта при его продаже не списывался бы.
+func (s *MerchAdminService) requireNotBundled(ctx context.Context, tx *sql.Tx, item *domain.Merch) error {
+	bundles, err := s.bundleRepo.GetBundleNames(ctx, tx, item.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load bundles: %w", err)
+	}
+	if len(bundles) > 0 {
+		return fmt.Errorf("%w: variants of %s, it is part of %s", ErrBundleUnsupported, item.Name,
+			strings.Join(bundles, ", "))
+	}
+	return nil
+}
+
+// AddImage сохраняет картинку в хранилище и привязывает к товару.
+// Файл пишется до транзакции, чтобы не держать блокировку товара во время загрузки; при ошибке он удаляется.
+func (s *MerchAdminService) AddImage(ctx context.Context, adminID int64, merchID int, contentType string, r io.Reader) (*domain.MerchImage, error) {
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+	if _, err := s.merchRepo.FindByID(ctx, merchID); err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate image key: %w", err)
+	}
+	image := &domain.MerchImage{
+		MerchID:     merchID,
+		Key:         fmt.Sprintf("merch-%d-%s%s", merchID, hex.EncodeToString(suffix), ext),
+		ContentType: contentType,
+	}
+
+	size, err := s.blobs.Put(ctx, image.Key, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store image: %w", err)
+	}
+	image.Size = size
+
+	err = runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := s.lockActive(ctx, tx, merchID); err != nil {
+			return err
+		}
+		if err := s.imageRepo.Create(ctx, tx, image); err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "image", "", image.Key)
+	})
+	if err != nil {
+		if delErr := s.blobs.Delete(ctx, image.Key); delErr != nil {
+			log.Printf("failed to delete orphan image %s: %v", image.Key, delErr)
+		}
+		return nil, err
+	}
+
+	image.URL = s.blobs.URL(image.Key)
+	return image, nil
+}
+
+// DeleteImage отвязывает картинку от товара и удаляет файл после коммита.
+func (s *MerchAdminService) DeleteImage(ctx context.Context, adminID int64, merchID int, imageID int64) error {
+	var image *domain.MerchImage
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := s.merchRepo.FindByIDForUpdate(ctx, tx, merchID); err != nil {
+			return err
+		}
+		var err error
+		image, err = s.imageRepo.Delete(ctx, tx, merchID, imageID)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "image", image.Key, "")
+	})
+	if err != nil {
+		return err
+	}
+
+	// запись уже удалена: осиротевший файл не виден клиентам, поэтому ошибку только логируем
+	if err := s.blobs.Delete(ctx, image.Key); err != nil {
+		log.Printf("failed to delete image %s: %v", image.Key, err)
+	}
+	return nil
+}
+
+func (s *MerchAdminService) GetPurchaseRule(ctx context.Context, merchID int) (*domain.MerchPurchaseRule, error) {
+	if _, err := s.merchRepo.FindByID(ctx, merchID); err != nil {
+		return nil, err
+	}
+	return s.ruleRepo.Get(ctx, merchID)
+}
+
+// SetPurchaseRule заменяет ограничения на покупку товара целиком.
+func (s *MerchAdminService) SetPurchaseRule(ctx context.Context, adminID int64, merchID int, rule *domain.MerchPurchaseRule) (*domain.MerchPurchaseRule, error) {
+	if err := normalizePurchaseRule(rule); err != nil {
+		return nil, err
+	}
+
+	rule.MerchID = merchID
+	rule.UpdatedBy = adminID
+	rule.UpdatedAt = time.Now()
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := s.lockActive(ctx, tx, merchID); err != nil {
+			return err
+		}
+		old := ""
+		if current, err := s.ruleRepo.Get(ctx, merchID); err == nil {
+			old = current.String()
+		} else if !errors.Is(err, storage.ErrMerchRuleNotFound) {
+			return err
+		}
+		if err := s.ruleRepo.Upsert(ctx, tx, rule); err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "purchase_rule", old, rule.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *MerchAdminService) DeletePurchaseRule(ctx context.Context, adminID int64, merchID int) error {
+	return runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := s.merchRepo.FindByIDForUpdate(ctx, tx, merchID); err != nil {
+			return err
+		}
+		current, err := s.ruleRepo.Get(ctx, merchID)
+		if err != nil {
+			return err
+		}
+		if err := s.ruleRepo.Delete(ctx, tx, merchID); err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, adminID, merchID, domain.MerchAuditUpdate, "purchase_rule", current.String(), "")
+	})
+}
+
+// SetDrop задает окно продаж и тираж товара. При смене тиража уже проданное вычитается из нового.
+func (s *MerchAdminService) SetDrop(ctx context.Context, adminID int64, merchID int, drop *domain.MerchDrop) (*domain.Merch, error) {
+	if drop.Cap != nil && *drop.Cap <= 0 {
+		return nil, ErrInvalidDrop
+	}
+	if drop.AvailableFrom != nil && drop.AvailableUntil != nil && !drop.AvailableUntil.After(*drop.AvailableFrom) {
+		return nil, ErrInvalidDrop
+	}
+	drop.MerchID = merchID
+	if err := s.setDrop(ctx, adminID, drop); err != nil {
+		return nil, err
+	}
+	return s.merchRepo.FindByID(ctx, merchID)
+}
+
+// ClearDrop снимает окно продаж и тираж.
+func (s *MerchAdminService) ClearDrop(ctx context.Context, adminID int64, merchID int) error {
+	return s.setDrop(ctx, adminID, &domain.MerchDrop{MerchID: merchID})
+}
+
+func (s *MerchAdminService) setDrop(ctx context.Context, adminID int64, drop *domain.MerchDrop) error {
+	return runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		item, err := s.lockActive(ctx, tx, drop.MerchID)
+		if err != nil {
+			return err
+		}
+		if err := s.dropRepo.Set(ctx, tx, drop); err != nil {
+			return err
+		}
+		return s.audit(ctx, tx, adminID, drop.MerchID, domain.MerchAuditUpdate, "drop", item.Drop().String(), drop.String())
+	})
+}
+
+func validAttributes(attributes map[string]string) bool {
+	if len(attributes) > maxMerchAttributes {
+		return false
+	}
+	for key, value := range attributes {
+		if strings.TrimSpace(key) == "" || utf8.RuneCountInString(key) > maxAttributeKey ||
+			utf8.RuneCountInString(value) > maxAttributeValue {
+			return false
+		}
+	}
+	return true
+}
+
+func formatAttributes(attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + attributes[key]
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// normalizeTags приводит теги к нижнему регистру, убирает повторы и сортирует, чтобы аудит не фиксировал перестановки.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !merchNamePattern.MatchString(tag) {
+			return nil, ErrInvalidMerchTags
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxMerchTags {
+		return nil, ErrInvalidMerchTags
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func normalizePurchaseRule(rule *domain.MerchPurchaseRule) error {
+	if rule.MaxQuantity < 0 || rule.PeriodDays < 0 || rule.MinAccountAgeDays < 0 {
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidPurchaseRule)
+	}
+	if rule.PeriodDays > 0 && rule.MaxQuantity == 0 {
+		return fmt.Errorf("%w: period requires max quantity", ErrInvalidPurchaseRule)
+	}
+
+	roles := make([]string, 0, len(rule.AllowedRoles))
+	for _, role := range rule.AllowedRoles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role != domain.RoleEmployee && role != domain.RoleAdmin {
+			return fmt.Errorf("%w: unknown role %q", ErrInvalidPurchaseRule, role)
+		}
+		roles = append(roles, role)
+	}
+	teams := make([]string, 0, len(rule.AllowedTeams))
+	for _, team := range rule.AllowedTeams {
+		team, err := normalizeTeam(team)
+		if err != nil || team == "" {
+			return fmt.Errorf("%w: invalid team %q", ErrInvalidPurchaseRule, team)
+		}
+		teams = append(teams, team)
+	}
+	rule.AllowedRoles = uniqueSorted(roles)
+	rule.AllowedTeams = uniqueSorted(teams)
+
+	if rule.IsEmpty() {
+		return fmt.Errorf("%w: rule has no restrictions", ErrInvalidPurchaseRule)
+	}
+	return nil
+}
+
+func uniqueSorted(values []string) []string {
+	sort.Strings(values)
+	result := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func formatComponents(components []*domain.BundleComponent) string {
+	parts := make([]string, len(components))
+	for i, c := range components {
+		parts[i] = fmt.Sprintf("%s x%d", c.Item.Name, c.Quantity)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatStock(stock *int) string {
+	if stock == nil {
+		return "unlimited"
+	}
+	return strconv.Itoa(*stock)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"avito-backend-intern-winter25/internal/metrics"
 	"avito-backend-intern-winter25/internal/models/domain"
 	"avito-backend-intern-winter25/internal/storage"
 	"context"
@@ -8,38 +9,127 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 var (
 	ErrInsufficientCoins = errors.New("insufficient coins")
+	ErrOutOfStock        = errors.New("item is out of stock")
+	ErrInvalidQuantity   = errors.New("quantity must be positive")
+	ErrInvalidMerchSort  = errors.New("unknown sort order")
+	ErrInvalidPriceRange = errors.New("invalid price range")
+	ErrVariantRequired   = errors.New("item has variants, choose one")
+	ErrVariantNotFound   = errors.New("variant not found")
+
+	ErrPurchaseRestricted    = errors.New("item is not available for you")
+	ErrAccountTooNew         = errors.New("account is too new for this item")
+	ErrPurchaseLimitExceeded = errors.New("purchase limit exceeded")
+
+	ErrNotOnSaleYet = errors.New("item is not on sale yet")
+	ErrSaleEnded    = errors.New("item is no longer on sale")
+
+	ErrGiftRecipientNotFound = errors.New("gift recipient not found")
+	ErrGiftToSelf            = errors.New("cannot gift to yourself")
+	ErrInvalidGiftMessage    = errors.New("gift message is too long")
+)
+
+const (
+	DefaultMerchPageSize = 20
+	MaxMerchPageSize     = 100
+
+	maxGiftMessageRunes = 500
 )
 
 type MerchService struct {
 	merchRepo    storage.MerchRepository
 	purchaseRepo storage.PurchaseRepository
+	orderRepo    storage.OrderRepository
+	promoRepo    storage.PromoCodeRepository
 	userRepo     storage.UserRepository
+	walletRepo   storage.WalletRepository
+	imageRepo    storage.MerchImageRepository
+	variantRepo  storage.MerchVariantRepository
+	ruleRepo     storage.MerchRuleRepository
+	dropRepo     storage.MerchDropRepository
+	bundleRepo   storage.MerchBundleRepository
+	notifyRepo   storage.NotificationRepository
+	blobs        storage.BlobStore
 	db           *sql.DB
 }
 
 func NewMerchService(
 	merchRepo storage.MerchRepository,
 	purchaseRepo storage.PurchaseRepository,
+	orderRepo storage.OrderRepository,
+	promoRepo storage.PromoCodeRepository,
 	userRepo storage.UserRepository,
+	walletRepo storage.WalletRepository,
+	imageRepo storage.MerchImageRepository,
+	variantRepo storage.MerchVariantRepository,
+	ruleRepo storage.MerchRuleRepository,
+	dropRepo storage.MerchDropRepository,
+	bundleRepo storage.MerchBundleRepository,
+	notifyRepo storage.NotificationRepository,
+	blobs storage.BlobStore,
 	db *sql.DB,
 ) *MerchService {
 	return &MerchService{
 		merchRepo:    merchRepo,
 		purchaseRepo: purchaseRepo,
+		orderRepo:    orderRepo,
+		promoRepo:    promoRepo,
 		userRepo:     userRepo,
+		walletRepo:   walletRepo,
+		imageRepo:    imageRepo,
+		variantRepo:  variantRepo,
+		ruleRepo:     ruleRepo,
+		dropRepo:     dropRepo,
+		bundleRepo:   bundleRepo,
+		notifyRepo:   notifyRepo,
+		blobs:        blobs,
 		db:           db,
 	}
 }
 
-func (s *MerchService) PurchaseItem(ctx context.Context, userID int64, itemName string) error {
+// PurchaseItem покупает quantity штук одного товара, покупка оформляется заказом из одной строки.
+// variantSKU обязателен для товаров с вариантами, promoCode необязателен.
+func (s *MerchService) PurchaseItem(ctx context.Context, userID int64, itemName, variantSKU string, quantity int, promoCode string) (*domain.Order, error) {
+	return s.purchase(ctx, userID, nil, itemName, variantSKU, quantity, promoCode)
+}
+
+// GiftItem покупает товар в подарок: монеты списываются с покупателя, товар записывается на получателя,
+// получатель получает уведомление. Ограничения на покупку проверяются для получателя.
+func (s *MerchService) GiftItem(ctx context.Context, buyerID int64, recipientName, itemName, variantSKU string, quantity int, message, promoCode string) (*domain.Order, error) {
+	message = strings.TrimSpace(message)
+	if utf8.RuneCountInString(message) > maxGiftMessageRunes {
+		return nil, ErrInvalidGiftMessage
+	}
+	recipient, err := s.userRepo.FindByUsername(ctx, recipientName)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrGiftRecipientNotFound, recipientName)
+		}
+		return nil, err
+	}
+	if recipient.ID == buyerID {
+		return nil, ErrGiftToSelf
+	}
+
+	return s.purchase(ctx, buyerID, &domain.Gift{RecipientID: recipient.ID, RecipientName: recipient.Username, Message: message}, itemName, variantSKU, quantity, promoCode)
+}
+
+func (s *MerchService) purchase(ctx context.Context, userID int64, gift *domain.Gift, itemName, variantSKU string, quantity int, promoCode string) (*domain.Order, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer func() {
 		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
@@ -49,36 +139,509 @@ func (s *MerchService) PurchaseItem(ctx context.Context, userID int64, itemName
 
 	item, err := s.merchRepo.FindByName(ctx, itemName)
 	if err != nil {
-		return fmt.Errorf("merch not found: %w", err)
+		return nil, fmt.Errorf("merch not found: %w", err)
+	}
+
+	variant, err := s.ResolveVariant(ctx, item, variantSKU)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.placeOrderTx(ctx, tx, userID, gift, []domain.OrderLine{{Item: item, Variant: variant, Quantity: quantity}}, promoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	reportStock(order)
+	return order, nil
+}
+
+// PlaceOrderTx списывает остатки и монеты по всем строкам и записывает покупки одним заказом.
+// Строки обрабатываются в порядке id товара, чтобы параллельные заказы блокировали остатки в одном порядке.
+// Скидка по промокоду уменьшает списываемую сумму и записывается в покупки.
+func (s *MerchService) PlaceOrderTx(ctx context.Context, tx *sql.Tx, userID int64, lines []domain.OrderLine, promoCode string) (*domain.Order, error) {
+	return s.placeOrderTx(ctx, tx, userID, nil, lines, promoCode)
+}
+
+// placeOrderTx оформляет заказ на userID; для подарка покупки записываются на получателя.
+func (s *MerchService) placeOrderTx(ctx context.Context, tx *sql.Tx, userID int64, gift *domain.Gift, lines []domain.OrderLine, promoCode string) (*domain.Order, error) {
+	ownerID := userID
+	if gift != nil {
+		ownerID = gift.RecipientID
+	}
+
+	lines = append([]domain.OrderLine(nil), lines...)
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Item.ID != lines[j].Item.ID {
+			return lines[i].Item.ID < lines[j].Item.ID
+		}
+		return variantID(lines[i].Variant) < variantID(lines[j].Variant)
+	})
+
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+	}
+	// остатки, тиражи и ограничения на покупку проверяются и по составляющим наборов
+	components, err := s.bundleComponentsTx(ctx, tx, lines)
+	if err != nil {
+		return nil, err
+	}
+	expanded := expandBundles(lines, components)
+
+	now := time.Now()
+	dropQuantities := make(map[int]int)
+	for _, line := range expanded {
+		if err := checkOnSale(line.Item, now); err != nil {
+			return nil, err
+		}
+		// есть ли у товара тираж, решает таблица шардов в транзакции, а не товар из кэша каталога:
+		// тираж могли задать уже после того, как каталог попал в кэш. Take не меняет товар без тиража
+		if !line.Item.Bundle {
+			dropQuantities[line.Item.ID] += line.Quantity
+		}
+	}
+
+	var promo *domain.PromoCode
+	discounts := make(map[int]int)
+	if promoCode != "" {
+		promo, discounts, err = s.applyPromoTx(ctx, tx, userID, promoCode, lines)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// остаток списывается в той же транзакции, что и монеты: при откате покупки он вернется.
+	// Строки идут в порядке id товара вместе с составляющими наборов, поэтому блокировки берутся в одном порядке.
+	stockLeft := make(map[string]int)
+	for _, line := range expanded {
+		// у набора своего остатка нет. Товар дропа без своего остатка строку merch не трогает,
+		// иначе на старте дропа все покупки ждали бы ее
+		if line.Item.Bundle || (line.Item.DropCap != nil && !line.Item.Limited()) {
+			continue
+		}
+		remaining, err := s.merchRepo.DecrementStock(ctx, tx, line.Item.ID, line.Quantity)
+		if err != nil {
+			if errors.Is(err, storage.ErrMerchOutOfStock) {
+				return nil, fmt.Errorf("%w: %s", ErrOutOfStock, line.Item.Name)
+			}
+			return nil, fmt.Errorf("failed to decrement stock: %w", err)
+		}
+		if remaining != nil {
+			stockLeft[line.Item.Name] = *remaining
+		}
+	}
+	// тираж списывается один раз на товар: строки одного товара с разными вариантами идут подряд
+	for _, line := range expanded {
+		quantity, ok := dropQuantities[line.Item.ID]
+		if !ok {
+			continue
+		}
+		delete(dropQuantities, line.Item.ID)
+		if err := s.dropRepo.Take(ctx, tx, line.Item.ID, quantity); err != nil {
+			if errors.Is(err, storage.ErrDropSoldOut) {
+				return nil, fmt.Errorf("%w: %s drop is sold out", ErrOutOfStock, line.Item.Name)
+			}
+			return nil, fmt.Errorf("failed to take drop quantity: %w", err)
+		}
+	}
+
+	totals := make(map[string]int)
+	for i, line := range lines {
+		if line.Variant != nil {
+			if _, err := s.variantRepo.DecrementStock(ctx, tx, line.Variant.ID, line.Quantity); err != nil {
+				if errors.Is(err, storage.ErrVariantOutOfStock) {
+					return nil, fmt.Errorf("%w: %s", ErrOutOfStock, line.Variant.SKU)
+				}
+				return nil, fmt.Errorf("failed to decrement variant stock: %w", err)
+			}
+		}
+		totals[orderCurrency(line.Item)] += line.Total() - discounts[i]
+	}
+
+	if err := s.checkRulesTx(ctx, tx, ownerID, expanded); err != nil {
+		return nil, err
+	}
+
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		if err := s.charge(ctx, tx, userID, currency, totals[currency]); err != nil {
+			return nil, err
+		}
+	}
+
+	order := &domain.Order{UserID: userID, CreatedAt: now, StockLeft: stockLeft}
+	if err := s.orderRepo.Create(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	var totalDiscount int
+	for i, line := range lines {
+		purchase := &domain.Purchase{
+			UserID:       ownerID,
+			OrderID:      order.ID,
+			MerchID:      line.Item.ID,
+			Item:         line.Item.Name,
+			Quantity:     line.Quantity,
+			UnitPrice:    line.UnitPrice(),
+			Price:        line.Total() - discounts[i],
+			Discount:     discounts[i],
+			Currency:     orderCurrency(line.Item),
+			PurchaseDate: order.CreatedAt,
+		}
+		if line.Variant != nil {
+			purchase.VariantID = line.Variant.ID
+			purchase.SKU = line.Variant.SKU
+		}
+		if promo != nil {
+			purchase.PromoCodeID = promo.ID
+		}
+		if gift != nil {
+			purchase.GiftedBy = userID
+			purchase.GiftMessage = gift.Message
+			purchase.GiftTo = gift.RecipientName
+		}
+		if err := s.purchaseRepo.Create(ctx, tx, purchase); err != nil {
+			return nil, fmt.Errorf("failed to create purchase: %w", err)
+		}
+		order.Purchases = append(order.Purchases, purchase)
+		totalDiscount += discounts[i]
+
+		// состав набора попадает в инвентарь покупками с нулевой ценой
+		for _, c := range components[line.Item.ID] {
+			content := *purchase
+			content.ID = 0
+			content.MerchID = c.Item.ID
+			content.Item = c.Item.Name
+			content.Quantity = line.Quantity * c.Quantity
+			content.UnitPrice = 0
+			content.Price = 0
+			content.Discount = 0
+			content.PromoCodeID = 0
+			content.BundlePurchaseID = purchase.ID
+			if err := s.purchaseRepo.Create(ctx, tx, &content); err != nil {
+				return nil, fmt.Errorf("failed to create bundle content purchase: %w", err)
+			}
+			order.Purchases = append(order.Purchases, &content)
+		}
+	}
+
+	if promo != nil {
+		if err := s.promoRepo.CreateRedemption(ctx, tx, &domain.PromoRedemption{
+			PromoCodeID: promo.ID,
+			UserID:      userID,
+			OrderID:     order.ID,
+			Discount:    totalDiscount,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to record promo code redemption: %w", err)
+		}
+	}
+
+	if gift != nil {
+		if err := s.notifyGiftTx(ctx, tx, userID, gift, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+func (s *MerchService) notifyGiftTx(ctx context.Context, tx *sql.Tx, buyerID int64, gift *domain.Gift, order *domain.Order) error {
+	buyer, err := s.userRepo.FindByID(ctx, buyerID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+	var items []string
+	for _, p := range order.Purchases {
+		if p.BundlePurchaseID == 0 {
+			items = append(items, p.Item)
+		}
+	}
+	text := fmt.Sprintf("%s gifted you %s", buyer.Username, strings.Join(items, ", "))
+	if gift.Message != "" {
+		text += ": " + gift.Message
+	}
+	err = s.notifyRepo.Create(ctx, tx, &domain.Notification{
+		UserID:  gift.RecipientID,
+		Kind:    domain.NotificationGiftReceived,
+		Message: text,
+		Data: map[string]string{
+			"from":    buyer.Username,
+			"items":   strings.Join(items, ","),
+			"message": gift.Message,
+			"orderId": strconv.FormatInt(order.ID, 10),
+		},
+		CreatedAt: order.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to notify gift recipient: %w", err)
+	}
+	return nil
+}
+
+// applyPromoTx проверяет правила кода и учитывает его использование.
+// Строка кода заблокирована до конца транзакции, поэтому лимиты нельзя превысить параллельными заказами.
+func (s *MerchService) applyPromoTx(ctx context.Context, tx *sql.Tx, userID int64, code string, lines []domain.OrderLine) (*domain.PromoCode, map[int]int, error) {
+	promo, err := s.promoRepo.FindByCodeForUpdate(ctx, tx, normalizePromoCode(code))
+	if err != nil {
+		if errors.Is(err, storage.ErrPromoCodeNotFound) {
+			return nil, nil, ErrInvalidPromoCode
+		}
+		return nil, nil, err
+	}
+	if !promo.IsValidAt(time.Now()) {
+		return nil, nil, ErrPromoCodeExpired
+	}
+	if promo.Exhausted() {
+		return nil, nil, ErrPromoCodeExhausted
+	}
+	if promo.MaxUsesPerUser != nil {
+		used, err := s.promoRepo.CountUserRedemptions(ctx, tx, promo.ID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if used >= *promo.MaxUsesPerUser {
+			return nil, nil, ErrPromoCodeUserLimit
+		}
+	}
+
+	subtotal := promo.EligibleSubtotal(lines)
+	if subtotal == 0 {
+		return nil, nil, ErrPromoCodeNotApplicable
+	}
+	if subtotal < promo.MinSpend {
+		return nil, nil, fmt.Errorf("%w: %d %s", ErrPromoCodeMinSpend, promo.MinSpend, promo.Currency)
+	}
+
+	if err := s.promoRepo.IncrementUses(ctx, tx, promo.ID); err != nil {
+		if errors.Is(err, storage.ErrPromoCodeExhausted) {
+			return nil, nil, ErrPromoCodeExhausted
+		}
+		return nil, nil, err
 	}
+	return promo, promo.Discounts(lines), nil
+}
 
+// checkRulesTx проверяет ограничения на покупку товаров заказа. Вызывается после списания остатков, чтобы
+// блокировка лимита пользователя бралась после блокировок остатков. Параллельные покупки не обойдут лимит:
+// CountUserItem держит блокировку пары пользователь-товар до конца транзакции.
+func (s *MerchService) checkRulesTx(ctx context.Context, tx *sql.Tx, userID int64, lines []domain.OrderLine) error {
+	var ids []int
+	names := make(map[int]string)
+	quantities := make(map[int]int)
+	for _, line := range lines {
+		if _, ok := quantities[line.Item.ID]; !ok {
+			ids = append(ids, line.Item.ID)
+			names[line.Item.ID] = line.Item.Name
+		}
+		quantities[line.Item.ID] += line.Quantity
+	}
+
+	rules, err := s.ruleRepo.GetByMerchIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load purchase rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("user not found: %w", err)
 	}
 
-	if user.Coins < item.Price {
-		return ErrInsufficientCoins
+	now := time.Now()
+	for _, id := range ids {
+		rule, ok := rules[id]
+		if !ok {
+			continue
+		}
+		if !rule.AllowsRole(user.Role) {
+			return fmt.Errorf("%w: %s is available only for roles: %s", ErrPurchaseRestricted, names[id],
+				strings.Join(rule.AllowedRoles, ", "))
+		}
+		if !rule.AllowsTeam(user.Team) {
+			return fmt.Errorf("%w: %s is available only for teams: %s", ErrPurchaseRestricted, names[id],
+				strings.Join(rule.AllowedTeams, ", "))
+		}
+		if eligibleAt := rule.EligibleAt(user); now.Before(eligibleAt) {
+			return fmt.Errorf("%w: %s requires an account at least %d days old, available from %s", ErrAccountTooNew,
+				names[id], rule.MinAccountAgeDays, eligibleAt.Format("2006-01-02"))
+		}
+		if rule.MaxQuantity == 0 {
+			continue
+		}
+		bought, err := s.purchaseRepo.CountUserItem(ctx, tx, userID, id, rule.LimitSince(now))
+		if err != nil {
+			return err
+		}
+		if bought+quantities[id] > rule.MaxQuantity {
+			period := ""
+			if rule.PeriodDays > 0 {
+				period = fmt.Sprintf(" per %d days", rule.PeriodDays)
+			}
+			return fmt.Errorf("%w: %s is limited to %d per user%s, already bought %d", ErrPurchaseLimitExceeded,
+				names[id], rule.MaxQuantity, period, bought)
+		}
 	}
+	return nil
+}
 
-	user.Coins -= item.Price
-	if err := s.userRepo.Update(ctx, tx, user); err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+// bundleComponentsTx загружает составляющие наборов из строк заказа по id набора.
+func (s *MerchService) bundleComponentsTx(ctx context.Context, tx *sql.Tx, lines []domain.OrderLine) (map[int][]*domain.BundleComponent, error) {
+	components := make(map[int][]*domain.BundleComponent)
+	for _, line := range lines {
+		if !line.Item.Bundle {
+			continue
+		}
+		if _, ok := components[line.Item.ID]; ok {
+			continue
+		}
+		items, err := s.bundleRepo.GetComponents(ctx, tx, line.Item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bundle components: %w", err)
+		}
+		for _, c := range items {
+			if !c.Item.Active {
+				return nil, fmt.Errorf("%w: %s", ErrMerchUnavailable, c.Item.Name)
+			}
+		}
+		components[line.Item.ID] = items
 	}
+	return components, nil
+}
 
-	if err := s.purchaseRepo.Create(ctx, tx, &domain.Purchase{
-		UserID:       userID,
-		Item:         item.Name,
-		Price:        item.Price,
-		PurchaseDate: time.Now(),
-	}); err != nil {
-		return fmt.Errorf("failed to create purchase: %w", err)
+// expandBundles добавляет к строкам заказа составляющие наборов и упорядочивает результат по id товара.
+func expandBundles(lines []domain.OrderLine, components map[int][]*domain.BundleComponent) []domain.OrderLine {
+	if len(components) == 0 {
+		return lines
 	}
+	expanded := append([]domain.OrderLine(nil), lines...)
+	for _, line := range lines {
+		for _, c := range components[line.Item.ID] {
+			expanded = append(expanded, domain.OrderLine{Item: c.Item, Quantity: line.Quantity * c.Quantity})
+		}
+	}
+	sort.SliceStable(expanded, func(i, j int) bool {
+		return expanded[i].Item.ID < expanded[j].Item.ID
+	})
+	return expanded
+}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit failed: %w", err)
+// checkOnSale проверяет, что товар продается в момент now.
+func checkOnSale(item *domain.Merch, now time.Time) error {
+	if item.OnSaleAt(now) {
+		return nil
+	}
+	if item.AvailableFrom != nil && now.Before(*item.AvailableFrom) {
+		return fmt.Errorf("%w: %s, sales start at %s", ErrNotOnSaleYet, item.Name,
+			item.AvailableFrom.UTC().Format(time.RFC3339))
+	}
+	return fmt.Errorf("%w: %s", ErrSaleEnded, item.Name)
+}
+
+// ResolveVariant находит вариант товара по SKU. Товар с активными вариантами без SKU купить нельзя.
+func (s *MerchService) ResolveVariant(ctx context.Context, item *domain.Merch, sku string) (*domain.MerchVariant, error) {
+	if sku == "" {
+		variants, err := s.variantRepo.GetByMerch(ctx, item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load variants: %w", err)
+		}
+		for _, v := range variants {
+			if v.Active {
+				return nil, fmt.Errorf("%w: %s", ErrVariantRequired, item.Name)
+			}
+		}
+		return nil, nil
 	}
 
+	variant, err := s.variantRepo.FindBySKU(ctx, sku)
+	if err != nil {
+		if errors.Is(err, storage.ErrVariantNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrVariantNotFound, sku)
+		}
+		return nil, err
+	}
+	if variant.MerchID != item.ID || !variant.Active {
+		return nil, fmt.Errorf("%w: %s", ErrVariantNotFound, sku)
+	}
+	return variant, nil
+}
+
+// RequireNoVariants отклоняет товары с активными вариантами там, где вариант не выбирается.
+func (s *MerchService) RequireNoVariants(ctx context.Context, item *domain.Merch) error {
+	if _, err := s.ResolveVariant(ctx, item, ""); err != nil {
+		if errors.Is(err, ErrVariantRequired) {
+			return fmt.Errorf("%w: %s", ErrVariantsUnsupported, item.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// CheckRulesTx проверяет правила покупки для получателя товара вне корзины и прямой покупки.
+func (s *MerchService) CheckRulesTx(ctx context.Context, tx *sql.Tx, userID int64, item *domain.Merch, quantity int) error {
+	return s.checkRulesTx(ctx, tx, userID, []domain.OrderLine{{Item: item, Quantity: quantity}})
+}
+
+// isRuleViolation сообщает, что получатель не проходит правила покупки товара.
+func isRuleViolation(err error) bool {
+	return errors.Is(err, ErrPurchaseRestricted) || errors.Is(err, ErrAccountTooNew) ||
+		errors.Is(err, ErrPurchaseLimitExceeded)
+}
+
+func variantID(v *domain.MerchVariant) int {
+	if v == nil {
+		return 0
+	}
+	return v.ID
+}
+
+func orderCurrency(item *domain.Merch) string {
+	if domain.IsPrimaryCurrency(item.Currency) {
+		return domain.PrimaryCurrency
+	}
+	return item.Currency
+}
+
+// charge списывает сумму с кошелька пользователя в валюте товара.
+func (s *MerchService) charge(ctx context.Context, tx *sql.Tx, userID int64, currency string, amount int) error {
+	if !domain.IsPrimaryCurrency(currency) {
+		wallet, err := s.walletRepo.FindForUpdate(ctx, tx, userID, currency)
+		if err != nil {
+			return fmt.Errorf("wallet not found: %w", err)
+		}
+		if wallet.Available() < amount {
+			return ErrInsufficientCoins
+		}
+		wallet.Balance -= amount
+		if err := s.walletRepo.Update(ctx, tx, wallet); err != nil {
+			return fmt.Errorf("failed to update wallet: %w", err)
+		}
+		return nil
+	}
+
+	user, err := s.userRepo.FindByIDForUpdate(ctx, tx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+
+	if user.AvailableCoins() < amount {
+		return ErrInsufficientCoins
+	}
+
+	user.Coins -= amount
+	if err := s.userRepo.Update(ctx, tx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
 	return nil
 }
 
@@ -93,3 +656,94 @@ func (s *MerchService) GetPurchasesByUser(ctx context.Context, userID int64) ([]
 func (s *MerchService) GetAllAvailableMerch(ctx context.Context) ([]*domain.Merch, error) {
 	return s.merchRepo.GetAllAvailableMerch(ctx)
 }
+
+// GetItem возвращает карточку товара с картинками, снятые с продажи товары не показываются.
+func (s *MerchService) GetItem(ctx context.Context, merchID int) (*domain.Merch, error) {
+	item, err := s.merchRepo.FindByID(ctx, merchID)
+	if err != nil {
+		return nil, err
+	}
+	if !item.Active {
+		return nil, storage.ErrMerchNotFound
+	}
+
+	item.Images, err = s.imageRepo.GetByMerch(ctx, merchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load merch images: %w", err)
+	}
+	for _, img := range item.Images {
+		img.URL = s.blobs.URL(img.Key)
+	}
+
+	variants, err := s.variantRepo.GetByMerch(ctx, merchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variants: %w", err)
+	}
+	for _, v := range variants {
+		if v.Active {
+			item.Variants = append(item.Variants, v)
+		}
+	}
+
+	if item.Bundle {
+		item.Components, err = s.bundleRepo.GetComponents(ctx, nil, merchID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bundle components: %w", err)
+		}
+	}
+	return item, nil
+}
+
+// SearchMerch ищет по каталогу. Без поискового запроса сортировка по релевантности сводится к порядку id,
+// как в /api/merch/list.
+func (s *MerchService) SearchMerch(ctx context.Context, filter domain.MerchFilter) (*domain.MerchPage, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Sort == "" {
+		filter.Sort = domain.MerchSortRelevance
+	}
+	if !domain.IsMerchSort(filter.Sort) {
+		return nil, ErrInvalidMerchSort
+	}
+	if filter.MinPrice != nil && *filter.MinPrice < 0 || filter.MaxPrice != nil && *filter.MaxPrice < 0 ||
+		filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, ErrInvalidPriceRange
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultMerchPageSize
+	}
+	if filter.Limit > MaxMerchPageSize {
+		filter.Limit = MaxMerchPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	filter.Category = strings.ToLower(strings.TrimSpace(filter.Category))
+	for i, tag := range filter.Tags {
+		filter.Tags[i] = strings.ToLower(strings.TrimSpace(tag))
+	}
+
+	return s.merchRepo.Search(ctx, filter)
+}
+
+// reportStock выгружает в метрики остатки после заказа, вызывается после коммита.
+func reportStock(order *domain.Order) {
+	for item, stock := range order.StockLeft {
+		metrics.SetMerchStock(item, stock)
+	}
+}
+
+// RefreshStockMetrics выгружает остатки в метрики, чтобы учесть изменения через админку.
+func (s *MerchService) RefreshStockMetrics(ctx context.Context) error {
+	items, err := s.merchRepo.GetAllAvailableMerch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list merch: %w", err)
+	}
+
+	metrics.ResetMerchStock()
+	for _, item := range items {
+		if item.Limited() {
+			metrics.SetMerchStock(item.Name, *item.Stock)
+		}
+	}
+	return nil
+}
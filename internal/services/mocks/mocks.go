@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/mock"
+	"io"
 	"time"
 )
 
@@ -48,6 +49,11 @@ func (m *MockUserRepository) FindByUsername(ctx context.Context, username string
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) SetTeam(ctx context.Context, userID int64, team string) error {
+	args := m.Called(ctx, userID, team)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, tx storage.Tx, user *domain.User) error {
 	args := m.Called(ctx, tx, user)
 	return args.Error(0)
@@ -137,6 +143,16 @@ func (m *MockTransactionRepository) GetReceivedTransactions(ctx context.Context,
 	return args.Get(0).([]*domain.CoinTransaction), args.Error(1)
 }
 
+func (m *MockTransactionRepository) CountSentSince(ctx context.Context, tx storage.Tx, userID int64, since time.Time) (int, error) {
+	args := m.Called(ctx, tx, userID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) SettleFees(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 type MockMerchRepository struct {
 	mock.Mock
 }
@@ -161,6 +177,90 @@ func (m *MockMerchRepository) FindByName(ctx context.Context, name string) (*dom
 	return args.Get(0).(*domain.Merch), args.Error(1)
 }
 
+func (m *MockMerchRepository) GetAll(ctx context.Context) ([]*domain.Merch, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Merch), args.Error(1)
+}
+
+func (m *MockMerchRepository) Search(ctx context.Context, filter domain.MerchFilter) (*domain.MerchPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MerchPage), args.Error(1)
+}
+
+func (m *MockMerchRepository) FindByID(ctx context.Context, id int) (*domain.Merch, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Merch), args.Error(1)
+}
+
+func (m *MockMerchRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int) (*domain.Merch, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Merch), args.Error(1)
+}
+
+func (m *MockMerchRepository) Create(ctx context.Context, tx storage.Tx, merch *domain.Merch) error {
+	args := m.Called(ctx, tx, merch)
+	return args.Error(0)
+}
+
+func (m *MockMerchRepository) Update(ctx context.Context, tx storage.Tx, merch *domain.Merch) error {
+	args := m.Called(ctx, tx, merch)
+	return args.Error(0)
+}
+
+func (m *MockMerchRepository) DecrementStock(ctx context.Context, tx storage.Tx, id int, quantity int) (*int, error) {
+	args := m.Called(ctx, tx, id, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*int), args.Error(1)
+}
+
+func (m *MockMerchRepository) IncrementStock(ctx context.Context, tx storage.Tx, id int, quantity int) error {
+	args := m.Called(ctx, tx, id, quantity)
+	return args.Error(0)
+}
+
+func (m *MockMerchRepository) GetStock(ctx context.Context, merchIDs []int) (map[int]*domain.MerchStock, error) {
+	args := m.Called(ctx, merchIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]*domain.MerchStock), args.Error(1)
+}
+
+type MockMerchAuditRepository struct {
+	mock.Mock
+}
+
+func NewMockMerchAuditRepository() *MockMerchAuditRepository {
+	return &MockMerchAuditRepository{}
+}
+
+func (m *MockMerchAuditRepository) Create(ctx context.Context, tx storage.Tx, entry *domain.MerchAudit) error {
+	args := m.Called(ctx, tx, entry)
+	return args.Error(0)
+}
+
+func (m *MockMerchAuditRepository) GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchAudit, error) {
+	args := m.Called(ctx, merchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MerchAudit), args.Error(1)
+}
+
 type MockPurchaseRepository struct {
 	mock.Mock
 }
@@ -182,6 +282,24 @@ func (m *MockPurchaseRepository) GetByUser(ctx context.Context, tx *sql.Tx, user
 	return args.Get(0).([]*domain.Purchase), args.Error(1)
 }
 
+func (m *MockPurchaseRepository) GetByOrder(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.Purchase, error) {
+	args := m.Called(ctx, tx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Purchase), args.Error(1)
+}
+
+func (m *MockPurchaseRepository) CountUserItem(ctx context.Context, tx *sql.Tx, userID int64, merchID int, since time.Time) (int, error) {
+	args := m.Called(ctx, tx, userID, merchID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPurchaseRepository) HasPurchased(ctx context.Context, userID int64, merchID int) (bool, error) {
+	args := m.Called(ctx, userID, merchID)
+	return args.Bool(0), args.Error(1)
+}
+
 type MockRedisClient struct {
 	mock.Mock
 }
@@ -201,3 +319,813 @@ func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}
 	}
 	return redis.NewStatusResult("OK", nil)
 }
+
+type MockStatementRepository struct {
+	mock.Mock
+}
+
+func NewMockStatementRepository() *MockStatementRepository {
+	return &MockStatementRepository{}
+}
+
+func (m *MockStatementRepository) Create(ctx context.Context, tx *sql.Tx, statement *domain.Statement) error {
+	args := m.Called(ctx, tx, statement)
+	return args.Error(0)
+}
+
+func (m *MockStatementRepository) FindByUserAndPeriod(ctx context.Context, tx *sql.Tx, userID int64, period time.Time) (*domain.Statement, error) {
+	args := m.Called(ctx, tx, userID, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Statement), args.Error(1)
+}
+
+func (m *MockStatementRepository) GetMovements(ctx context.Context, tx *sql.Tx, userID int64, from, to time.Time) ([]domain.StatementMovement, error) {
+	args := m.Called(ctx, tx, userID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StatementMovement), args.Error(1)
+}
+
+func (m *MockStatementRepository) GetNetChangeSince(ctx context.Context, tx *sql.Tx, userID int64, since time.Time) (int, error) {
+	args := m.Called(ctx, tx, userID, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockStatementRepository) ListUsersWithoutStatement(ctx context.Context, period time.Time) ([]int64, error) {
+	args := m.Called(ctx, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+type MockWalletRepository struct {
+	mock.Mock
+}
+
+func NewMockWalletRepository() *MockWalletRepository {
+	return &MockWalletRepository{}
+}
+
+func (m *MockWalletRepository) GetByUser(ctx context.Context, userID int64) ([]*domain.Wallet, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) FindForUpdate(ctx context.Context, tx storage.Tx, userID int64, currency string) (*domain.Wallet, error) {
+	args := m.Called(ctx, tx, userID, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) Update(ctx context.Context, tx storage.Tx, wallet *domain.Wallet) error {
+	args := m.Called(ctx, tx, wallet)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) CurrencyExists(ctx context.Context, currency string) (bool, error) {
+	args := m.Called(ctx, currency)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockHoldRepository struct {
+	mock.Mock
+}
+
+func NewMockHoldRepository() *MockHoldRepository {
+	return &MockHoldRepository{}
+}
+
+func (m *MockHoldRepository) Create(ctx context.Context, tx storage.Tx, hold *domain.Hold) error {
+	args := m.Called(ctx, tx, hold)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Hold, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) Update(ctx context.Context, tx storage.Tx, hold *domain.Hold) error {
+	args := m.Called(ctx, tx, hold)
+	return args.Error(0)
+}
+
+func (m *MockHoldRepository) GetActiveByUser(ctx context.Context, userID int64) ([]*domain.Hold, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Hold), args.Error(1)
+}
+
+func (m *MockHoldRepository) ExpireDue(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) Create(ctx context.Context, tx storage.Tx, order *domain.Order) error {
+	args := m.Called(ctx, tx, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) FindByID(ctx context.Context, id int64) (*domain.Order, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Order, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByUser(ctx context.Context, userID int64) ([]*domain.Order, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByStatus(ctx context.Context, status string) ([]*domain.Order, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) UpdateStatus(ctx context.Context, tx storage.Tx, order *domain.Order, change *domain.OrderStatusChange) error {
+	args := m.Called(ctx, tx, order, change)
+	return args.Error(0)
+}
+
+type MockCartRepository struct {
+	mock.Mock
+}
+
+func (m *MockCartRepository) GetByUser(ctx context.Context, tx *sql.Tx, userID int64) ([]*domain.CartItem, error) {
+	args := m.Called(ctx, tx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.CartItem), args.Error(1)
+}
+
+func (m *MockCartRepository) AddItem(ctx context.Context, userID int64, merchID int, variantID int, quantity int) error {
+	args := m.Called(ctx, userID, merchID, variantID, quantity)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) RemoveItem(ctx context.Context, userID int64, merchID int, variantID int) error {
+	args := m.Called(ctx, userID, merchID, variantID)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) Clear(ctx context.Context, tx storage.Tx, userID int64) error {
+	args := m.Called(ctx, tx, userID)
+	return args.Error(0)
+}
+
+type MockRefundRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefundRepository) Create(ctx context.Context, tx storage.Tx, refund *domain.Refund) error {
+	args := m.Called(ctx, tx, refund)
+	return args.Error(0)
+}
+
+type MockMerchPriceRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchPriceRepository) Create(ctx context.Context, tx storage.Tx, price *domain.MerchPrice) error {
+	args := m.Called(ctx, tx, price)
+	return args.Error(0)
+}
+
+func (m *MockMerchPriceRepository) GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchPrice, error) {
+	args := m.Called(ctx, merchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MerchPrice), args.Error(1)
+}
+
+type MockPromoCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockPromoCodeRepository) Create(ctx context.Context, tx storage.Tx, promo *domain.PromoCode) error {
+	args := m.Called(ctx, tx, promo)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) GetAll(ctx context.Context) ([]*domain.PromoCode, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) FindByCodeForUpdate(ctx context.Context, tx storage.Tx, code string) (*domain.PromoCode, error) {
+	args := m.Called(ctx, tx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) SetActive(ctx context.Context, id int64, active bool) error {
+	args := m.Called(ctx, id, active)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) IncrementUses(ctx context.Context, tx storage.Tx, id int64) error {
+	args := m.Called(ctx, tx, id)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) CountUserRedemptions(ctx context.Context, tx storage.Tx, promoID, userID int64) (int, error) {
+	args := m.Called(ctx, tx, promoID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPromoCodeRepository) CreateRedemption(ctx context.Context, tx storage.Tx, redemption *domain.PromoRedemption) error {
+	args := m.Called(ctx, tx, redemption)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeRepository) ReleaseRedemption(ctx context.Context, tx storage.Tx, orderID int64) error {
+	args := m.Called(ctx, tx, orderID)
+	return args.Error(0)
+}
+
+type MockMerchImageRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchImageRepository) Create(ctx context.Context, tx storage.Tx, image *domain.MerchImage) error {
+	args := m.Called(ctx, tx, image)
+	return args.Error(0)
+}
+
+func (m *MockMerchImageRepository) GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchImage, error) {
+	args := m.Called(ctx, merchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MerchImage), args.Error(1)
+}
+
+func (m *MockMerchImageRepository) Delete(ctx context.Context, tx storage.Tx, merchID int, imageID int64) (*domain.MerchImage, error) {
+	args := m.Called(ctx, tx, merchID, imageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MerchImage), args.Error(1)
+}
+
+type MockMerchVariantRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchVariantRepository) Create(ctx context.Context, tx storage.Tx, variant *domain.MerchVariant) error {
+	args := m.Called(ctx, tx, variant)
+	return args.Error(0)
+}
+
+func (m *MockMerchVariantRepository) Update(ctx context.Context, tx storage.Tx, variant *domain.MerchVariant) error {
+	args := m.Called(ctx, tx, variant)
+	return args.Error(0)
+}
+
+func (m *MockMerchVariantRepository) GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchVariant, error) {
+	args := m.Called(ctx, merchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.MerchVariant), args.Error(1)
+}
+
+func (m *MockMerchVariantRepository) FindBySKU(ctx context.Context, sku string) (*domain.MerchVariant, error) {
+	args := m.Called(ctx, sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MerchVariant), args.Error(1)
+}
+
+func (m *MockMerchVariantRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int) (*domain.MerchVariant, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MerchVariant), args.Error(1)
+}
+
+func (m *MockMerchVariantRepository) DecrementStock(ctx context.Context, tx storage.Tx, id int, quantity int) (*int, error) {
+	args := m.Called(ctx, tx, id, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*int), args.Error(1)
+}
+
+func (m *MockMerchVariantRepository) IncrementStock(ctx context.Context, tx storage.Tx, id int, quantity int) error {
+	args := m.Called(ctx, tx, id, quantity)
+	return args.Error(0)
+}
+
+type MockMerchRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchRuleRepository) Get(ctx context.Context, merchID int) (*domain.MerchPurchaseRule, error) {
+	args := m.Called(ctx, merchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MerchPurchaseRule), args.Error(1)
+}
+
+func (m *MockMerchRuleRepository) GetByMerchIDs(ctx context.Context, merchIDs []int) (map[int]*domain.MerchPurchaseRule, error) {
+	args := m.Called(ctx, merchIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]*domain.MerchPurchaseRule), args.Error(1)
+}
+
+func (m *MockMerchRuleRepository) Upsert(ctx context.Context, tx storage.Tx, rule *domain.MerchPurchaseRule) error {
+	args := m.Called(ctx, tx, rule)
+	return args.Error(0)
+}
+
+func (m *MockMerchRuleRepository) Delete(ctx context.Context, tx storage.Tx, merchID int) error {
+	args := m.Called(ctx, tx, merchID)
+	return args.Error(0)
+}
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, tx storage.Tx, notification *domain.Notification) error {
+	args := m.Called(ctx, tx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetByUser(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]*domain.Notification, error) {
+	args := m.Called(ctx, userID, unreadOnly, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	args := m.Called(ctx, userID, notificationID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockMerchDropRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchDropRepository) Set(ctx context.Context, tx *sql.Tx, drop *domain.MerchDrop) error {
+	args := m.Called(ctx, tx, drop)
+	return args.Error(0)
+}
+
+func (m *MockMerchDropRepository) Take(ctx context.Context, tx *sql.Tx, merchID int, quantity int) error {
+	args := m.Called(ctx, tx, merchID, quantity)
+	return args.Error(0)
+}
+
+func (m *MockMerchDropRepository) Release(ctx context.Context, tx *sql.Tx, merchID int, quantity int) error {
+	args := m.Called(ctx, tx, merchID, quantity)
+	return args.Error(0)
+}
+
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) Add(ctx context.Context, userID int64, merchID int) error {
+	args := m.Called(ctx, userID, merchID)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) Remove(ctx context.Context, userID int64, merchID int) error {
+	args := m.Called(ctx, userID, merchID)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) GetByUser(ctx context.Context, userID int64) ([]*domain.WishlistItem, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetMerchIDs(ctx context.Context, userID int64) (map[int]bool, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int]bool), args.Error(1)
+}
+
+func (m *MockWishlistRepository) SetShared(ctx context.Context, userID int64, shared bool) error {
+	args := m.Called(ctx, userID, shared)
+	return args.Error(0)
+}
+
+func (m *MockWishlistRepository) IsShared(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWishlistRepository) CollectAlerts(ctx context.Context, tx *sql.Tx, limit int) ([]*domain.WishlistAlert, error) {
+	args := m.Called(ctx, tx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.WishlistAlert), args.Error(1)
+}
+
+type MockAuctionRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuctionRepository) Create(ctx context.Context, tx storage.Tx, auction *domain.Auction) error {
+	args := m.Called(ctx, tx, auction)
+	return args.Error(0)
+}
+
+func (m *MockAuctionRepository) FindByID(ctx context.Context, id int64) (*domain.Auction, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Auction), args.Error(1)
+}
+
+func (m *MockAuctionRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Auction, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Auction), args.Error(1)
+}
+
+func (m *MockAuctionRepository) ClaimDue(ctx context.Context, tx storage.Tx, now time.Time) (*domain.Auction, error) {
+	args := m.Called(ctx, tx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Auction), args.Error(1)
+}
+
+func (m *MockAuctionRepository) GetActive(ctx context.Context, now time.Time) ([]*domain.Auction, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Auction), args.Error(1)
+}
+
+func (m *MockAuctionRepository) Update(ctx context.Context, tx storage.Tx, auction *domain.Auction) error {
+	args := m.Called(ctx, tx, auction)
+	return args.Error(0)
+}
+
+func (m *MockAuctionRepository) CreateBid(ctx context.Context, tx storage.Tx, bid *domain.AuctionBid) error {
+	args := m.Called(ctx, tx, bid)
+	return args.Error(0)
+}
+
+func (m *MockAuctionRepository) GetBids(ctx context.Context, auctionID int64, limit int) ([]*domain.AuctionBid, error) {
+	args := m.Called(ctx, auctionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.AuctionBid), args.Error(1)
+}
+
+type MockRaffleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRaffleRepository) Create(ctx context.Context, tx storage.Tx, raffle *domain.Raffle) error {
+	args := m.Called(ctx, tx, raffle)
+	return args.Error(0)
+}
+
+func (m *MockRaffleRepository) FindByID(ctx context.Context, id int64) (*domain.Raffle, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Raffle), args.Error(1)
+}
+
+func (m *MockRaffleRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Raffle, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Raffle), args.Error(1)
+}
+
+func (m *MockRaffleRepository) ClaimDue(ctx context.Context, tx storage.Tx, now time.Time) (*domain.Raffle, error) {
+	args := m.Called(ctx, tx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Raffle), args.Error(1)
+}
+
+func (m *MockRaffleRepository) GetActive(ctx context.Context) ([]*domain.Raffle, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Raffle), args.Error(1)
+}
+
+func (m *MockRaffleRepository) Update(ctx context.Context, tx storage.Tx, raffle *domain.Raffle) error {
+	args := m.Called(ctx, tx, raffle)
+	return args.Error(0)
+}
+
+func (m *MockRaffleRepository) CreateEntry(ctx context.Context, tx storage.Tx, entry *domain.RaffleEntry) error {
+	args := m.Called(ctx, tx, entry)
+	return args.Error(0)
+}
+
+func (m *MockRaffleRepository) CountUserTickets(ctx context.Context, tx storage.Tx, raffleID, userID int64) (int, error) {
+	args := m.Called(ctx, tx, raffleID, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRaffleRepository) GetEntries(ctx context.Context, tx *sql.Tx, raffleID int64) ([]*domain.RaffleEntry, error) {
+	args := m.Called(ctx, tx, raffleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RaffleEntry), args.Error(1)
+}
+
+func (m *MockRaffleRepository) MarkEntriesRefunded(ctx context.Context, tx storage.Tx, raffleID int64, at time.Time) error {
+	args := m.Called(ctx, tx, raffleID, at)
+	return args.Error(0)
+}
+
+func (m *MockRaffleRepository) CreateWinner(ctx context.Context, tx storage.Tx, winner *domain.RaffleWinner) error {
+	args := m.Called(ctx, tx, winner)
+	return args.Error(0)
+}
+
+func (m *MockRaffleRepository) GetWinners(ctx context.Context, raffleID int64) ([]*domain.RaffleWinner, error) {
+	args := m.Called(ctx, raffleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RaffleWinner), args.Error(1)
+}
+
+type MockGroupBuyRepository struct {
+	mock.Mock
+}
+
+func (m *MockGroupBuyRepository) Create(ctx context.Context, tx storage.Tx, groupBuy *domain.GroupBuy) error {
+	args := m.Called(ctx, tx, groupBuy)
+	return args.Error(0)
+}
+
+func (m *MockGroupBuyRepository) FindByID(ctx context.Context, id int64) (*domain.GroupBuy, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.GroupBuy), args.Error(1)
+}
+
+func (m *MockGroupBuyRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.GroupBuy, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.GroupBuy), args.Error(1)
+}
+
+func (m *MockGroupBuyRepository) ClaimExpired(ctx context.Context, tx storage.Tx, now time.Time) (*domain.GroupBuy, error) {
+	args := m.Called(ctx, tx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.GroupBuy), args.Error(1)
+}
+
+func (m *MockGroupBuyRepository) GetOpen(ctx context.Context) ([]*domain.GroupBuy, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.GroupBuy), args.Error(1)
+}
+
+func (m *MockGroupBuyRepository) CountOpenByCreator(ctx context.Context, tx *sql.Tx, creatorID int64) (int, error) {
+	args := m.Called(ctx, tx, creatorID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockGroupBuyRepository) Update(ctx context.Context, tx storage.Tx, groupBuy *domain.GroupBuy) error {
+	args := m.Called(ctx, tx, groupBuy)
+	return args.Error(0)
+}
+
+func (m *MockGroupBuyRepository) CreatePledge(ctx context.Context, tx storage.Tx, pledge *domain.GroupBuyPledge) error {
+	args := m.Called(ctx, tx, pledge)
+	return args.Error(0)
+}
+
+func (m *MockGroupBuyRepository) GetPledges(ctx context.Context, tx *sql.Tx, groupBuyID int64) ([]*domain.GroupBuyPledge, error) {
+	args := m.Called(ctx, tx, groupBuyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.GroupBuyPledge), args.Error(1)
+}
+
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) Create(ctx context.Context, tx storage.Tx, review *domain.Review) error {
+	args := m.Called(ctx, tx, review)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Review, error) {
+	args := m.Called(ctx, tx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) FindByUserForUpdate(ctx context.Context, tx storage.Tx, userID int64, merchID int) (*domain.Review, error) {
+	args := m.Called(ctx, tx, userID, merchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) Update(ctx context.Context, tx storage.Tx, review *domain.Review) error {
+	args := m.Called(ctx, tx, review)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) Delete(ctx context.Context, tx storage.Tx, id int64) error {
+	args := m.Called(ctx, tx, id)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepository) GetByMerch(ctx context.Context, merchID int, limit int) ([]*domain.Review, error) {
+	args := m.Called(ctx, merchID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetByStatus(ctx context.Context, status string, limit int) ([]*domain.Review, error) {
+	args := m.Called(ctx, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) AdjustStats(ctx context.Context, tx storage.Tx, merchID int, countDelta, ratingDelta int) error {
+	args := m.Called(ctx, tx, merchID, countDelta, ratingDelta)
+	return args.Error(0)
+}
+
+type MockRecommendationRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecommendationRepository) Refresh(ctx context.Context, tx *sql.Tx, now time.Time, perItem int) error {
+	args := m.Called(ctx, tx, now, perItem)
+	return args.Error(0)
+}
+
+func (m *MockRecommendationRepository) AlsoBoughtWith(ctx context.Context, userID int64, merchID int, limit int) ([]*domain.Recommendation, error) {
+	args := m.Called(ctx, userID, merchID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Recommendation), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) AlsoBoughtForUser(ctx context.Context, userID int64, limit int) ([]*domain.Recommendation, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Recommendation), args.Error(1)
+}
+
+func (m *MockRecommendationRepository) PopularInTeam(ctx context.Context, userID int64, team string, limit int) ([]*domain.Recommendation, error) {
+	args := m.Called(ctx, userID, team, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Recommendation), args.Error(1)
+}
+
+type MockMerchBundleRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchBundleRepository) AddComponent(ctx context.Context, tx storage.Tx, component *domain.BundleComponent) error {
+	args := m.Called(ctx, tx, component)
+	return args.Error(0)
+}
+
+func (m *MockMerchBundleRepository) GetComponents(ctx context.Context, tx *sql.Tx, bundleID int) ([]*domain.BundleComponent, error) {
+	args := m.Called(ctx, tx, bundleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.BundleComponent), args.Error(1)
+}
+
+func (m *MockMerchBundleRepository) GetBundleNames(ctx context.Context, tx *sql.Tx, componentID int) ([]string, error) {
+	args := m.Called(ctx, tx, componentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type MockBlobStore struct {
+	mock.Mock
+}
+
+func (m *MockBlobStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	args := m.Called(ctx, key, r)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockBlobStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockBlobStore) URL(key string) string {
+	args := m.Called(key)
+	return args.String(0)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+)
+
+const maxNotifications = 100
+
+type NotificationService struct {
+	notifyRepo storage.NotificationRepository
+}
+
+func NewNotificationService(notifyRepo storage.NotificationRepository) *NotificationService {
+	return &NotificationService{notifyRepo: notifyRepo}
+}
+
+// List возвращает последние уведомления пользователя, новые первыми.
+func (s *NotificationService) List(ctx context.Context, userID int64, unreadOnly bool) ([]*domain.Notification, error) {
+	return s.notifyRepo.GetByUser(ctx, userID, unreadOnly, maxNotifications)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	return s.notifyRepo.MarkRead(ctx, userID, notificationID)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return s.notifyRepo.MarkAllRead(ctx, userID)
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrUnknownOrderStatus        = errors.New("unknown order status")
+	ErrInvalidOrderTransition    = errors.New("order status transition is not allowed")
+	ErrOrderNotCancellable       = errors.New("order can not be cancelled after it is shipped")
+	ErrCancellationWindowExpired = errors.New("order cancellation window has expired")
+	ErrGroupOrderNotCancellable  = errors.New("group buy orders can not be cancelled")
+	ErrPrizeOrderNotCancellable  = errors.New("auction and raffle orders can not be cancelled")
+)
+
+type OrderService struct {
+	orderRepo          storage.OrderRepository
+	purchaseRepo       storage.PurchaseRepository
+	refundRepo         storage.RefundRepository
+	merchRepo          storage.MerchRepository
+	variantRepo        storage.MerchVariantRepository
+	dropRepo           storage.MerchDropRepository
+	promoRepo          storage.PromoCodeRepository
+	userRepo           storage.UserRepository
+	walletRepo         storage.WalletRepository
+	db                 *sql.DB
+	cancellationWindow time.Duration
+}
+
+func NewOrderService(
+	orderRepo storage.OrderRepository,
+	purchaseRepo storage.PurchaseRepository,
+	refundRepo storage.RefundRepository,
+	merchRepo storage.MerchRepository,
+	variantRepo storage.MerchVariantRepository,
+	dropRepo storage.MerchDropRepository,
+	promoRepo storage.PromoCodeRepository,
+	userRepo storage.UserRepository,
+	walletRepo storage.WalletRepository,
+	db *sql.DB,
+	cancellationWindow time.Duration,
+) *OrderService {
+	return &OrderService{
+		orderRepo:          orderRepo,
+		purchaseRepo:       purchaseRepo,
+		refundRepo:         refundRepo,
+		merchRepo:          merchRepo,
+		variantRepo:        variantRepo,
+		dropRepo:           dropRepo,
+		promoRepo:          promoRepo,
+		userRepo:           userRepo,
+		walletRepo:         walletRepo,
+		db:                 db,
+		cancellationWindow: cancellationWindow,
+	}
+}
+
+func (s *OrderService) GetUserOrders(ctx context.Context, userID int64) ([]*domain.Order, error) {
+	return s.orderRepo.GetByUser(ctx, userID)
+}
+
+// GetUserOrder возвращает заказ, только если он принадлежит пользователю.
+func (s *OrderService) GetUserOrder(ctx context.Context, userID, orderID int64) (*domain.Order, error) {
+	order, err := s.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, storage.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (s *OrderService) ListOrders(ctx context.Context, status string) ([]*domain.Order, error) {
+	if status != "" && !domain.IsOrderStatus(status) {
+		return nil, ErrUnknownOrderStatus
+	}
+	return s.orderRepo.GetByStatus(ctx, status)
+}
+
+// TransitionOrder переводит заказ в новый статус, если переход разрешен из текущего.
+// Отмена администратором не ограничена окном отмены, но так же возвращает деньги и остатки.
+func (s *OrderService) TransitionOrder(ctx context.Context, adminID, orderID int64, status string) (*domain.Order, error) {
+	if !domain.IsOrderStatus(status) {
+		return nil, ErrUnknownOrderStatus
+	}
+
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		order, err := s.orderRepo.FindByIDForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if !order.CanTransitionTo(status) {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidOrderTransition, order.Status, status)
+		}
+		if status == domain.OrderStatusCancelled {
+			return s.cancelTx(ctx, tx, order, adminID)
+		}
+
+		return s.orderRepo.UpdateStatus(ctx, tx, order, &domain.OrderStatusChange{
+			FromStatus: order.Status,
+			ToStatus:   status,
+			ChangedBy:  adminID,
+			CreatedAt:  time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindByID(ctx, orderID)
+}
+
+// CancelOrder отменяет заказ по просьбе покупателя: до отправки и, если окно задано, не позже окна с момента заказа.
+func (s *OrderService) CancelOrder(ctx context.Context, userID, orderID int64) (*domain.Order, error) {
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		order, err := s.orderRepo.FindByIDForUpdate(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.UserID != userID {
+			return storage.ErrOrderNotFound
+		}
+		if !order.CanTransitionTo(domain.OrderStatusCancelled) {
+			return ErrOrderNotCancellable
+		}
+		if s.cancellationWindow > 0 && time.Since(order.CreatedAt) > s.cancellationWindow {
+			return ErrCancellationWindowExpired
+		}
+		return s.cancelTx(ctx, tx, order, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.orderRepo.FindByID(ctx, orderID)
+}
+
+// cancelTx возвращает на счет ровно оплаченную сумму каждой покупки и товар на склад и в тираж дропа.
+func (s *OrderService) cancelTx(ctx context.Context, tx *sql.Tx, order *domain.Order, changedBy int64) error {
+	purchases, err := s.purchaseRepo.GetByOrder(ctx, tx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get order purchases: %w", err)
+	}
+	// совместную покупку оплатили несколько участников, вернуть деньги одному покупателю нельзя.
+	// Лот аукциона и приз розыгрыша не возвращаются в обычную продажу: лот уникален и оплачен холдом,
+	// а приз бесплатный
+	for _, p := range purchases {
+		if p.GroupBuyID != 0 {
+			return ErrGroupOrderNotCancellable
+		}
+		if p.HoldID != 0 || p.RaffleID != 0 {
+			return ErrPrizeOrderNotCancellable
+		}
+	}
+
+	// тираж возвращается один раз на товар, как и списывался при покупке
+	dropQuantities := make(map[int]int)
+	var promoUsed bool
+	for _, p := range purchases {
+		if p.MerchID != 0 {
+			dropQuantities[p.MerchID] += p.Quantity
+		}
+		promoUsed = promoUsed || p.PromoCodeID != 0
+	}
+	// отмененный заказ не должен расходовать лимит промокода
+	if promoUsed {
+		if err := s.promoRepo.ReleaseRedemption(ctx, tx, order.ID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	refunds := make(map[string]int)
+	for _, p := range purchases {
+		if p.MerchID != 0 {
+			if err := s.merchRepo.IncrementStock(ctx, tx, p.MerchID, p.Quantity); err != nil {
+				return err
+			}
+			if quantity, ok := dropQuantities[p.MerchID]; ok {
+				delete(dropQuantities, p.MerchID)
+				if err := s.dropRepo.Release(ctx, tx, p.MerchID, quantity); err != nil {
+					return err
+				}
+			}
+		}
+		if p.VariantID != 0 {
+			if err := s.variantRepo.IncrementStock(ctx, tx, p.VariantID, p.Quantity); err != nil {
+				return err
+			}
+		}
+		if p.Price <= 0 {
+			continue
+		}
+		if err := s.refundRepo.Create(ctx, tx, &domain.Refund{
+			OrderID:    order.ID,
+			PurchaseID: p.ID,
+			UserID:     order.UserID,
+			Amount:     p.Price,
+			Currency:   p.Currency,
+			CreatedAt:  now,
+		}); err != nil {
+			return fmt.Errorf("failed to create refund: %w", err)
+		}
+		refunds[p.Currency] += p.Price
+	}
+
+	currencies := make([]string, 0, len(refunds))
+	for currency := range refunds {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		if err := s.credit(ctx, tx, order.UserID, currency, refunds[currency]); err != nil {
+			return err
+		}
+	}
+
+	return s.orderRepo.UpdateStatus(ctx, tx, order, &domain.OrderStatusChange{
+		FromStatus: order.Status,
+		ToStatus:   domain.OrderStatusCancelled,
+		ChangedBy:  changedBy,
+		CreatedAt:  now,
+	})
+}
+
+func (s *OrderService) credit(ctx context.Context, tx *sql.Tx, userID int64, currency string, amount int) error {
+	if !domain.IsPrimaryCurrency(currency) {
+		wallet, err := s.walletRepo.FindForUpdate(ctx, tx, userID, currency)
+		if err != nil {
+			return fmt.Errorf("wallet not found: %w", err)
+		}
+		wallet.Balance += amount
+		if err := s.walletRepo.Update(ctx, tx, wallet); err != nil {
+			return fmt.Errorf("failed to update wallet: %w", err)
+		}
+		return nil
+	}
+
+	user, err := s.userRepo.FindByIDForUpdate(ctx, tx, userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+	user.Coins += amount
+	if err := s.userRepo.Update(ctx, tx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPromoCode       = errors.New("promo code not found")
+	ErrPromoCodeExpired       = errors.New("promo code is not active")
+	ErrPromoCodeExhausted     = errors.New("promo code usage limit reached")
+	ErrPromoCodeUserLimit     = errors.New("promo code already used the maximum number of times")
+	ErrPromoCodeNotApplicable = errors.New("promo code does not apply to these items")
+	ErrPromoCodeMinSpend      = errors.New("order total is below promo code minimum spend")
+
+	ErrInvalidPromoRule = errors.New("invalid promo code rule")
+)
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{2,49}$`)
+
+// коды вводятся пользователями, регистр не важен
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+type PromoService struct {
+	promoRepo  storage.PromoCodeRepository
+	walletRepo storage.WalletRepository
+	db         *sql.DB
+}
+
+func NewPromoService(promoRepo storage.PromoCodeRepository, walletRepo storage.WalletRepository, db *sql.DB) *PromoService {
+	return &PromoService{
+		promoRepo:  promoRepo,
+		walletRepo: walletRepo,
+		db:         db,
+	}
+}
+
+func (s *PromoService) ListPromoCodes(ctx context.Context) ([]*domain.PromoCode, error) {
+	return s.promoRepo.GetAll(ctx)
+}
+
+func (s *PromoService) CreatePromoCode(ctx context.Context, adminID int64, promo *domain.PromoCode) (*domain.PromoCode, error) {
+	promo.Code = normalizePromoCode(promo.Code)
+	if err := s.validate(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	promo.CreatedBy = adminID
+	promo.CreatedAt = time.Now()
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		return s.promoRepo.Create(ctx, tx, promo)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// DeactivatePromoCode отключает код, уже оформленные со скидкой заказы не меняются.
+func (s *PromoService) DeactivatePromoCode(ctx context.Context, id int64) error {
+	return s.promoRepo.SetActive(ctx, id, false)
+}
+
+func (s *PromoService) validate(ctx context.Context, promo *domain.PromoCode) error {
+	if !promoCodePattern.MatchString(promo.Code) {
+		return fmt.Errorf("%w: code must be 3-50 latin letters, digits, dashes or underscores", ErrInvalidPromoRule)
+	}
+	switch promo.Kind {
+	case domain.PromoKindPercent:
+		if promo.Value <= 0 || promo.Value > 100 {
+			return fmt.Errorf("%w: percent discount must be between 1 and 100", ErrInvalidPromoRule)
+		}
+	case domain.PromoKindFixed:
+		if promo.Value <= 0 {
+			return fmt.Errorf("%w: fixed discount must be positive", ErrInvalidPromoRule)
+		}
+	default:
+		return fmt.Errorf("%w: kind must be percent or fixed", ErrInvalidPromoRule)
+	}
+	if promo.MinSpend < 0 {
+		return fmt.Errorf("%w: min spend must not be negative", ErrInvalidPromoRule)
+	}
+	if promo.ValidFrom != nil && promo.ValidUntil != nil && !promo.ValidUntil.After(*promo.ValidFrom) {
+		return fmt.Errorf("%w: validity window is empty", ErrInvalidPromoRule)
+	}
+	if promo.MaxUses != nil && *promo.MaxUses <= 0 || promo.MaxUsesPerUser != nil && *promo.MaxUsesPerUser <= 0 {
+		return fmt.Errorf("%w: usage limits must be positive", ErrInvalidPromoRule)
+	}
+
+	if domain.IsPrimaryCurrency(promo.Currency) {
+		promo.Currency = domain.PrimaryCurrency
+		return nil
+	}
+	exists, err := s.walletRepo.CurrencyExists(ctx, promo.Currency)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUnknownCurrency
+	}
+	return nil
+}
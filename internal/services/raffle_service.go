@@ -0,0 +1,327 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidRaffle     = errors.New("raffle needs a positive ticket price and prize count and a draw time in the future")
+	ErrRaffleClosed      = errors.New("raffle is not selling tickets")
+	ErrRaffleTicketLimit = errors.New("raffle ticket limit exceeded")
+)
+
+type RaffleService struct {
+	raffleRepo   storage.RaffleRepository
+	merchRepo    storage.MerchRepository
+	merchService *MerchService
+	orderRepo    storage.OrderRepository
+	purchaseRepo storage.PurchaseRepository
+	userRepo     storage.UserRepository
+	notifyRepo   storage.NotificationRepository
+	db           *sql.DB
+}
+
+func NewRaffleService(
+	raffleRepo storage.RaffleRepository,
+	merchRepo storage.MerchRepository,
+	merchService *MerchService,
+	orderRepo storage.OrderRepository,
+	purchaseRepo storage.PurchaseRepository,
+	userRepo storage.UserRepository,
+	notifyRepo storage.NotificationRepository,
+	db *sql.DB,
+) *RaffleService {
+	return &RaffleService{
+		raffleRepo:   raffleRepo,
+		merchRepo:    merchRepo,
+		merchService: merchService,
+		orderRepo:    orderRepo,
+		purchaseRepo: purchaseRepo,
+		userRepo:     userRepo,
+		notifyRepo:   notifyRepo,
+		db:           db,
+	}
+}
+
+// CreateRaffle объявляет розыгрыш. Призы сразу снимаются с остатка, seed генерируется сейчас,
+// а публикуется только его хеш. Победителей seed определяет только вместе с итоговым списком билетов.
+func (s *RaffleService) CreateRaffle(ctx context.Context, adminID int64, itemName string, raffle *domain.Raffle) (*domain.Raffle, error) {
+	now := time.Now()
+	if raffle.TicketPrice <= 0 || raffle.Prizes <= 0 || raffle.MaxTicketsPerUser < 0 || !raffle.DrawAt.After(now) {
+		return nil, ErrInvalidRaffle
+	}
+	item, err := s.merchRepo.FindByName(ctx, itemName)
+	if err != nil {
+		return nil, err
+	}
+	if item.Bundle {
+		return nil, fmt.Errorf("%w: %s is a bundle", ErrBundleUnsupported, item.Name)
+	}
+	if err := s.merchService.RequireNoVariants(ctx, item); err != nil {
+		return nil, err
+	}
+
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("failed to generate raffle seed: %w", err)
+	}
+	raffle.Seed = hex.EncodeToString(seed)
+	raffle.SeedHash = domain.RaffleSeedHash(raffle.Seed)
+	raffle.MerchID = item.ID
+	raffle.Item = item.Name
+	raffle.CreatedBy = adminID
+	raffle.CreatedAt = now
+	err = runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := s.merchRepo.DecrementStock(ctx, tx, item.ID, raffle.Prizes); err != nil {
+			if errors.Is(err, storage.ErrMerchOutOfStock) {
+				return fmt.Errorf("%w: %s", ErrOutOfStock, item.Name)
+			}
+			return fmt.Errorf("failed to decrement stock: %w", err)
+		}
+		return s.raffleRepo.Create(ctx, tx, raffle)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return raffle, nil
+}
+
+func (s *RaffleService) ListRaffles(ctx context.Context) ([]*domain.Raffle, error) {
+	return s.raffleRepo.GetActive(ctx)
+}
+
+// GetRaffle возвращает розыгрыш и, если он проведен, победителей.
+func (s *RaffleService) GetRaffle(ctx context.Context, raffleID int64) (*domain.Raffle, []*domain.RaffleWinner, error) {
+	raffle, err := s.raffleRepo.FindByID(ctx, raffleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if raffle.Status != domain.RaffleStatusDrawn {
+		return raffle, nil, nil
+	}
+	winners, err := s.raffleRepo.GetWinners(ctx, raffleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return raffle, winners, nil
+}
+
+// GetTickets возвращает полный список купленных билетов для проверки розыгрыша.
+func (s *RaffleService) GetTickets(ctx context.Context, raffleID int64) ([]*domain.RaffleEntry, error) {
+	if _, err := s.raffleRepo.FindByID(ctx, raffleID); err != nil {
+		return nil, err
+	}
+	return s.raffleRepo.GetEntries(ctx, nil, raffleID)
+}
+
+// BuyTickets списывает монеты и выдает билеты с очередными номерами. Строка розыгрыша заблокирована
+// до конца транзакции, поэтому номера не пересекаются, а лимит на пользователя не обходится параллельными покупками.
+func (s *RaffleService) BuyTickets(ctx context.Context, userID, raffleID int64, quantity int) (*domain.RaffleEntry, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	var entry *domain.RaffleEntry
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		raffle, err := s.raffleRepo.FindByIDForUpdate(ctx, tx, raffleID)
+		if err != nil {
+			return err
+		}
+		if !raffle.SellsTicketsAt(time.Now()) {
+			return fmt.Errorf("%w: tickets are sold until %s", ErrRaffleClosed, raffle.DrawAt.UTC().Format(time.RFC3339))
+		}
+		if raffle.MaxTicketsPerUser > 0 {
+			owned, err := s.raffleRepo.CountUserTickets(ctx, tx, raffleID, userID)
+			if err != nil {
+				return err
+			}
+			if owned+quantity > raffle.MaxTicketsPerUser {
+				return fmt.Errorf("%w: %d of %d tickets already bought", ErrRaffleTicketLimit, owned, raffle.MaxTicketsPerUser)
+			}
+		}
+		// участник выигрывает не больше одного приза, поэтому правила проверяются на одну штуку.
+		// При розыгрыше они не перепроверяются: иначе результат нельзя было бы проверить по списку билетов
+		if err := s.merchService.CheckRulesTx(ctx, tx, userID, &domain.Merch{ID: raffle.MerchID, Name: raffle.Item}, 1); err != nil {
+			return err
+		}
+
+		amount := raffle.TicketPrice * quantity
+		user, err := s.userRepo.FindByIDForUpdate(ctx, tx, userID)
+		if err != nil {
+			return fmt.Errorf("user not found: %w", err)
+		}
+		if user.AvailableCoins() < amount {
+			return ErrInsufficientCoins
+		}
+		user.Coins -= amount
+		if err := s.userRepo.Update(ctx, tx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+
+		entry = &domain.RaffleEntry{
+			RaffleID:    raffleID,
+			UserID:      userID,
+			Username:    user.Username,
+			FirstTicket: raffle.TicketsSold + 1,
+			Quantity:    quantity,
+			Amount:      amount,
+			CreatedAt:   time.Now(),
+		}
+		if err := s.raffleRepo.CreateEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+		raffle.TicketsSold += quantity
+		return s.raffleRepo.Update(ctx, tx, raffle)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// CancelRaffle отменяет открытый розыгрыш: монеты за билеты возвращаются, призы - на остаток.
+func (s *RaffleService) CancelRaffle(ctx context.Context, raffleID int64) (*domain.Raffle, error) {
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		raffle, err := s.raffleRepo.FindByIDForUpdate(ctx, tx, raffleID)
+		if err != nil {
+			return err
+		}
+		if raffle.Status != domain.RaffleStatusOpen {
+			return fmt.Errorf("%w: raffle is %s", ErrRaffleClosed, raffle.Status)
+		}
+		entries, err := s.raffleRepo.GetEntries(ctx, tx, raffleID)
+		if err != nil {
+			return err
+		}
+
+		// пользователи блокируются по возрастанию id, чтобы не взаимоблокироваться с параллельными переводами
+		refunds := make(map[int64]int)
+		for _, e := range entries {
+			if e.RefundedAt == nil {
+				refunds[e.UserID] += e.Amount
+			}
+		}
+		userIDs := make([]int64, 0, len(refunds))
+		for id := range refunds {
+			userIDs = append(userIDs, id)
+		}
+		sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+		for _, id := range userIDs {
+			user, err := s.userRepo.FindByIDForUpdate(ctx, tx, id)
+			if err != nil {
+				return fmt.Errorf("user not found: %w", err)
+			}
+			user.Coins += refunds[id]
+			if err := s.userRepo.Update(ctx, tx, user); err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+		}
+
+		now := time.Now()
+		if err := s.raffleRepo.MarkEntriesRefunded(ctx, tx, raffleID, now); err != nil {
+			return err
+		}
+		if err := s.merchRepo.IncrementStock(ctx, tx, raffle.MerchID, raffle.Prizes); err != nil {
+			return err
+		}
+		raffle.Status = domain.RaffleStatusCancelled
+		raffle.DrawnAt = &now
+		return s.raffleRepo.Update(ctx, tx, raffle)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.raffleRepo.FindByID(ctx, raffleID)
+}
+
+// DrawRaffles проводит розыгрыши, время которых пришло, каждый в своей транзакции.
+func (s *RaffleService) DrawRaffles(ctx context.Context) error {
+	for {
+		claimed := false
+		err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+			raffle, err := s.raffleRepo.ClaimDue(ctx, tx, time.Now())
+			if err != nil {
+				if errors.Is(err, storage.ErrRaffleNotFound) {
+					return nil
+				}
+				return err
+			}
+			claimed = true
+			return s.drawTx(ctx, tx, raffle)
+		})
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return nil
+		}
+	}
+}
+
+// drawTx выбирает победителей по seed и оформляет каждому заказ с бесплатной покупкой приза.
+// Призы, которым не хватило участников, возвращаются на остаток.
+func (s *RaffleService) drawTx(ctx context.Context, tx *sql.Tx, raffle *domain.Raffle) error {
+	entries, err := s.raffleRepo.GetEntries(ctx, tx, raffle.ID)
+	if err != nil {
+		return err
+	}
+	winners := domain.DrawRaffleWinners(raffle.Seed, entries, raffle.Prizes)
+	log.Printf("raffle %d drawn: seed=%s seed_hash=%s tickets_hash=%s tickets=%d winners=%d",
+		raffle.ID, raffle.Seed, raffle.SeedHash, domain.RaffleTicketsHash(entries), raffle.TicketsSold, len(winners))
+
+	now := time.Now()
+	for _, w := range winners {
+		order := &domain.Order{UserID: w.UserID, CreatedAt: now}
+		if err := s.orderRepo.Create(ctx, tx, order); err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+		if err := s.purchaseRepo.Create(ctx, tx, &domain.Purchase{
+			UserID:       w.UserID,
+			OrderID:      order.ID,
+			MerchID:      raffle.MerchID,
+			Item:         raffle.Item,
+			Quantity:     1,
+			Currency:     domain.PrimaryCurrency,
+			PurchaseDate: now,
+			RaffleID:     raffle.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to create purchase: %w", err)
+		}
+		w.OrderID = order.ID
+		if err := s.raffleRepo.CreateWinner(ctx, tx, w); err != nil {
+			return err
+		}
+		if err := s.notifyRepo.Create(ctx, tx, &domain.Notification{
+			UserID:  w.UserID,
+			Kind:    domain.NotificationRaffleWon,
+			Message: fmt.Sprintf("Your ticket #%d won %s", w.Ticket, raffle.Item),
+			Data: map[string]string{
+				"raffleId": strconv.FormatInt(raffle.ID, 10),
+				"item":     raffle.Item,
+				"ticket":   strconv.Itoa(w.Ticket),
+				"orderId":  strconv.FormatInt(order.ID, 10),
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to create raffle notification: %w", err)
+		}
+	}
+
+	if unclaimed := raffle.Prizes - len(winners); unclaimed > 0 {
+		if err := s.merchRepo.IncrementStock(ctx, tx, raffle.MerchID, unclaimed); err != nil {
+			return err
+		}
+	}
+	raffle.Status = domain.RaffleStatusDrawn
+	raffle.DrawnAt = &now
+	return s.raffleRepo.Update(ctx, tx, raffle)
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	// recommendationsPerItem - сколько рекомендаций хранится на товар и на команду; запас нужен,
+	// потому что при чтении часть из них отсеивается (уже куплено, нет в наличии)
+	recommendationsPerItem = 50
+	recommendationsLimit   = 10
+)
+
+type RecommendationService struct {
+	recommendationRepo storage.RecommendationRepository
+	merchRepo          storage.MerchRepository
+	userRepo           storage.UserRepository
+	db                 *sql.DB
+}
+
+func NewRecommendationService(
+	recommendationRepo storage.RecommendationRepository,
+	merchRepo storage.MerchRepository,
+	userRepo storage.UserRepository,
+	db *sql.DB,
+) *RecommendationService {
+	return &RecommendationService{
+		recommendationRepo: recommendationRepo,
+		merchRepo:          merchRepo,
+		userRepo:           userRepo,
+		db:                 db,
+	}
+}
+
+// RefreshRecommendations пересчитывает рекомендации в одной транзакции, чтобы читатели
+// не видели наполовину очищенные таблицы.
+func (s *RecommendationService) RefreshRecommendations(ctx context.Context) error {
+	return runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		return s.recommendationRepo.Refresh(ctx, tx, time.Now(), recommendationsPerItem)
+	})
+}
+
+func (s *RecommendationService) ForUser(ctx context.Context, userID int64) (*domain.UserRecommendations, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	alsoBought, err := s.recommendationRepo.AlsoBoughtForUser(ctx, userID, recommendationsLimit)
+	if err != nil {
+		return nil, err
+	}
+	recs := &domain.UserRecommendations{AlsoBought: alsoBought, Team: user.Team}
+	if user.Team == "" {
+		return recs, nil
+	}
+	recs.PopularInTeam, err = s.recommendationRepo.PopularInTeam(ctx, userID, user.Team, recommendationsLimit)
+	if err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+func (s *RecommendationService) ForItem(ctx context.Context, userID int64, merchID int) ([]*domain.Recommendation, error) {
+	if _, err := s.merchRepo.FindByID(ctx, merchID); err != nil {
+		return nil, err
+	}
+	return s.recommendationRepo.AlsoBoughtWith(ctx, userID, merchID, recommendationsLimit)
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidReview       = errors.New("rating must be from 1 to 5 and review text at most 500 characters")
+	ErrNotVerifiedBuyer    = errors.New("only buyers of the item can review it")
+	ErrUnknownReviewStatus = errors.New("unknown review status")
+)
+
+const (
+	reviewsLimit           = 100
+	moderationReviewsLimit = 200
+)
+
+type ReviewService struct {
+	reviewRepo   storage.ReviewRepository
+	merchRepo    storage.MerchRepository
+	purchaseRepo storage.PurchaseRepository
+	db           *sql.DB
+}
+
+func NewReviewService(
+	reviewRepo storage.ReviewRepository,
+	merchRepo storage.MerchRepository,
+	purchaseRepo storage.PurchaseRepository,
+	db *sql.DB,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:   reviewRepo,
+		merchRepo:    merchRepo,
+		purchaseRepo: purchaseRepo,
+		db:           db,
+	}
+}
+
+func (s *ReviewService) GetReviews(ctx context.Context, merchID int) (*domain.Merch, []*domain.Review, error) {
+	item, err := s.merchRepo.FindByID(ctx, merchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	reviews, err := s.reviewRepo.GetByMerch(ctx, merchID, reviewsLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return item, reviews, nil
+}
+
+// CreateReview сохраняет отзыв покупателя. Второй отзыв того же пользователя на товар отклоняет
+// уникальный индекс, а не проверка в коде.
+func (s *ReviewService) CreateReview(ctx context.Context, userID int64, merchID int, rating int, body string) (*domain.Review, error) {
+	body = strings.TrimSpace(body)
+	if err := validateReview(rating, body); err != nil {
+		return nil, err
+	}
+	item, err := s.merchRepo.FindByID(ctx, merchID)
+	if err != nil {
+		return nil, err
+	}
+	purchased, err := s.purchaseRepo.HasPurchased(ctx, userID, merchID)
+	if err != nil {
+		return nil, err
+	}
+	if !purchased {
+		return nil, ErrNotVerifiedBuyer
+	}
+
+	review := &domain.Review{
+		MerchID:   merchID,
+		Item:      item.Name,
+		UserID:    userID,
+		Rating:    rating,
+		Body:      body,
+		Status:    domain.ReviewStatusPublished,
+		CreatedAt: time.Now(),
+	}
+	err = runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.reviewRepo.Create(ctx, tx, review); err != nil {
+			return err
+		}
+		return s.reviewRepo.AdjustStats(ctx, tx, merchID, 1, rating)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// UpdateReview меняет оценку и текст своего отзыва, статус модерации сохраняется.
+func (s *ReviewService) UpdateReview(ctx context.Context, userID int64, merchID int, rating int, body string) (*domain.Review, error) {
+	body = strings.TrimSpace(body)
+	if err := validateReview(rating, body); err != nil {
+		return nil, err
+	}
+	var review *domain.Review
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		review, err = s.reviewRepo.FindByUserForUpdate(ctx, tx, userID, merchID)
+		if err != nil {
+			return err
+		}
+		if review.Counted() && review.Rating != rating {
+			if err := s.reviewRepo.AdjustStats(ctx, tx, merchID, 0, rating-review.Rating); err != nil {
+				return err
+			}
+		}
+		review.Rating = rating
+		review.Body = body
+		review.UpdatedAt = time.Now()
+		return s.reviewRepo.Update(ctx, tx, review)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (s *ReviewService) DeleteReview(ctx context.Context, userID int64, merchID int) error {
+	return runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		review, err := s.reviewRepo.FindByUserForUpdate(ctx, tx, userID, merchID)
+		if err != nil {
+			return err
+		}
+		if err := s.reviewRepo.Delete(ctx, tx, review.ID); err != nil {
+			return err
+		}
+		if !review.Counted() {
+			return nil
+		}
+		return s.reviewRepo.AdjustStats(ctx, tx, merchID, -1, -review.Rating)
+	})
+}
+
+func (s *ReviewService) ListForModeration(ctx context.Context, status string) ([]*domain.Review, error) {
+	if status != "" && !domain.IsReviewStatus(status) {
+		return nil, ErrUnknownReviewStatus
+	}
+	return s.reviewRepo.GetByStatus(ctx, status, moderationReviewsLimit)
+}
+
+// ModerateReview меняет статус отзыва. Скрытие убирает оценку из рейтинга товара, возврат из скрытых - добавляет.
+func (s *ReviewService) ModerateReview(ctx context.Context, adminID, reviewID int64, status string) (*domain.Review, error) {
+	if !domain.IsReviewStatus(status) {
+		return nil, ErrUnknownReviewStatus
+	}
+	var review *domain.Review
+	err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+		var err error
+		review, err = s.reviewRepo.FindByIDForUpdate(ctx, tx, reviewID)
+		if err != nil {
+			return err
+		}
+		wasCounted := review.Counted()
+		now := time.Now()
+		review.Status = status
+		review.ModeratedBy = adminID
+		review.ModeratedAt = &now
+		switch {
+		case wasCounted && !review.Counted():
+			err = s.reviewRepo.AdjustStats(ctx, tx, review.MerchID, -1, -review.Rating)
+		case !wasCounted && review.Counted():
+			err = s.reviewRepo.AdjustStats(ctx, tx, review.MerchID, 1, review.Rating)
+		}
+		if err != nil {
+			return err
+		}
+		return s.reviewRepo.Update(ctx, tx, review)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func validateReview(rating int, body string) error {
+	if rating < 1 || rating > 5 || utf8.RuneCountInString(body) > domain.MaxReviewLength {
+		return ErrInvalidReview
+	}
+	return nil
+}
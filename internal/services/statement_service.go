@@ -0,0 +1,152 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var (
+	ErrStatementPeriodNotClosed = errors.New("statement period is not closed yet")
+	ErrStatementMismatch        = errors.New("statement does not match previous closing balance")
+)
+
+type StatementService struct {
+	statementRepo storage.StatementRepository
+	userRepo      storage.UserRepository
+	db            *sql.DB
+}
+
+func NewStatementService(
+	statementRepo storage.StatementRepository,
+	userRepo storage.UserRepository,
+	db *sql.DB,
+) *StatementService {
+	return &StatementService{
+		statementRepo: statementRepo,
+		userRepo:      userRepo,
+		db:            db,
+	}
+}
+
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (s *StatementService) GetStatement(ctx context.Context, userID int64, period time.Time) (*domain.Statement, error) {
+	statement, err := s.statementRepo.FindByUserAndPeriod(ctx, nil, userID, MonthStart(period))
+	if err == nil {
+		return statement, nil
+	}
+	if !errors.Is(err, storage.ErrStatementNotFound) {
+		return nil, err
+	}
+	return s.GenerateStatement(ctx, userID, period)
+}
+
+// GenerateStatement восстанавливает баланс на конец периода от текущего users.coins,
+// вычитая все движения после периода, и сохраняет выписку. Повторный вызов вернет уже сохраненную.
+func (s *StatementService) GenerateStatement(ctx context.Context, userID int64, period time.Time) (*domain.Statement, error) {
+	start := MonthStart(period)
+	end := start.AddDate(0, 1, 0)
+	if end.After(time.Now()) {
+		return nil, ErrStatementPeriodNotClosed
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("rollback error: %v", err)
+		}
+	}()
+
+	// блокировка строки пользователя не дает появиться новым движениям, пока считаем баланс
+	user, err := s.userRepo.FindByIDForUpdate(ctx, tx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	existing, err := s.statementRepo.FindByUserAndPeriod(ctx, tx, userID, start)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, storage.ErrStatementNotFound) {
+		return nil, err
+	}
+
+	netAfter, err := s.statementRepo.GetNetChangeSince(ctx, tx, userID, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movements after period: %w", err)
+	}
+	movements, err := s.statementRepo.GetMovements(ctx, tx, userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movements: %w", err)
+	}
+
+	statement := &domain.Statement{
+		UserID:         userID,
+		Period:         start,
+		ClosingBalance: user.Coins - netAfter,
+		Movements:      movements,
+		GeneratedAt:    time.Now(),
+	}
+	for _, m := range movements {
+		if m.Amount > 0 {
+			statement.TotalCredits += m.Amount
+		} else {
+			statement.TotalDebits -= m.Amount
+		}
+	}
+	statement.OpeningBalance = statement.ClosingBalance - statement.TotalCredits + statement.TotalDebits
+
+	prev, err := s.statementRepo.FindByUserAndPeriod(ctx, tx, userID, start.AddDate(0, -1, 0))
+	switch {
+	case err == nil:
+		if prev.ClosingBalance != statement.OpeningBalance {
+			return nil, fmt.Errorf("%w: expected %d, got %d", ErrStatementMismatch, prev.ClosingBalance, statement.OpeningBalance)
+		}
+	case !errors.Is(err, storage.ErrStatementNotFound):
+		return nil, err
+	}
+
+	if err := s.statementRepo.Create(ctx, tx, statement); err != nil {
+		return nil, fmt.Errorf("failed to save statement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return statement, nil
+}
+
+// GenerateMonthlyStatements формирует выписки за прошедший месяц всем, у кого их еще нет.
+func (s *StatementService) GenerateMonthlyStatements(ctx context.Context) error {
+	period := MonthStart(time.Now()).AddDate(0, -1, 0)
+
+	userIDs, err := s.statementRepo.ListUsersWithoutStatement(ctx, period)
+	if err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
+
+	var failed int
+	for _, userID := range userIDs {
+		if _, err := s.GenerateStatement(ctx, userID, period); err != nil {
+			log.Printf("failed to generate statement for user %d: %v", userID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to generate %d of %d statements", failed, len(userIDs))
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAuctionService_PlaceBid_Outbid(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	auctionRepo := new(mocks.MockAuctionRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+	notifyRepo := new(mocks.MockNotificationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	endsAt := time.Now().Add(30 * time.Second)
+	auction := &domain.Auction{
+		ID: 7, MerchID: 3, Item: "hoody", Currency: domain.PrimaryCurrency, StartingPrice: 100, MinIncrement: 10,
+		AntiSnipe: 2 * time.Minute, StartsAt: time.Now().Add(-time.Hour), EndsAt: endsAt, Status: domain.AuctionStatusOpen,
+		TopBid: &domain.AuctionBid{ID: 1, UserID: 2, Amount: 150, HoldID: 40},
+	}
+	previousHold := &domain.Hold{ID: 40, UserID: 2, Amount: 150, Status: domain.HoldStatusActive, ExpiresAt: endsAt.Add(time.Hour)}
+
+	auctionRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(7)).Return(auction, nil)
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(40)).Return(previousHold, nil)
+	holdRepo.On("Update", mock.Anything, mock.Anything, previousHold).Return(nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).
+		Return(&domain.User{ID: 1, Coins: 500}, nil)
+	holdRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(h *domain.Hold) bool {
+		return h.UserID == 1 && h.Amount == 160 && h.ExpiresAt.After(endsAt.Add(time.Minute))
+	})).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Hold).ID = 41
+	}).Return(nil)
+	auctionRepo.On("CreateBid", mock.Anything, mock.Anything, mock.MatchedBy(func(b *domain.AuctionBid) bool {
+		return b.AuctionID == 7 && b.UserID == 1 && b.Amount == 160 && b.HoldID == 41
+	})).Return(nil)
+	auctionRepo.On("Update", mock.Anything, mock.Anything, auction).Return(nil)
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.UserID == 2 && n.Kind == domain.NotificationAuctionOutbid && n.Data["amount"] == "160"
+	})).Return(nil)
+	auctionRepo.On("FindByID", mock.Anything, int64(7)).Return(auction, nil)
+
+	holdService := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewAuctionService(auctionRepo, new(mocks.MockMerchRepository), unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), notifyRepo, holdService, db)
+
+	// act
+	result, err := service.PlaceBid(context.Background(), 1, 7, 160)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldStatusReleased, previousHold.Status)
+	assert.Equal(t, 160, result.TopBid.Amount)
+	assert.True(t, result.EndsAt.After(endsAt), "bid near the end must extend the auction")
+	auctionRepo.AssertExpectations(t)
+	holdRepo.AssertExpectations(t)
+	notifyRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestAuctionService_PlaceBid_TooLow(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	auctionRepo := new(mocks.MockAuctionRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	auctionRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(7)).Return(&domain.Auction{
+		ID: 7, Currency: domain.PrimaryCurrency, StartingPrice: 100, MinIncrement: 10,
+		StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(time.Hour), Status: domain.AuctionStatusOpen,
+		TopBid: &domain.AuctionBid{UserID: 2, Amount: 150, HoldID: 40},
+	}, nil)
+
+	holdService := services.NewHoldService(holdRepo, new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewAuctionService(auctionRepo, new(mocks.MockMerchRepository), unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), holdService, db)
+
+	_, err = service.PlaceBid(context.Background(), 1, 7, 155)
+
+	assert.ErrorIs(t, err, services.ErrBidTooLow)
+	holdRepo.AssertNotCalled(t, "FindByIDForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestAuctionService_PlaceBid_Ended(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	auctionRepo := new(mocks.MockAuctionRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	auctionRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(7)).Return(&domain.Auction{
+		ID: 7, StartingPrice: 100, MinIncrement: 10, Status: domain.AuctionStatusOpen,
+		StartsAt: time.Now().Add(-2 * time.Hour), EndsAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	holdService := services.NewHoldService(new(mocks.MockHoldRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewAuctionService(auctionRepo, new(mocks.MockMerchRepository), unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), holdService, db)
+
+	_, err = service.PlaceBid(context.Background(), 1, 7, 500)
+
+	assert.ErrorIs(t, err, services.ErrAuctionNotOpen)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestAuctionService_SettleAuctions(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	auctionRepo := new(mocks.MockAuctionRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	notifyRepo := new(mocks.MockNotificationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	auction := &domain.Auction{
+		ID: 7, MerchID: 3, Item: "hoody", Currency: domain.PrimaryCurrency, Status: domain.AuctionStatusOpen,
+		EndsAt: time.Now().Add(-time.Minute), TopBid: &domain.AuctionBid{ID: 5, UserID: 1, Amount: 160, HoldID: 41},
+	}
+	hold := &domain.Hold{ID: 41, UserID: 1, Currency: domain.PrimaryCurrency, Amount: 160,
+		Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour)}
+	user := &domain.User{ID: 1, Coins: 500, HeldCoins: 160}
+
+	auctionRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(auction, nil).Once()
+	auctionRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(nil, storage.ErrAuctionNotFound).Once()
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(41)).Return(hold, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(user, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, user).Return(nil)
+	holdRepo.On("Update", mock.Anything, mock.Anything, hold).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.Order")).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*domain.Order).ID = 90
+		}).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.UserID == 1 && p.OrderID == 90 && p.MerchID == 3 && p.Price == 160 && p.HoldID == 41
+	})).Return(nil)
+	auctionRepo.On("Update", mock.Anything, mock.Anything, auction).Return(nil)
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.UserID == 1 && n.Kind == domain.NotificationAuctionWon && n.Data["orderId"] == "90"
+	})).Return(nil)
+
+	holdService := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewAuctionService(auctionRepo, new(mocks.MockMerchRepository), unrestrictedMerchService(), orderRepo, purchaseRepo, notifyRepo, holdService, db)
+
+	// act
+	err = service.SettleAuctions(context.Background())
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.AuctionStatusSettled, auction.Status)
+	assert.Equal(t, int64(90), auction.OrderID)
+	assert.Equal(t, domain.HoldStatusCaptured, hold.Status)
+	assert.Equal(t, 340, user.Coins)
+	purchaseRepo.AssertExpectations(t)
+	notifyRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestAuctionService_PlaceBid_Restricted(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	auctionRepo := new(mocks.MockAuctionRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	auctionRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(7)).Return(&domain.Auction{
+		ID: 7, MerchID: 3, Item: "hoody", Currency: domain.PrimaryCurrency, StartingPrice: 100, MinIncrement: 10,
+		StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(time.Hour), Status: domain.AuctionStatusOpen,
+	}, nil)
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(&domain.User{ID: 1, Role: "intern"}, nil)
+	merchService := restrictedMerchService(3, &domain.MerchPurchaseRule{MerchID: 3, AllowedRoles: []string{"staff"}},
+		userRepo, new(mocks.MockPurchaseRepository))
+
+	holdService := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewAuctionService(auctionRepo, new(mocks.MockMerchRepository), merchService, new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), holdService, db)
+
+	_, err = service.PlaceBid(context.Background(), 1, 7, 100)
+
+	assert.ErrorIs(t, err, services.ErrPurchaseRestricted)
+	holdRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestAuctionService_SettleAuctions_WinnerOverLimit(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	auctionRepo := new(mocks.MockAuctionRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	auction := &domain.Auction{
+		ID: 7, MerchID: 3, Item: "hoody", Currency: domain.PrimaryCurrency, Status: domain.AuctionStatusOpen,
+		EndsAt: time.Now().Add(-time.Minute), TopBid: &domain.AuctionBid{ID: 5, UserID: 1, Amount: 160, HoldID: 41},
+	}
+	hold := &domain.Hold{ID: 41, UserID: 1, Amount: 160, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour)}
+
+	auctionRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(auction, nil).Once()
+	auctionRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(nil, storage.ErrAuctionNotFound).Once()
+	// после ставки победитель купил hoody в магазине и исчерпал лимит
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(&domain.User{ID: 1}, nil)
+	purchaseRepo.On("CountUserItem", mock.Anything, mock.Anything, int64(1), 3, mock.Anything).Return(1, nil)
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(41)).Return(hold, nil)
+	holdRepo.On("Update", mock.Anything, mock.Anything, hold).Return(nil)
+	merchRepo.On("IncrementStock", mock.Anything, mock.Anything, 3, 1).Return(nil)
+	auctionRepo.On("Update", mock.Anything, mock.Anything, auction).Return(nil)
+	merchService := restrictedMerchService(3, &domain.MerchPurchaseRule{MerchID: 3, MaxQuantity: 1}, userRepo, purchaseRepo)
+
+	holdService := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewAuctionService(auctionRepo, merchRepo, merchService, new(mocks.MockOrderRepository),
+		purchaseRepo, new(mocks.MockNotificationRepository), holdService, db)
+
+	err = service.SettleAuctions(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.AuctionStatusUnsold, auction.Status)
+	assert.Equal(t, domain.HoldStatusReleased, hold.Status)
+	merchRepo.AssertExpectations(t)
+	purchaseRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestAuctionService_CreateAuction_Variants(t *testing.T) {
+	merchRepo := new(mocks.MockMerchRepository)
+	variantRepo := new(mocks.MockMerchVariantRepository)
+
+	item := &domain.Merch{ID: 3, Name: "hoody", Price: 300, Active: true}
+	merchRepo.On("FindByName", mock.Anything, "hoody").Return(item, nil)
+	variantRepo.On("GetByMerch", mock.Anything, 3).Return([]*domain.MerchVariant{{ID: 1, MerchID: 3, SKU: "HOODY-M", Active: true}}, nil)
+	merchService := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository),
+		variantRepo, noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+
+	service := services.NewAuctionService(new(mocks.MockAuctionRepository), merchRepo, merchService, new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), nil, nil)
+
+	_, err := service.CreateAuction(context.Background(), 9, "hoody", &domain.Auction{
+		StartingPrice: 100, MinIncrement: 10, StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour),
+	})
+
+	assert.ErrorIs(t, err, services.ErrVariantsUnsupported)
+	merchRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,135 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCartService_Checkout_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cartRepo := new(mocks.MockCartRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+	walletRepo := new(mocks.MockWalletRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	userID := int64(1)
+	cup := &domain.Merch{ID: 2, Name: "cup", Price: 20, Currency: domain.PrimaryCurrency, Active: true}
+	badge := &domain.Merch{ID: 5, Name: "event-badge", Price: 2, Currency: "event_token", Active: true}
+	wallet := &domain.Wallet{UserID: userID, Currency: "event_token", Balance: 10}
+
+	cartRepo.On("GetByUser", mock.Anything, mock.Anything, userID).Return([]*domain.CartItem{
+		{UserID: userID, Item: badge, Quantity: 1},
+		{UserID: userID, Item: cup, Quantity: 2},
+	}, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, cup.ID, 2).Return(nil, nil).Once()
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, badge.ID, 1).Return(nil, nil).Once()
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{ID: userID, Coins: 100}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Coins == 60
+	})).Return(nil)
+	walletRepo.On("FindForUpdate", mock.Anything, mock.Anything, userID, "event_token").Return(wallet, nil)
+	walletRepo.On("Update", mock.Anything, mock.Anything, wallet).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Order).ID = 9
+	}).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.OrderID == 9
+	})).Return(nil).Twice()
+	cartRepo.On("Clear", mock.Anything, mock.Anything, userID).Return(nil)
+
+	merchService := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, walletRepo, new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+	service := services.NewCartService(cartRepo, merchRepo, merchService, db)
+
+	// act
+	order, err := service.Checkout(context.Background(), userID, "")
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), order.ID)
+	require.Len(t, order.Purchases, 2)
+	assert.Equal(t, "cup", order.Purchases[0].Item)
+	assert.Equal(t, map[string]int{"coin": 40, "event_token": 2}, order.Totals())
+	assert.Equal(t, 8, wallet.Balance)
+	cartRepo.AssertExpectations(t)
+	merchRepo.AssertExpectations(t)
+	purchaseRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestCartService_Checkout_EmptyCart(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cartRepo := new(mocks.MockCartRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	cartRepo.On("GetByUser", mock.Anything, mock.Anything, int64(1)).Return([]*domain.CartItem{}, nil)
+
+	service := services.NewCartService(cartRepo, new(mocks.MockMerchRepository), nil, db)
+
+	_, err = service.Checkout(context.Background(), 1, "")
+
+	assert.ErrorIs(t, err, services.ErrCartEmpty)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestCartService_Checkout_RetiredItem(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cartRepo := new(mocks.MockCartRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	cartRepo.On("GetByUser", mock.Anything, mock.Anything, int64(1)).Return([]*domain.CartItem{
+		{UserID: 1, Item: &domain.Merch{ID: 2, Name: "cup", Price: 20, Active: false}, Quantity: 1},
+	}, nil)
+
+	merchService := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+	service := services.NewCartService(cartRepo, merchRepo, merchService, db)
+
+	_, err = service.Checkout(context.Background(), 1, "")
+
+	assert.ErrorIs(t, err, services.ErrMerchUnavailable)
+	merchRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	cartRepo.AssertNotCalled(t, "Clear", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestCartService_AddItem_ExceedsStock(t *testing.T) {
+	cartRepo := new(mocks.MockCartRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+
+	stock := 1
+	merchRepo.On("FindByName", mock.Anything, "cup").Return(&domain.Merch{ID: 2, Name: "cup", Price: 20, Stock: &stock, Active: true}, nil)
+
+	merchService := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+	service := services.NewCartService(cartRepo, merchRepo, merchService, nil)
+
+	err := service.AddItem(context.Background(), 1, "cup", "", 2)
+
+	assert.ErrorIs(t, err, services.ErrOutOfStock)
+	cartRepo.AssertNotCalled(t, "AddItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,436 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var testGroupBuyPolicy = domain.GroupBuyPolicy{MaxQuantity: 10, MaxOpenPerCreator: 3, MaxDuration: 7 * 24 * time.Hour}
+
+func TestGroupBuyService_Pledge_CompletesAtTarget(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	groupBuyRepo := new(mocks.MockGroupBuyRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	notifyRepo := new(mocks.MockNotificationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	groupBuy := &domain.GroupBuy{ID: 4, MerchID: 8, Item: "hoody", Quantity: 2, Currency: domain.PrimaryCurrency,
+		Target: 600, Pledged: 400, CreatorID: 1, Deadline: time.Now().Add(time.Hour), Status: domain.GroupBuyStatusOpen}
+	creator := &domain.User{ID: 1, Coins: 1000, HeldCoins: 400}
+	pledger := &domain.User{ID: 2, Coins: 500}
+	holds := map[int64]*domain.Hold{
+		30: {ID: 30, UserID: 1, Currency: domain.PrimaryCurrency, Amount: 400, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(2 * time.Hour)},
+		31: {ID: 31, UserID: 2, Currency: domain.PrimaryCurrency, Amount: 200, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(2 * time.Hour)},
+	}
+
+	groupBuyRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(groupBuy, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(creator, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(2)).Return(pledger, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	holdRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(h *domain.Hold) bool {
+		return h.UserID == 2 && h.Amount == 200
+	})).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Hold).ID = 31
+	}).Return(nil)
+	groupBuyRepo.On("CreatePledge", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.GroupBuyPledge")).Return(nil)
+	groupBuyRepo.On("GetPledges", mock.Anything, mock.Anything, int64(4)).Return([]*domain.GroupBuyPledge{
+		{GroupBuyID: 4, UserID: 2, Amount: 200, HoldID: 31},
+		{GroupBuyID: 4, UserID: 1, Amount: 400, HoldID: 30},
+	}, nil)
+	for id, hold := range holds {
+		holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, id).Return(hold, nil)
+		holdRepo.On("Update", mock.Anything, mock.Anything, hold).Return(nil)
+	}
+	left := 3
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 8, 2).Return(&left, nil).Once()
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.Order")).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*domain.Order).ID = 70
+		}).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.UserID == 1 && p.OrderID == 70 && p.Quantity == 2 && p.Price == 600 && p.GroupBuyID == 4
+	})).Return(nil)
+	groupBuyRepo.On("Update", mock.Anything, mock.Anything, groupBuy).Return(nil)
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Kind == domain.NotificationGroupBuyCompleted && n.Data["orderId"] == "70"
+	})).Return(nil).Twice()
+	groupBuyRepo.On("FindByID", mock.Anything, int64(4)).Return(groupBuy, nil)
+
+	holdService := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewGroupBuyService(groupBuyRepo, merchRepo, unrestrictedMerchService(), orderRepo, purchaseRepo,
+		notifyRepo, holdService, db, testGroupBuyPolicy)
+
+	// act
+	result, err := service.Pledge(context.Background(), 2, 4, 200)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.GroupBuyStatusCompleted, result.Status)
+	assert.Equal(t, int64(70), result.OrderID)
+	assert.Equal(t, domain.HoldStatusCaptured, holds[30].Status)
+	assert.Equal(t, domain.HoldStatusCaptured, holds[31].Status)
+	assert.Equal(t, 600, creator.Coins)
+	assert.Equal(t, 300, pledger.Coins)
+	merchRepo.AssertExpectations(t)
+	purchaseRepo.AssertExpectations(t)
+	notifyRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGroupBuyService_Pledge_ExceedsTarget(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	groupBuyRepo := new(mocks.MockGroupBuyRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	groupBuyRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(&domain.GroupBuy{
+		ID: 4, Target: 600, Pledged: 500, Deadline: time.Now().Add(time.Hour), Status: domain.GroupBuyStatusOpen,
+	}, nil)
+
+	holdService := services.NewHoldService(holdRepo, new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewGroupBuyService(groupBuyRepo, new(mocks.MockMerchRepository), unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), holdService, db, testGroupBuyPolicy)
+
+	_, err = service.Pledge(context.Background(), 2, 4, 150)
+
+	assert.ErrorIs(t, err, services.ErrPledgeExceedsTarget)
+	holdRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGroupBuyService_ExpireGroupBuys(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	groupBuyRepo := new(mocks.MockGroupBuyRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	notifyRepo := new(mocks.MockNotificationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	groupBuy := &domain.GroupBuy{ID: 4, MerchID: 8, Item: "hoody", Quantity: 2, Target: 600, Pledged: 200,
+		CreatorID: 1, Deadline: time.Now().Add(-time.Minute), Status: domain.GroupBuyStatusOpen}
+	hold := &domain.Hold{ID: 31, UserID: 2, Amount: 200, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour)}
+
+	groupBuyRepo.On("ClaimExpired", mock.Anything, mock.Anything, mock.Anything).Return(groupBuy, nil).Once()
+	groupBuyRepo.On("ClaimExpired", mock.Anything, mock.Anything, mock.Anything).Return(nil, storage.ErrGroupBuyNotFound).Once()
+	groupBuyRepo.On("GetPledges", mock.Anything, mock.Anything, int64(4)).Return([]*domain.GroupBuyPledge{
+		{GroupBuyID: 4, UserID: 2, Amount: 200, HoldID: 31},
+	}, nil)
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(31)).Return(hold, nil)
+	holdRepo.On("Update", mock.Anything, mock.Anything, hold).Return(nil)
+	groupBuyRepo.On("Update", mock.Anything, mock.Anything, groupBuy).Return(nil)
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Kind == domain.NotificationGroupBuyExpired
+	})).Return(nil).Twice()
+
+	holdService := services.NewHoldService(holdRepo, new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewGroupBuyService(groupBuyRepo, merchRepo, unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), notifyRepo, holdService, db, testGroupBuyPolicy)
+
+	// act
+	err = service.ExpireGroupBuys(context.Background())
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.GroupBuyStatusExpired, groupBuy.Status)
+	assert.Equal(t, domain.HoldStatusReleased, hold.Status)
+	merchRepo.AssertNotCalled(t, "IncrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	notifyRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGroupBuyService_CreateGroupBuy_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	groupBuyRepo := new(mocks.MockGroupBuyRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	deadline := time.Now().Add(24 * time.Hour)
+	stock := 3
+	created := &domain.GroupBuy{}
+	merchRepo.On("FindByName", mock.Anything, "hoody").Return(&domain.Merch{ID: 8, Name: "hoody", Price: 300, Stock: &stock, Active: true}, nil)
+	groupBuyRepo.On("CountOpenByCreator", mock.Anything, mock.Anything, int64(1)).Return(2, nil)
+	groupBuyRepo.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.GroupBuy")).
+		Run(func(args mock.Arguments) {
+			groupBuy := args.Get(2).(*domain.GroupBuy)
+			groupBuy.ID = 4
+			*created = *groupBuy
+		}).Return(nil)
+	groupBuyRepo.On("FindByID", mock.Anything, int64(4)).Return(created, nil)
+
+	service := services.NewGroupBuyService(groupBuyRepo, merchRepo, unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), nil, db, testGroupBuyPolicy)
+
+	// act
+	result, err := service.CreateGroupBuy(context.Background(), 1, "hoody", 2, deadline)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), result.ID)
+	assert.Equal(t, 600, result.Target)
+	assert.Equal(t, 2, result.Quantity)
+	assert.Equal(t, 8, result.MerchID)
+	assert.Equal(t, int64(1), result.CreatorID)
+	assert.Equal(t, domain.PrimaryCurrency, result.Currency)
+	assert.True(t, deadline.Equal(result.Deadline))
+	merchRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	groupBuyRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGroupBuyService_CreateGroupBuy_OverLimit(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	groupBuyRepo := new(mocks.MockGroupBuyRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	userRepo := new(mocks.MockUserRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	merchRepo.On("FindByName", mock.Anything, "hoody").Return(&domain.Merch{ID: 8, Name: "hoody", Price: 300, Active: true}, nil)
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(&domain.User{ID: 1}, nil)
+	purchaseRepo.On("CountUserItem", mock.Anything, mock.Anything, int64(1), 8, mock.Anything).Return(0, nil)
+	merchService := restrictedMerchService(8, &domain.MerchPurchaseRule{MerchID: 8, MaxQuantity: 1}, userRepo, purchaseRepo)
+
+	service := services.NewGroupBuyService(groupBuyRepo, merchRepo, merchService, new(mocks.MockOrderRepository),
+		purchaseRepo, new(mocks.MockNotificationRepository), nil, db, testGroupBuyPolicy)
+
+	_, err = service.CreateGroupBuy(context.Background(), 1, "hoody", 2, time.Now().Add(time.Hour))
+
+	assert.ErrorIs(t, err, services.ErrPurchaseLimitExceeded)
+	merchRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	groupBuyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGroupBuyService_CreateGroupBuy_Variants(t *testing.T) {
+	merchRepo := new(mocks.MockMerchRepository)
+	variantRepo := new(mocks.MockMerchVariantRepository)
+
+	item := &domain.Merch{ID: 8, Name: "hoody", Price: 300, Active: true}
+	merchRepo.On("FindByName", mock.Anything, "hoody").Return(item, nil)
+	variantRepo.On("GetByMerch", mock.Anything, 8).Return([]*domain.MerchVariant{{ID: 1, MerchID: 8, SKU: "HOODY-M", Active: true}}, nil)
+	merchService := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository),
+		variantRepo, noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+
+	service := services.NewGroupBuyService(new(mocks.MockGroupBuyRepository), merchRepo, merchService, new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), nil, nil, testGroupBuyPolicy)
+
+	_, err := service.CreateGroupBuy(context.Background(), 1, "hoody", 2, time.Now().Add(time.Hour))
+
+	assert.ErrorIs(t, err, services.ErrVariantsUnsupported)
+}
+
+func TestGroupBuyService_Pledge_ReleasedPledgeKeepsOpen(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	groupBuyRepo := new(mocks.MockGroupBuyRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	// сумма сбора посчитана до того, как создатель освободил свой холд 30
+	groupBuy := &domain.GroupBuy{ID: 4, MerchID: 8, Item: "hoody", Quantity: 2, Currency: domain.PrimaryCurrency,
+		Target: 600, Pledged: 400, CreatorID: 1, Deadline: time.Now().Add(time.Hour), Status: domain.GroupBuyStatusOpen}
+	pledger := &domain.User{ID: 2, Coins: 500}
+	released := &domain.Hold{ID: 30, UserID: 1, Currency: domain.PrimaryCurrency, Amount: 400, Status: domain.HoldStatusReleased,
+		ExpiresAt: time.Now().Add(2 * time.Hour)}
+	placed := &domain.Hold{ID: 31, UserID: 2, Currency: domain.PrimaryCurrency, Amount: 200, Status: domain.HoldStatusActive,
+		ExpiresAt: time.Now().Add(2 * time.Hour)}
+
+	groupBuyRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(groupBuy, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(2)).Return(pledger, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	holdRepo.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.Hold")).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Hold).ID = 31
+	}).Return(nil)
+	groupBuyRepo.On("CreatePledge", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.GroupBuyPledge")).Return(nil)
+	groupBuyRepo.On("GetPledges", mock.Anything, mock.Anything, int64(4)).Return([]*domain.GroupBuyPledge{
+		{GroupBuyID: 4, UserID: 1, Amount: 400, HoldID: 30},
+		{GroupBuyID: 4, UserID: 2, Amount: 200, HoldID: 31},
+	}, nil)
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(30)).Return(released, nil)
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(31)).Return(placed, nil)
+	groupBuyRepo.On("FindByID", mock.Anything, int64(4)).Return(groupBuy, nil)
+
+	holdService := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewGroupBuyService(groupBuyRepo, new(mocks.MockMerchRepository), unrestrictedMerchService(), orderRepo,
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), holdService, db, testGroupBuyPolicy)
+
+	// act
+	result, err := service.Pledge(context.Background(), 2, 4, 200)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.GroupBuyStatusOpen, result.Status)
+	assert.Equal(t, 200, result.Pledged)
+	assert.Equal(t, domain.HoldStatusActive, placed.Status)
+	assert.Equal(t, 500, pledger.Coins)
+	orderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	holdRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGroupBuyService_CreateGroupBuy_PolicyLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		deadline time.Time
+	}{
+		{name: "quantity over the cap", quantity: 11, deadline: time.Now().Add(time.Hour)},
+		{name: "deadline too far", quantity: 2, deadline: time.Now().Add(8 * 24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merchRepo := new(mocks.MockMerchRepository)
+			service := services.NewGroupBuyService(new(mocks.MockGroupBuyRepository), merchRepo, unrestrictedMerchService(),
+				new(mocks.MockOrderRepository), new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), nil, nil,
+				testGroupBuyPolicy)
+
+			_, err := service.CreateGroupBuy(context.Background(), 1, "hoody", tt.quantity, tt.deadline)
+
+			assert.ErrorIs(t, err, services.ErrInvalidGroupBuy)
+			merchRepo.AssertNotCalled(t, "FindByName", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGroupBuyService_CreateGroupBuy_TooManyOpen(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	groupBuyRepo := new(mocks.MockGroupBuyRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	merchRepo.On("FindByName", mock.Anything, "hoody").Return(&domain.Merch{ID: 8, Name: "hoody", Price: 300, Active: true}, nil)
+	groupBuyRepo.On("CountOpenByCreator", mock.Anything, mock.Anything, int64(1)).Return(3, nil)
+
+	service := services.NewGroupBuyService(groupBuyRepo, merchRepo, unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), new(mocks.MockNotificationRepository), nil, db, testGroupBuyPolicy)
+
+	_, err = service.CreateGroupBuy(context.Background(), 1, "hoody", 2, time.Now().Add(time.Hour))
+
+	assert.ErrorIs(t, err, services.ErrTooManyGroupBuys)
+	groupBuyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestGroupBuyService_Pledge_OutOfStockExpires(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	groupBuyRepo := new(mocks.MockGroupBuyRepository)
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	notifyRepo := new(mocks.MockNotificationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	// пока шел сбор, товар раскупили
+	groupBuy := &domain.GroupBuy{ID: 4, MerchID: 8, Item: "hoody", Quantity: 2, Currency: domain.PrimaryCurrency,
+		Target: 600, Pledged: 400, CreatorID: 1, Deadline: time.Now().Add(time.Hour), Status: domain.GroupBuyStatusOpen}
+	creator := &domain.User{ID: 1, Coins: 1000, HeldCoins: 400}
+	pledger := &domain.User{ID: 2, Coins: 500}
+	holds := map[int64]*domain.Hold{
+		30: {ID: 30, UserID: 1, Currency: domain.PrimaryCurrency, Amount: 400, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(2 * time.Hour)},
+		31: {ID: 31, UserID: 2, Currency: domain.PrimaryCurrency, Amount: 200, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(2 * time.Hour)},
+	}
+
+	groupBuyRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(groupBuy, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(creator, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(2)).Return(pledger, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+	holdRepo.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.Hold")).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Hold).ID = 31
+	}).Return(nil)
+	groupBuyRepo.On("CreatePledge", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.GroupBuyPledge")).Return(nil)
+	groupBuyRepo.On("GetPledges", mock.Anything, mock.Anything, int64(4)).Return([]*domain.GroupBuyPledge{
+		{GroupBuyID: 4, UserID: 1, Amount: 400, HoldID: 30},
+		{GroupBuyID: 4, UserID: 2, Amount: 200, HoldID: 31},
+	}, nil)
+	for id, hold := range holds {
+		holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, id).Return(hold, nil)
+		holdRepo.On("Update", mock.Anything, mock.Anything, hold).Return(nil)
+	}
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 8, 2).Return(nil, storage.ErrMerchOutOfStock)
+	groupBuyRepo.On("Update", mock.Anything, mock.Anything, groupBuy).Return(nil)
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Kind == domain.NotificationGroupBuyExpired
+	})).Return(nil).Twice()
+	groupBuyRepo.On("FindByID", mock.Anything, int64(4)).Return(groupBuy, nil)
+
+	holdService := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+	service := services.NewGroupBuyService(groupBuyRepo, merchRepo, unrestrictedMerchService(), orderRepo,
+		new(mocks.MockPurchaseRepository), notifyRepo, holdService, db, testGroupBuyPolicy)
+
+	// act
+	result, err := service.Pledge(context.Background(), 2, 4, 200)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.GroupBuyStatusExpired, result.Status)
+	assert.Equal(t, domain.HoldStatusReleased, holds[30].Status)
+	assert.Equal(t, domain.HoldStatusReleased, holds[31].Status)
+	assert.Equal(t, 1000, creator.Coins)
+	assert.Equal(t, 500, pledger.Coins)
+	orderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	notifyRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
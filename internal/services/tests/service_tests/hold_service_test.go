@@ -0,0 +1,179 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHoldService_PlaceHold_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).
+		Return(&domain.User{ID: 1, Coins: 500, HeldCoins: 300}, nil)
+	holdRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(h *domain.Hold) bool {
+		return h.UserID == 1 && h.Amount == 200 && h.Currency == domain.PrimaryCurrency &&
+			h.Status == domain.HoldStatusActive && h.ExpiresAt.After(time.Now().Add(time.Hour))
+	})).Return(nil)
+
+	service := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, 2*time.Hour)
+
+	// act
+	hold, err := service.PlaceHold(context.Background(), 1, "", 200, "hoody approval", 0)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "hoody approval", hold.Reason)
+	holdRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestHoldService_PlaceHold_InsufficientAvailable(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).
+		Return(&domain.User{ID: 1, Coins: 500, HeldCoins: 400}, nil)
+
+	service := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+
+	// act
+	_, err = service.PlaceHold(context.Background(), 1, domain.PrimaryCurrency, 200, "", 0)
+
+	// assert
+	assert.ErrorIs(t, err, services.ErrInsufficientAvailable)
+	holdRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestHoldService_Capture_Partial(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	hold := &domain.Hold{ID: 10, UserID: 1, Currency: domain.PrimaryCurrency, Amount: 300,
+		Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour)}
+	user := &domain.User{ID: 1, Coins: 500, HeldCoins: 300}
+
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(10)).Return(hold, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(user, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, user).Return(nil)
+	holdRepo.On("Update", mock.Anything, mock.Anything, hold).Return(nil)
+
+	service := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+
+	// act
+	captured, err := service.Capture(context.Background(), 10, 120)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldStatusCaptured, captured.Status)
+	assert.Equal(t, 120, captured.CapturedAmount)
+	assert.NotNil(t, captured.CapturedAt)
+	assert.Equal(t, 380, user.Coins)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestHoldService_Capture_ExpiredHold(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	holdRepo := new(mocks.MockHoldRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(10)).Return(&domain.Hold{
+		ID: 10, UserID: 1, Amount: 300, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	service := services.NewHoldService(holdRepo, userRepo, new(mocks.MockWalletRepository), db, time.Hour)
+
+	// act
+	_, err = service.Capture(context.Background(), 10, 100)
+
+	// assert
+	assert.ErrorIs(t, err, services.ErrHoldNotActive)
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestHoldService_Capture_AmountExceedsHold(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	holdRepo := new(mocks.MockHoldRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(10)).Return(&domain.Hold{
+		ID: 10, UserID: 1, Amount: 300, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+
+	service := services.NewHoldService(holdRepo, new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, time.Hour)
+
+	_, err = service.Capture(context.Background(), 10, 301)
+
+	assert.ErrorIs(t, err, services.ErrInvalidCaptureAmount)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestHoldService_Release_Success(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	holdRepo := new(mocks.MockHoldRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	hold := &domain.Hold{ID: 10, UserID: 1, Amount: 300, Status: domain.HoldStatusActive, ExpiresAt: time.Now().Add(time.Hour)}
+	holdRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(10)).Return(hold, nil)
+	holdRepo.On("Update", mock.Anything, mock.Anything, hold).Return(nil)
+
+	service := services.NewHoldService(holdRepo, new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, time.Hour)
+
+	released, err := service.Release(context.Background(), 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.HoldStatusReleased, released.Status)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -0,0 +1,602 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMerchAdminService_CreateItem_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+	priceRepo := new(mocks.MockMerchPriceRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	merchRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(m *domain.Merch) bool {
+		return m.Name == "sticker" && m.Price == 5 && m.Currency == domain.PrimaryCurrency
+	})).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Merch).ID = 11
+	}).Return(nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.MerchID == 11 && a.AdminID == 1 && a.Action == domain.MerchAuditCreate && a.NewValue == "5"
+	})).Return(nil)
+	priceRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.MerchPrice) bool {
+		return p.MerchID == 11 && p.Price == 5
+	})).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, priceRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	item, err := service.CreateItem(context.Background(), 1, "sticker", 5, "", nil)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 11, item.ID)
+	merchRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_CreateItem_Validation(t *testing.T) {
+	service := services.NewMerchAdminService(new(mocks.MockMerchRepository), new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+	_, err := service.CreateItem(context.Background(), 1, "Big Hoody", 5, "", nil)
+	assert.ErrorIs(t, err, services.ErrInvalidMerchName)
+
+	_, err = service.CreateItem(context.Background(), 1, "hoody", 0, "", nil)
+	assert.ErrorIs(t, err, services.ErrInvalidMerchPrice)
+}
+
+func TestMerchAdminService_CreateItem_NameTaken(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	merchRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(storage.ErrMerchNameTaken)
+
+	service := services.NewMerchAdminService(merchRepo, new(mocks.MockMerchAuditRepository), new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	_, err = service.CreateItem(context.Background(), 1, "cup", 20, "", nil)
+
+	assert.ErrorIs(t, err, storage.ErrMerchNameTaken)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_UpdateItem_AuditsPriceChange(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+	priceRepo := new(mocks.MockMerchPriceRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	item := &domain.Merch{ID: 3, Name: "cup", Price: 20, Currency: domain.PrimaryCurrency, Active: true}
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(item, nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.AdminID == 7 && a.Field == "price" && a.OldValue == "20" && a.NewValue == "25"
+	})).Return(nil).Once()
+	priceRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.MerchPrice) bool {
+		return p.MerchID == 3 && p.Price == 25 && p.CreatedBy == 7 && !p.EffectiveFrom.After(time.Now())
+	})).Return(nil)
+	merchRepo.On("Update", mock.Anything, mock.Anything, item).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, priceRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	price := 25
+	updated, err := service.UpdateItem(context.Background(), 7, 3, services.MerchUpdate{Price: &price})
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 25, updated.Price)
+	auditRepo.AssertExpectations(t)
+	priceRepo.AssertExpectations(t)
+	merchRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_RenameItem_RetiredItem(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(&domain.Merch{ID: 3, Name: "cup", Active: false}, nil)
+
+	service := services.NewMerchAdminService(merchRepo, new(mocks.MockMerchAuditRepository), new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	_, err = service.RenameItem(context.Background(), 7, 3, "mug")
+
+	assert.ErrorIs(t, err, services.ErrMerchRetired)
+	merchRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_RetireItem_Success(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+	priceRepo := new(mocks.MockMerchPriceRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	item := &domain.Merch{ID: 3, Name: "cup", Price: 20, Active: true}
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(item, nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.Action == domain.MerchAuditRetire
+	})).Return(nil)
+	merchRepo.On("Update", mock.Anything, mock.Anything, item).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, priceRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	retired, err := service.RetireItem(context.Background(), 7, 3)
+
+	require.NoError(t, err)
+	assert.False(t, retired.Active)
+	assert.NotNil(t, retired.RetiredAt)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_UpdateItem_Restock(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+	priceRepo := new(mocks.MockMerchPriceRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	stock := 2
+	item := &domain.Merch{ID: 3, Name: "cup", Price: 20, Stock: &stock, Active: true}
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(item, nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.Field == "stock" && a.OldValue == "2" && a.NewValue == "50"
+	})).Return(nil).Once()
+	merchRepo.On("Update", mock.Anything, mock.Anything, item).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, priceRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	restock := 50
+	updated, err := service.UpdateItem(context.Background(), 7, 3, services.MerchUpdate{Stock: &restock})
+
+	require.NoError(t, err)
+	require.NotNil(t, updated.Stock)
+	assert.Equal(t, 50, *updated.Stock)
+	auditRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_SchedulePriceChange_Success(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+	priceRepo := new(mocks.MockMerchPriceRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	effectiveFrom := time.Now().Add(48 * time.Hour)
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(&domain.Merch{ID: 3, Name: "cup", Price: 20, Active: true}, nil)
+	priceRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.MerchPrice) bool {
+		return p.MerchID == 3 && p.Price == 15 && p.EffectiveFrom.Equal(effectiveFrom)
+	})).Return(nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.Action == domain.MerchAuditSchedulePrice
+	})).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, priceRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	scheduled, err := service.SchedulePriceChange(context.Background(), 7, 3, 15, effectiveFrom)
+
+	require.NoError(t, err)
+	assert.True(t, scheduled.IsScheduled(time.Now()))
+	// текущая цена не меняется до наступления даты
+	merchRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	priceRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_SchedulePriceChange_PastDate(t *testing.T) {
+	service := services.NewMerchAdminService(new(mocks.MockMerchRepository), new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+	_, err := service.SchedulePriceChange(context.Background(), 7, 3, 15, time.Now().Add(-time.Minute))
+
+	assert.ErrorIs(t, err, services.ErrInvalidEffectiveDate)
+}
+
+func TestMerchAdminService_UpdateItem_CatalogFields(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	item := &domain.Merch{ID: 3, Name: "hoody", Price: 300, Active: true, Tags: []string{"warm"}}
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(item, nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.Field == "category" && a.NewValue == "clothes"
+	})).Return(nil).Once()
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.Field == "tags" && a.OldValue == "warm" && a.NewValue == "avito,warm"
+	})).Return(nil).Once()
+	merchRepo.On("Update", mock.Anything, mock.Anything, item).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	category := "clothes"
+	tags := []string{" Warm", "avito", "warm"}
+	updated, err := service.UpdateItem(context.Background(), 1, 3, services.MerchUpdate{Category: &category, Tags: &tags})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"avito", "warm"}, updated.Tags)
+	auditRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_UpdateItem_InvalidTags(t *testing.T) {
+	service := services.NewMerchAdminService(new(mocks.MockMerchRepository), new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+	tags := []string{"two words"}
+	_, err := service.UpdateItem(context.Background(), 1, 3, services.MerchUpdate{Tags: &tags})
+
+	assert.ErrorIs(t, err, services.ErrInvalidMerchTags)
+}
+
+func TestMerchAdminService_AddImage_UnsupportedType(t *testing.T) {
+	service := services.NewMerchAdminService(new(mocks.MockMerchRepository), new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+	_, err := service.AddImage(context.Background(), 1, 3, "text/html; charset=utf-8", strings.NewReader("<html>"))
+
+	assert.ErrorIs(t, err, services.ErrUnsupportedImage)
+}
+
+func TestMerchAdminService_AddImage_RemovesBlobOnFailure(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	blobs := new(mocks.MockBlobStore)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	retired := &domain.Merch{ID: 3, Name: "cup", Active: false}
+	merchRepo.On("FindByID", mock.Anything, 3).Return(retired, nil)
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(retired, nil)
+	var key string
+	blobs.On("Put", mock.Anything, mock.MatchedBy(func(k string) bool {
+		key = k
+		return strings.HasPrefix(k, "merch-3-") && strings.HasSuffix(k, ".png")
+	}), mock.Anything).Return(int64(4), nil)
+	blobs.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), blobs, db)
+
+	_, err = service.AddImage(context.Background(), 1, 3, "image/png", strings.NewReader("\x89PNG"))
+
+	assert.ErrorIs(t, err, services.ErrMerchRetired)
+	blobs.AssertCalled(t, "Delete", mock.Anything, key)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_UpdateItem_InvalidAttributes(t *testing.T) {
+	service := services.NewMerchAdminService(new(mocks.MockMerchRepository), new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+	attributes := map[string]string{" ": "empty key"}
+	_, err := service.UpdateItem(context.Background(), 1, 3, services.MerchUpdate{Attributes: &attributes})
+
+	assert.ErrorIs(t, err, services.ErrInvalidAttributes)
+}
+
+func TestMerchAdminService_CreateVariant_Invalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		variant *domain.MerchVariant
+	}{
+		{"bad sku", &domain.MerchVariant{SKU: "hoody xl", Size: "XL"}},
+		{"no size and color", &domain.MerchVariant{SKU: "HOODY-1"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			variantRepo := new(mocks.MockMerchVariantRepository)
+			service := services.NewMerchAdminService(new(mocks.MockMerchRepository), new(mocks.MockMerchAuditRepository),
+				new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), variantRepo, new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+			_, err := service.CreateVariant(context.Background(), 1, 3, tc.variant)
+
+			assert.ErrorIs(t, err, services.ErrInvalidVariant)
+			variantRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMerchAdminService_CreateVariant_NonPositivePrice(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	variantRepo := new(mocks.MockMerchVariantRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(&domain.Merch{ID: 3, Name: "cup", Price: 20, Active: true}, nil)
+
+	service := services.NewMerchAdminService(merchRepo, new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), variantRepo, new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	_, err = service.CreateVariant(context.Background(), 1, 3, &domain.MerchVariant{SKU: "CUP-S", Size: "S", PriceDelta: -20})
+
+	assert.ErrorIs(t, err, services.ErrInvalidVariantPrice)
+	variantRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_CreateVariant_BundleComponent(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	variantRepo := new(mocks.MockMerchVariantRepository)
+	bundleRepo := new(mocks.MockMerchBundleRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(&domain.Merch{ID: 3, Name: "cup", Price: 20, Active: true}, nil)
+	bundleRepo.On("GetBundleNames", mock.Anything, mock.Anything, 3).Return([]string{"welcome-pack"}, nil)
+
+	service := services.NewMerchAdminService(merchRepo, new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), variantRepo, new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), bundleRepo, new(mocks.MockBlobStore), db)
+
+	_, err = service.CreateVariant(context.Background(), 1, 3, &domain.MerchVariant{SKU: "CUP-S", Size: "S"})
+
+	assert.ErrorIs(t, err, services.ErrBundleUnsupported)
+	assert.Contains(t, err.Error(), "welcome-pack")
+	variantRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_SetPurchaseRule_Invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		rule *domain.MerchPurchaseRule
+	}{
+		{"empty", &domain.MerchPurchaseRule{}},
+		{"period without limit", &domain.MerchPurchaseRule{PeriodDays: 30}},
+		{"unknown role", &domain.MerchPurchaseRule{AllowedRoles: []string{"intern"}}},
+		{"bad team", &domain.MerchPurchaseRule{AllowedTeams: []string{"back end"}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ruleRepo := new(mocks.MockMerchRuleRepository)
+			service := services.NewMerchAdminService(new(mocks.MockMerchRepository), new(mocks.MockMerchAuditRepository),
+				new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository),
+				new(mocks.MockMerchVariantRepository), ruleRepo, new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+			_, err := service.SetPurchaseRule(context.Background(), 1, 3, tc.rule)
+
+			assert.ErrorIs(t, err, services.ErrInvalidPurchaseRule)
+			ruleRepo.AssertNotCalled(t, "Upsert", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMerchAdminService_SetPurchaseRule_Success(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+	ruleRepo := new(mocks.MockMerchRuleRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(&domain.Merch{ID: 3, Name: "pink-hoody", Price: 500, Active: true}, nil)
+	ruleRepo.On("Get", mock.Anything, 3).Return(nil, storage.ErrMerchRuleNotFound)
+	ruleRepo.On("Upsert", mock.Anything, mock.Anything, mock.MatchedBy(func(r *domain.MerchPurchaseRule) bool {
+		return r.MerchID == 3 && r.UpdatedBy == 1 && r.MaxQuantity == 1
+	})).Return(nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.Field == "purchase_rule" && a.OldValue == "" && a.NewValue == "max=1 period=0d min_age=0d roles= teams=backend,design"
+	})).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository),
+		new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), ruleRepo, new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), db)
+
+	rule, err := service.SetPurchaseRule(context.Background(), 1, 3, &domain.MerchPurchaseRule{
+		MaxQuantity:  1,
+		AllowedTeams: []string{" Design", "backend", "design"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "design"}, rule.AllowedTeams)
+	ruleRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_SetDrop_Invalid(t *testing.T) {
+	from := time.Now().Add(time.Hour)
+	until := from.Add(-time.Minute)
+	zero := 0
+
+	cases := []struct {
+		name string
+		drop *domain.MerchDrop
+	}{
+		{"window ends before start", &domain.MerchDrop{AvailableFrom: &from, AvailableUntil: &until}},
+		{"empty window", &domain.MerchDrop{AvailableFrom: &from, AvailableUntil: &from}},
+		{"zero cap", &domain.MerchDrop{Cap: &zero}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dropRepo := new(mocks.MockMerchDropRepository)
+			service := services.NewMerchAdminService(new(mocks.MockMerchRepository), new(mocks.MockMerchAuditRepository),
+				new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository),
+				new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), dropRepo, new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+			_, err := service.SetDrop(context.Background(), 1, 3, tc.drop)
+
+			assert.ErrorIs(t, err, services.ErrInvalidDrop)
+			dropRepo.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMerchAdminService_SetDrop_Success(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+	dropRepo := new(mocks.MockMerchDropRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	from := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	dropCap := 300
+	item := &domain.Merch{ID: 3, Name: "hoody", Price: 300, Active: true}
+	merchRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, 3).Return(item, nil)
+	merchRepo.On("FindByID", mock.Anything, 3).Return(item, nil)
+	dropRepo.On("Set", mock.Anything, mock.Anything, mock.MatchedBy(func(d *domain.MerchDrop) bool {
+		return d.MerchID == 3 && *d.Cap == 300
+	})).Return(nil)
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.Field == "drop" && a.OldValue == "" && a.NewValue == "from=2025-03-01T10:00:00Z until=- cap=300"
+	})).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository),
+		new(mocks.MockMerchImageRepository), new(mocks.MockMerchVariantRepository), new(mocks.MockMerchRuleRepository), dropRepo, new(mocks.MockMerchBundleRepository),
+		new(mocks.MockBlobStore), db)
+
+	_, err = service.SetDrop(context.Background(), 1, 3, &domain.MerchDrop{AvailableFrom: &from, Cap: &dropCap})
+
+	require.NoError(t, err)
+	dropRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_CreateBundle_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	auditRepo := new(mocks.MockMerchAuditRepository)
+	priceRepo := new(mocks.MockMerchPriceRepository)
+	bundleRepo := new(mocks.MockMerchBundleRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	merchRepo.On("FindByName", mock.Anything, "pen").Return(&domain.Merch{ID: 3, Name: "pen", Active: true}, nil)
+	merchRepo.On("FindByName", mock.Anything, "cup").Return(&domain.Merch{ID: 2, Name: "cup", Active: true}, nil)
+	merchRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(m *domain.Merch) bool {
+		return m.Name == "onboarding-pack" && m.Bundle && m.Stock == nil
+	})).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Merch).ID = 12
+	}).Return(nil)
+	bundleRepo.On("AddComponent", mock.Anything, mock.Anything, mock.MatchedBy(func(c *domain.BundleComponent) bool {
+		return c.BundleID == 12 && c.Item.ID == 2 && c.Quantity == 1
+	})).Return(nil).Once()
+	bundleRepo.On("AddComponent", mock.Anything, mock.Anything, mock.MatchedBy(func(c *domain.BundleComponent) bool {
+		return c.BundleID == 12 && c.Item.ID == 3 && c.Quantity == 2
+	})).Return(nil).Once()
+	auditRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	priceRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewMerchAdminService(merchRepo, auditRepo, priceRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), bundleRepo, new(mocks.MockBlobStore), db)
+
+	// act
+	item, err := service.CreateBundle(context.Background(), 1, "onboarding-pack", 150, "", []services.BundleComponentInput{
+		{Item: "pen", Quantity: 2},
+		{Item: "cup", Quantity: 1},
+	})
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 12, item.ID)
+	require.Len(t, item.Components, 2)
+	assert.Equal(t, "cup", item.Components[0].Item.Name)
+	auditRepo.AssertCalled(t, "Create", mock.Anything, mock.Anything, mock.MatchedBy(func(a *domain.MerchAudit) bool {
+		return a.Field == "components" && a.NewValue == "cup x1, pen x2"
+	}))
+	bundleRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchAdminService_CreateBundle_Validation(t *testing.T) {
+	merchRepo := new(mocks.MockMerchRepository)
+	merchRepo.On("FindByName", mock.Anything, "starter-pack").Return(&domain.Merch{ID: 5, Name: "starter-pack", Bundle: true, Active: true}, nil)
+
+	service := services.NewMerchAdminService(merchRepo, new(mocks.MockMerchAuditRepository),
+		new(mocks.MockMerchPriceRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), new(mocks.MockMerchRuleRepository), new(mocks.MockMerchDropRepository), new(mocks.MockMerchBundleRepository), new(mocks.MockBlobStore), nil)
+
+	_, err := service.CreateBundle(context.Background(), 1, "pack", 10, "", nil)
+	assert.ErrorIs(t, err, services.ErrInvalidBundle)
+
+	_, err = service.CreateBundle(context.Background(), 1, "pack", 10, "", []services.BundleComponentInput{
+		{Item: "pen", Quantity: 1}, {Item: "pen", Quantity: 2},
+	})
+	assert.ErrorIs(t, err, services.ErrInvalidBundle)
+
+	_, err = service.CreateBundle(context.Background(), 1, "pack", 10, "", []services.BundleComponentInput{
+		{Item: "starter-pack", Quantity: 1},
+	})
+	assert.ErrorIs(t, err, services.ErrInvalidBundle)
+}
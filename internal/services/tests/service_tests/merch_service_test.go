@@ -12,10 +12,50 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 	"time"
 )
 
+// noVariants - репозиторий вариантов для товаров без размеров и цветов.
+func noVariants() *mocks.MockMerchVariantRepository {
+	variantRepo := new(mocks.MockMerchVariantRepository)
+	variantRepo.On("GetByMerch", mock.Anything, mock.Anything).Return(nil, nil)
+	return variantRepo
+}
+
+// noRules - репозиторий правил для товаров без ограничений на покупку.
+func noRules() *mocks.MockMerchRuleRepository {
+	ruleRepo := new(mocks.MockMerchRuleRepository)
+	ruleRepo.On("GetByMerchIDs", mock.Anything, mock.Anything).Return(map[int]*domain.MerchPurchaseRule{}, nil)
+	return ruleRepo
+}
+
+// noDrops - репозиторий тиражей для товаров вне дропов: возврат в тираж у них ничего не меняет.
+func noDrops() *mocks.MockMerchDropRepository {
+	dropRepo := new(mocks.MockMerchDropRepository)
+	dropRepo.On("Take", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	dropRepo.On("Release", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	return dropRepo
+}
+
+// unrestrictedMerchService - MerchService для проверок товаров без вариантов и правил покупки.
+func unrestrictedMerchService() *services.MerchService {
+	return services.NewMerchService(new(mocks.MockMerchRepository), new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository),
+		noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+}
+
+// restrictedMerchService - MerchService, в котором у товара merchID есть правило покупки.
+func restrictedMerchService(merchID int, rule *domain.MerchPurchaseRule, userRepo *mocks.MockUserRepository,
+	purchaseRepo *mocks.MockPurchaseRepository) *services.MerchService {
+	ruleRepo := new(mocks.MockMerchRuleRepository)
+	ruleRepo.On("GetByMerchIDs", mock.Anything, []int{merchID}).Return(map[int]*domain.MerchPurchaseRule{merchID: rule}, nil)
+	return services.NewMerchService(new(mocks.MockMerchRepository), purchaseRepo, new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository),
+		noVariants(), ruleRepo, noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+}
+
 func TestMerchService_PurchaseItem_Success(t *testing.T) {
 	// arrange
 	db, mockDB, err := sqlmock.New()
@@ -24,6 +64,7 @@ func TestMerchService_PurchaseItem_Success(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -43,17 +84,19 @@ func TestMerchService_PurchaseItem_Success(t *testing.T) {
 
 	// act
 	merchRepo.On("FindByName", mock.Anything, itemName).Return(item, nil)
-	userRepo.On("FindByID", mock.Anything, userID).Return(user, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(user, nil)
 	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
 		return u.ID == userID && u.Coins == 100
 	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
 		return p.UserID == userID && p.Item == itemName && p.Price == 100
 	})).Return(nil)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
-	err = service.PurchaseItem(context.Background(), userID, itemName)
+	_, err = service.PurchaseItem(context.Background(), userID, itemName, "", 1, "")
 
 	// assert
 	assert.NoError(t, err)
@@ -71,6 +114,7 @@ func TestMerchService_PurchaseItem_InsufficientCoins(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -90,11 +134,12 @@ func TestMerchService_PurchaseItem_InsufficientCoins(t *testing.T) {
 
 	// act
 	merchRepo.On("FindByName", mock.Anything, itemName).Return(item, nil)
-	userRepo.On("FindByID", mock.Anything, userID).Return(user, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(user, nil)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
-	err = service.PurchaseItem(context.Background(), userID, itemName)
+	_, err = service.PurchaseItem(context.Background(), userID, itemName, "", 1, "")
 
 	// assert
 	assert.ErrorIs(t, err, services.ErrInsufficientCoins)
@@ -111,6 +156,7 @@ func TestMerchService_PurchaseItem_ItemNotFound(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -122,9 +168,9 @@ func TestMerchService_PurchaseItem_ItemNotFound(t *testing.T) {
 	// ACT
 	merchRepo.On("FindByName", mock.Anything, itemName).Return(nil, storage.ErrMerchNotFound)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
-	err = service.PurchaseItem(context.Background(), userID, itemName)
+	_, err = service.PurchaseItem(context.Background(), userID, itemName, "", 1, "")
 
 	// assert
 	assert.Error(t, err)
@@ -142,6 +188,7 @@ func TestMerchService_PurchaseItem_UserNotFound(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -157,11 +204,12 @@ func TestMerchService_PurchaseItem_UserNotFound(t *testing.T) {
 
 	// act
 	merchRepo.On("FindByName", mock.Anything, itemName).Return(item, nil)
-	userRepo.On("FindByID", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(nil, sql.ErrNoRows)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
-	err = service.PurchaseItem(context.Background(), userID, itemName)
+	_, err = service.PurchaseItem(context.Background(), userID, itemName, "", 1, "")
 
 	// assert
 	assert.Error(t, err)
@@ -180,6 +228,7 @@ func TestMerchService_PurchaseItem_UpdateUserError(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -200,14 +249,15 @@ func TestMerchService_PurchaseItem_UpdateUserError(t *testing.T) {
 
 	// act
 	merchRepo.On("FindByName", mock.Anything, itemName).Return(item, nil)
-	userRepo.On("FindByID", mock.Anything, userID).Return(user, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(user, nil)
 	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
 		return u.ID == userID && u.Coins == 100
 	})).Return(updateErr)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
-	err = service.PurchaseItem(context.Background(), userID, itemName)
+	_, err = service.PurchaseItem(context.Background(), userID, itemName, "", 1, "")
 
 	// assert
 	assert.Error(t, err)
@@ -227,6 +277,7 @@ func TestMerchService_PurchaseItem_CreatePurchaseError(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -247,17 +298,19 @@ func TestMerchService_PurchaseItem_CreatePurchaseError(t *testing.T) {
 
 	// act
 	merchRepo.On("FindByName", mock.Anything, itemName).Return(item, nil)
-	userRepo.On("FindByID", mock.Anything, userID).Return(user, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(user, nil)
 	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
 		return u.ID == userID && u.Coins == 100
 	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
 		return p.UserID == userID && p.Item == itemName && p.Price == 100
 	})).Return(createErr)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
-	err = service.PurchaseItem(context.Background(), userID, itemName)
+	_, err = service.PurchaseItem(context.Background(), userID, itemName, "", 1, "")
 
 	// assert
 	assert.Error(t, err)
@@ -278,6 +331,7 @@ func TestMerchService_PurchaseItem_CommitError(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -298,17 +352,19 @@ func TestMerchService_PurchaseItem_CommitError(t *testing.T) {
 
 	// act
 	merchRepo.On("FindByName", mock.Anything, itemName).Return(item, nil)
-	userRepo.On("FindByID", mock.Anything, userID).Return(user, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(user, nil)
 	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
 		return u.ID == userID && u.Coins == 100
 	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
 		return p.UserID == userID && p.Item == itemName && p.Price == 100
 	})).Return(nil)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
-	err = service.PurchaseItem(context.Background(), userID, itemName)
+	_, err = service.PurchaseItem(context.Background(), userID, itemName, "", 1, "")
 
 	// assert
 	assert.Error(t, err)
@@ -329,6 +385,7 @@ func TestMerchService_GetPurchasesByUser_Success(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -342,7 +399,7 @@ func TestMerchService_GetPurchasesByUser_Success(t *testing.T) {
 	// act
 	purchaseRepo.On("GetByUser", mock.Anything, mock.Anything, userID).Return(purchases, nil)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
 	result, err := service.GetPurchasesByUser(context.Background(), userID)
 
@@ -361,6 +418,7 @@ func TestMerchService_GetPurchasesByUser_BeginTxError(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	beginErr := errors.New("begin failed")
@@ -368,7 +426,7 @@ func TestMerchService_GetPurchasesByUser_BeginTxError(t *testing.T) {
 
 	userID := int64(1)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
 	// act
 	_, err = service.GetPurchasesByUser(context.Background(), userID)
@@ -389,6 +447,7 @@ func TestMerchService_GetPurchasesByUser_RepositoryError(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	mockDB.ExpectBegin()
@@ -399,7 +458,7 @@ func TestMerchService_GetPurchasesByUser_RepositoryError(t *testing.T) {
 	// act
 	purchaseRepo.On("GetByUser", mock.Anything, mock.Anything, userID).Return(nil, repoErr)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
 	_, err = service.GetPurchasesByUser(context.Background(), userID)
 
@@ -418,6 +477,7 @@ func TestMerchService_GetAllAvailableMerch_Success(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	merch := []*domain.Merch{
@@ -428,7 +488,7 @@ func TestMerchService_GetAllAvailableMerch_Success(t *testing.T) {
 	// act
 	merchRepo.On("GetAllAvailableMerch", mock.Anything).Return(merch, nil)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
 	result, err := service.GetAllAvailableMerch(context.Background())
 
@@ -446,6 +506,7 @@ func TestMerchService_GetAllAvailableMerch_RepositoryError(t *testing.T) {
 
 	merchRepo := new(mocks.MockMerchRepository)
 	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
 	userRepo := new(mocks.MockUserRepository)
 
 	repoErr := errors.New("repository error")
@@ -453,7 +514,7 @@ func TestMerchService_GetAllAvailableMerch_RepositoryError(t *testing.T) {
 	// act
 	merchRepo.On("GetAllAvailableMerch", mock.Anything).Return(nil, repoErr)
 
-	service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
 
 	_, err = service.GetAllAvailableMerch(context.Background())
 
@@ -480,6 +541,7 @@ func BenchmarkPurchaseItem(b *testing.B) {
 
 			merchRepo := new(mocks.MockMerchRepository)
 			purchaseRepo := new(mocks.MockPurchaseRepository)
+			orderRepo := new(mocks.MockOrderRepository)
 			userRepo := new(mocks.MockUserRepository)
 
 			merchRepo.On("FindByName", mock.Anything, itemName).Return(&domain.Merch{
@@ -487,19 +549,21 @@ func BenchmarkPurchaseItem(b *testing.B) {
 				Name:  itemName,
 				Price: 100,
 			}, nil)
-			userRepo.On("FindByID", mock.Anything, userID).Return(&domain.User{
+			merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 1, 1).Return(nil, nil)
+			userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{
 				ID:    userID,
 				Coins: 200,
 			}, nil)
 			userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
 				return u.ID == userID && u.Coins == 100
 			})).Return(nil)
+			orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
 				return p.UserID == userID && p.Item == itemName && p.Price == 100
 			})).Return(nil)
 
-			service := services.NewMerchService(merchRepo, purchaseRepo, userRepo, db)
-			err = service.PurchaseItem(context.Background(), userID, itemName)
+			service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+			_, err = service.PurchaseItem(context.Background(), userID, itemName, "", 1, "")
 			if err != nil {
 				b.Error(err)
 			}
@@ -510,3 +574,799 @@ func BenchmarkPurchaseItem(b *testing.B) {
 		}
 	})
 }
+
+func TestMerchService_PurchaseItem_EventTokenCurrency(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+	walletRepo := new(mocks.MockWalletRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	userID := int64(1)
+	item := &domain.Merch{ID: 3, Name: "event-badge", Price: 2, Currency: "event_token"}
+	wallet := &domain.Wallet{UserID: userID, Currency: "event_token", Balance: 5}
+
+	merchRepo.On("FindByName", mock.Anything, item.Name).Return(item, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, nil)
+	walletRepo.On("FindForUpdate", mock.Anything, mock.Anything, userID, "event_token").Return(wallet, nil)
+	walletRepo.On("Update", mock.Anything, mock.Anything, wallet).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.Currency == "event_token" && p.Price == 2
+	})).Return(nil)
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, walletRepo, new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	_, err = service.PurchaseItem(context.Background(), userID, item.Name, "", 1, "")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, wallet.Balance)
+	userRepo.AssertNotCalled(t, "FindByIDForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	walletRepo.AssertExpectations(t)
+	purchaseRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_HeldCoinsNotSpendable(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	userID := int64(1)
+	item := &domain.Merch{ID: 1, Name: "hoody", Price: 300}
+
+	merchRepo.On("FindByName", mock.Anything, item.Name).Return(item, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).
+		Return(&domain.User{ID: userID, Coins: 400, HeldCoins: 200}, nil)
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	_, err = service.PurchaseItem(context.Background(), userID, item.Name, "", 1, "")
+
+	// assert
+	assert.ErrorIs(t, err, services.ErrInsufficientCoins)
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_OutOfStock(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	userID := int64(1)
+	item := &domain.Merch{ID: 4, Name: "hoody", Price: 300}
+
+	merchRepo.On("FindByName", mock.Anything, item.Name).Return(item, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 1).Return(nil, storage.ErrMerchOutOfStock)
+
+	service := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository), new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	_, err = service.PurchaseItem(context.Background(), userID, item.Name, "", 1, "")
+
+	// assert
+	assert.ErrorIs(t, err, services.ErrOutOfStock)
+	userRepo.AssertNotCalled(t, "FindByIDForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_Quantity(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	userID := int64(1)
+	stock := 10
+	item := &domain.Merch{ID: 2, Name: "cup", Price: 20, Stock: &stock}
+	remaining := 7
+
+	merchRepo.On("FindByName", mock.Anything, item.Name).Return(item, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 3).Return(&remaining, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{ID: userID, Coins: 100}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Coins == 40
+	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Order).ID = 15
+	}).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.OrderID == 15 && p.Quantity == 3 && p.Price == 60 && p.Currency == domain.PrimaryCurrency
+	})).Return(nil)
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	order, err := service.PurchaseItem(context.Background(), userID, item.Name, "", 3, "")
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), order.ID)
+	assert.Equal(t, map[string]int{domain.PrimaryCurrency: 60}, order.Totals())
+	assert.Equal(t, map[string]int{"cup": 7}, order.StockLeft)
+	merchRepo.AssertExpectations(t)
+	purchaseRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_InvalidQuantity(t *testing.T) {
+	service := services.NewMerchService(new(mocks.MockMerchRepository), new(mocks.MockPurchaseRepository),
+		new(mocks.MockOrderRepository), new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+
+	_, err := service.PurchaseItem(context.Background(), 1, "cup", "", 0, "")
+
+	assert.ErrorIs(t, err, services.ErrInvalidQuantity)
+}
+
+func TestMerchService_PurchaseItem_PromoCode(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	promoRepo := new(mocks.MockPromoCodeRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	userID := int64(1)
+	item := &domain.Merch{ID: 2, Name: "cup", Price: 20, Currency: domain.PrimaryCurrency}
+	perUser := 1
+	promo := &domain.PromoCode{ID: 7, Code: "WELCOME", Kind: domain.PromoKindPercent, Value: 25,
+		Currency: domain.PrimaryCurrency, MinSpend: 40, MaxUsesPerUser: &perUser, Active: true}
+
+	merchRepo.On("FindByName", mock.Anything, item.Name).Return(item, nil)
+	promoRepo.On("FindByCodeForUpdate", mock.Anything, mock.Anything, "WELCOME").Return(promo, nil)
+	promoRepo.On("CountUserRedemptions", mock.Anything, mock.Anything, promo.ID, userID).Return(0, nil)
+	promoRepo.On("IncrementUses", mock.Anything, mock.Anything, promo.ID).Return(nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, item.ID, 3).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{ID: userID, Coins: 100}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Coins == 55
+	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Order).ID = 15
+	}).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.UnitPrice == 20 && p.Price == 45 && p.Discount == 15 && p.PromoCodeID == promo.ID
+	})).Return(nil)
+	promoRepo.On("CreateRedemption", mock.Anything, mock.Anything, mock.MatchedBy(func(r *domain.PromoRedemption) bool {
+		return r.PromoCodeID == promo.ID && r.OrderID == 15 && r.Discount == 15
+	})).Return(nil)
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, promoRepo, userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	order, err := service.PurchaseItem(context.Background(), userID, item.Name, "", 3, " welcome ")
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{domain.PrimaryCurrency: 45}, order.Totals())
+	promoRepo.AssertExpectations(t)
+	purchaseRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_PromoCodeRejected(t *testing.T) {
+	item := &domain.Merch{ID: 2, Name: "cup", Price: 20, Currency: domain.PrimaryCurrency}
+	past := time.Now().Add(-time.Hour)
+	one := 1
+
+	tests := []struct {
+		name        string
+		promo       *domain.PromoCode
+		redemptions int
+		wantErr     error
+	}{
+		{
+			name:    "expired",
+			promo:   &domain.PromoCode{ID: 1, Kind: domain.PromoKindFixed, Value: 5, Currency: domain.PrimaryCurrency, Active: true, ValidUntil: &past},
+			wantErr: services.ErrPromoCodeExpired,
+		},
+		{
+			name:    "global limit",
+			promo:   &domain.PromoCode{ID: 1, Kind: domain.PromoKindFixed, Value: 5, Currency: domain.PrimaryCurrency, Active: true, MaxUses: &one, Uses: 1},
+			wantErr: services.ErrPromoCodeExhausted,
+		},
+		{
+			name:        "per user limit",
+			promo:       &domain.PromoCode{ID: 1, Kind: domain.PromoKindFixed, Value: 5, Currency: domain.PrimaryCurrency, Active: true, MaxUsesPerUser: &one},
+			redemptions: 1,
+			wantErr:     services.ErrPromoCodeUserLimit,
+		},
+		{
+			name:    "other items",
+			promo:   &domain.PromoCode{ID: 1, Kind: domain.PromoKindFixed, Value: 5, Currency: domain.PrimaryCurrency, Active: true, MerchIDs: []int{9}, Categories: []string{"clothes"}},
+			wantErr: services.ErrPromoCodeNotApplicable,
+		},
+		{
+			name:    "min spend",
+			promo:   &domain.PromoCode{ID: 1, Kind: domain.PromoKindFixed, Value: 5, Currency: domain.PrimaryCurrency, Active: true, MinSpend: 100},
+			wantErr: services.ErrPromoCodeMinSpend,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mockDB, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mockDB.ExpectBegin()
+			mockDB.ExpectRollback()
+
+			merchRepo := new(mocks.MockMerchRepository)
+			promoRepo := new(mocks.MockPromoCodeRepository)
+			merchRepo.On("FindByName", mock.Anything, item.Name).Return(item, nil)
+			promoRepo.On("FindByCodeForUpdate", mock.Anything, mock.Anything, "SALE").Return(tt.promo, nil)
+			promoRepo.On("CountUserRedemptions", mock.Anything, mock.Anything, tt.promo.ID, int64(1)).Return(tt.redemptions, nil)
+
+			service := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+				promoRepo, new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+			_, err = service.PurchaseItem(context.Background(), 1, item.Name, "", 1, "sale")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			merchRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			promoRepo.AssertNotCalled(t, "IncrementUses", mock.Anything, mock.Anything, mock.Anything)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMerchService_SearchMerch_Defaults(t *testing.T) {
+	merchRepo := new(mocks.MockMerchRepository)
+	page := &domain.MerchPage{Items: []*domain.Merch{{ID: 1, Name: "cup"}}, Total: 1}
+	merchRepo.On("Search", mock.Anything, domain.MerchFilter{
+		Query:  "cup",
+		Tags:   []string{"kitchen"},
+		Sort:   domain.MerchSortRelevance,
+		Limit:  services.MaxMerchPageSize,
+		Offset: 0,
+	}).Return(page, nil)
+
+	service := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+
+	result, err := service.SearchMerch(context.Background(), domain.MerchFilter{Query: " cup ", Tags: []string{"Kitchen"}, Limit: 500})
+
+	require.NoError(t, err)
+	assert.Equal(t, page, result)
+	merchRepo.AssertExpectations(t)
+}
+
+func TestMerchService_SearchMerch_Validation(t *testing.T) {
+	service := services.NewMerchService(new(mocks.MockMerchRepository), new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+
+	_, err := service.SearchMerch(context.Background(), domain.MerchFilter{Sort: "cheapest"})
+	assert.ErrorIs(t, err, services.ErrInvalidMerchSort)
+
+	minPrice, maxPrice := 100, 10
+	_, err = service.SearchMerch(context.Background(), domain.MerchFilter{MinPrice: &minPrice, MaxPrice: &maxPrice})
+	assert.ErrorIs(t, err, services.ErrInvalidPriceRange)
+}
+
+func TestMerchService_GetItem(t *testing.T) {
+	merchRepo := new(mocks.MockMerchRepository)
+	imageRepo := new(mocks.MockMerchImageRepository)
+	blobs := new(mocks.MockBlobStore)
+
+	item := &domain.Merch{ID: 4, Name: "hoody", Price: 300, Active: true, Attributes: map[string]string{"material": "cotton"}}
+	merchRepo.On("FindByID", mock.Anything, 4).Return(item, nil)
+	merchRepo.On("FindByID", mock.Anything, 5).Return(&domain.Merch{ID: 5, Name: "old", Active: false}, nil)
+	imageRepo.On("GetByMerch", mock.Anything, 4).Return([]*domain.MerchImage{{ID: 1, MerchID: 4, Key: "merch-4-a.png"}}, nil)
+	blobs.On("URL", "merch-4-a.png").Return("/media/merch-4-a.png")
+
+	service := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), imageRepo, noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), blobs, nil)
+
+	result, err := service.GetItem(context.Background(), 4)
+	require.NoError(t, err)
+	require.Len(t, result.Images, 1)
+	assert.Equal(t, "/media/merch-4-a.png", result.Images[0].URL)
+
+	_, err = service.GetItem(context.Background(), 5)
+	assert.ErrorIs(t, err, storage.ErrMerchNotFound)
+}
+
+func TestMerchService_PurchaseItem_Variant(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+	variantRepo := new(mocks.MockMerchVariantRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	userID := int64(1)
+	item := &domain.Merch{ID: 1, Name: "hoody", Price: 300, Active: true}
+	variant := &domain.MerchVariant{ID: 5, MerchID: 1, SKU: "HOODY-XL-BLACK", Size: "XL", Color: "black", PriceDelta: 50, Active: true}
+
+	merchRepo.On("FindByName", mock.Anything, "hoody").Return(item, nil)
+	variantRepo.On("FindBySKU", mock.Anything, "HOODY-XL-BLACK").Return(variant, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 1, 2).Return(nil, nil)
+	variantRepo.On("DecrementStock", mock.Anything, mock.Anything, 5, 2).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{ID: userID, Coins: 1000}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Coins == 300
+	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.Price == 700 && p.UnitPrice == 350 && p.VariantID == 5 && p.SKU == "HOODY-XL-BLACK"
+	})).Return(nil)
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), variantRepo, noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	_, err = service.PurchaseItem(context.Background(), userID, "hoody", "HOODY-XL-BLACK", 2, "")
+
+	// assert
+	require.NoError(t, err)
+	variantRepo.AssertExpectations(t)
+	purchaseRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_VariantRequired(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	variantRepo := new(mocks.MockMerchVariantRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	item := &domain.Merch{ID: 1, Name: "hoody", Price: 300, Active: true}
+	merchRepo.On("FindByName", mock.Anything, "hoody").Return(item, nil)
+	variantRepo.On("GetByMerch", mock.Anything, 1).Return([]*domain.MerchVariant{
+		{ID: 5, MerchID: 1, SKU: "HOODY-XL-BLACK", Active: true},
+	}, nil)
+
+	service := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), variantRepo, noRules(), noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	_, err = service.PurchaseItem(context.Background(), 1, "hoody", "", 1, "")
+
+	assert.ErrorIs(t, err, services.ErrVariantRequired)
+	merchRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_RuleRejected(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name     string
+		rule     *domain.MerchPurchaseRule
+		user     *domain.User
+		bought   int
+		expected error
+		reason   string
+	}{
+		{
+			name:     "limit per period",
+			rule:     &domain.MerchPurchaseRule{MerchID: 1, MaxQuantity: 1, PeriodDays: 30},
+			user:     &domain.User{ID: 1, Role: domain.RoleEmployee, CreatedAt: now.AddDate(-1, 0, 0)},
+			bought:   1,
+			expected: services.ErrPurchaseLimitExceeded,
+			reason:   "limited to 1 per user per 30 days, already bought 1",
+		},
+		{
+			name:     "account too new",
+			rule:     &domain.MerchPurchaseRule{MerchID: 1, MinAccountAgeDays: 90},
+			user:     &domain.User{ID: 1, Role: domain.RoleEmployee, CreatedAt: now.AddDate(0, 0, -10)},
+			expected: services.ErrAccountTooNew,
+			reason:   "at least 90 days old",
+		},
+		{
+			name:     "role",
+			rule:     &domain.MerchPurchaseRule{MerchID: 1, AllowedRoles: []string{domain.RoleAdmin}},
+			user:     &domain.User{ID: 1, Role: domain.RoleEmployee, CreatedAt: now},
+			expected: services.ErrPurchaseRestricted,
+			reason:   "only for roles: admin",
+		},
+		{
+			name:     "no team",
+			rule:     &domain.MerchPurchaseRule{MerchID: 1, AllowedTeams: []string{"backend"}},
+			user:     &domain.User{ID: 1, Role: domain.RoleEmployee, CreatedAt: now},
+			expected: services.ErrPurchaseRestricted,
+			reason:   "only for teams: backend",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mockDB, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			merchRepo := new(mocks.MockMerchRepository)
+			purchaseRepo := new(mocks.MockPurchaseRepository)
+			userRepo := new(mocks.MockUserRepository)
+			ruleRepo := new(mocks.MockMerchRuleRepository)
+			mockDB.ExpectBegin()
+			mockDB.ExpectRollback()
+
+			item := &domain.Merch{ID: 1, Name: "pink-hoody", Price: 500, Active: true}
+			merchRepo.On("FindByName", mock.Anything, "pink-hoody").Return(item, nil)
+			merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 1, 1).Return(nil, nil)
+			ruleRepo.On("GetByMerchIDs", mock.Anything, []int{1}).Return(map[int]*domain.MerchPurchaseRule{1: tc.rule}, nil)
+			userRepo.On("FindByID", mock.Anything, int64(1)).Return(tc.user, nil)
+			purchaseRepo.On("CountUserItem", mock.Anything, mock.Anything, int64(1), 1, mock.Anything).Return(tc.bought, nil)
+
+			service := services.NewMerchService(merchRepo, purchaseRepo, new(mocks.MockOrderRepository), new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), ruleRepo, noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+			_, err = service.PurchaseItem(context.Background(), 1, "pink-hoody", "", 1, "")
+
+			require.ErrorIs(t, err, tc.expected)
+			assert.Contains(t, err.Error(), tc.reason)
+			userRepo.AssertNotCalled(t, "FindByIDForUpdate", mock.Anything, mock.Anything, mock.Anything)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMerchService_PurchaseItem_WithinRuleLimit(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+	ruleRepo := new(mocks.MockMerchRuleRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	user := &domain.User{ID: 1, Coins: 1000, Role: domain.RoleEmployee, Team: "backend", CreatedAt: time.Now().AddDate(-1, 0, 0)}
+	item := &domain.Merch{ID: 1, Name: "pink-hoody", Price: 500, Active: true}
+	merchRepo.On("FindByName", mock.Anything, "pink-hoody").Return(item, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 1, 1).Return(nil, nil)
+	ruleRepo.On("GetByMerchIDs", mock.Anything, []int{1}).Return(map[int]*domain.MerchPurchaseRule{
+		1: {MerchID: 1, MaxQuantity: 2, PeriodDays: 30, MinAccountAgeDays: 90, AllowedTeams: []string{"backend"}},
+	}, nil)
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(user, nil)
+	purchaseRepo.On("CountUserItem", mock.Anything, mock.Anything, int64(1), 1, mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) > 29*24*time.Hour && time.Since(since) < 31*24*time.Hour
+	})).Return(1, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(user, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), ruleRepo, noDrops(), new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	_, err = service.PurchaseItem(context.Background(), 1, "pink-hoody", "", 1, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, 500, user.Coins)
+	purchaseRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_GiftItem_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+	notifyRepo := new(mocks.MockNotificationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	buyer := &domain.User{ID: 1, Username: "alice", Coins: 100}
+	recipient := &domain.User{ID: 2, Username: "bob", Coins: 50}
+	item := &domain.Merch{ID: 3, Name: "cup", Price: 20, Active: true}
+
+	userRepo.On("FindByUsername", mock.Anything, "bob").Return(recipient, nil)
+	merchRepo.On("FindByName", mock.Anything, "cup").Return(item, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 3, 1).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(buyer, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.ID == 1 && u.Coins == 80
+	})).Return(nil)
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(buyer, nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(o *domain.Order) bool {
+		return o.UserID == 1
+	})).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Order).ID = 7
+	}).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.UserID == 2 && p.GiftedBy == 1 && p.GiftMessage == "С днем рождения!" && p.Price == 20
+	})).Return(nil)
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.UserID == 2 && n.Kind == domain.NotificationGiftReceived &&
+			n.Message == "alice gifted you cup: С днем рождения!" && n.Data["orderId"] == "7"
+	})).Return(nil)
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository), notifyRepo, new(mocks.MockBlobStore), db)
+
+	// act
+	order, err := service.GiftItem(context.Background(), 1, "bob", "cup", "", 1, "  С днем рождения! ", "")
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "bob", order.Purchases[0].GiftTo)
+	assert.Equal(t, 50, recipient.Coins)
+	purchaseRepo.AssertExpectations(t)
+	notifyRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_GiftItem_Rejected(t *testing.T) {
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("FindByUsername", mock.Anything, "alice").Return(&domain.User{ID: 1, Username: "alice"}, nil)
+	userRepo.On("FindByUsername", mock.Anything, "nobody").Return(nil, storage.ErrUserNotFound)
+
+	service := services.NewMerchService(new(mocks.MockMerchRepository), new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+		new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), new(mocks.MockMerchBundleRepository),
+		new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), nil)
+
+	_, err := service.GiftItem(context.Background(), 1, "alice", "cup", "", 1, "", "")
+	assert.ErrorIs(t, err, services.ErrGiftToSelf)
+
+	_, err = service.GiftItem(context.Background(), 1, "nobody", "cup", "", 1, "", "")
+	assert.ErrorIs(t, err, services.ErrGiftRecipientNotFound)
+
+	_, err = service.GiftItem(context.Background(), 1, "bob", "cup", "", 1, strings.Repeat("я", 501), "")
+	assert.ErrorIs(t, err, services.ErrInvalidGiftMessage)
+}
+
+func TestMerchService_PurchaseItem_Drop(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+	dropRepo := new(mocks.MockMerchDropRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	start := time.Now().Add(-time.Minute)
+	dropCap, dropLeft := 100, 40
+	item := &domain.Merch{ID: 7, Name: "hackathon-hoody", Price: 50, Active: true, AvailableFrom: &start,
+		DropCap: &dropCap, DropLeft: &dropLeft}
+
+	merchRepo.On("FindByName", mock.Anything, item.Name).Return(item, nil)
+	dropRepo.On("Take", mock.Anything, mock.Anything, item.ID, 2).Return(nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(&domain.User{ID: 1, Coins: 200}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Coins == 100
+	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo,
+		new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), dropRepo, new(mocks.MockMerchBundleRepository),
+		new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	_, err = service.PurchaseItem(context.Background(), 1, item.Name, "", 2, "")
+
+	// assert
+	require.NoError(t, err)
+	dropRepo.AssertExpectations(t)
+	// тираж без собственного остатка не блокирует строку товара
+	merchRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_DropRejected(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	dropCap := 10
+
+	cases := []struct {
+		name    string
+		item    *domain.Merch
+		soldOut bool
+		want    error
+	}{
+		{
+			name: "not started",
+			item: &domain.Merch{ID: 7, Name: "badge", Price: 5, Active: true, AvailableFrom: &future},
+			want: services.ErrNotOnSaleYet,
+		},
+		{
+			name: "ended",
+			item: &domain.Merch{ID: 7, Name: "badge", Price: 5, Active: true, AvailableFrom: &past, AvailableUntil: &past},
+			want: services.ErrSaleEnded,
+		},
+		{
+			name:    "sold out",
+			item:    &domain.Merch{ID: 7, Name: "badge", Price: 5, Active: true, DropCap: &dropCap},
+			soldOut: true,
+			want:    services.ErrOutOfStock,
+		},
+		{
+			// тираж задали после того, как товар попал в кэш каталога
+			name:    "cap missing in cache",
+			item:    &domain.Merch{ID: 7, Name: "badge", Price: 5, Active: true},
+			soldOut: true,
+			want:    services.ErrOutOfStock,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mockDB, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			merchRepo := new(mocks.MockMerchRepository)
+			userRepo := new(mocks.MockUserRepository)
+			dropRepo := new(mocks.MockMerchDropRepository)
+
+			mockDB.ExpectBegin()
+			mockDB.ExpectRollback()
+
+			merchRepo.On("FindByName", mock.Anything, tc.item.Name).Return(tc.item, nil)
+			merchRepo.On("DecrementStock", mock.Anything, mock.Anything, tc.item.ID, 1).Return(nil, nil).Maybe()
+			if tc.soldOut {
+				dropRepo.On("Take", mock.Anything, mock.Anything, tc.item.ID, 1).Return(storage.ErrDropSoldOut)
+			}
+
+			service := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository),
+				new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository),
+				noVariants(), noRules(), dropRepo, new(mocks.MockMerchBundleRepository), new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+			_, err = service.PurchaseItem(context.Background(), 1, tc.item.Name, "", 1, "")
+
+			assert.ErrorIs(t, err, tc.want)
+			userRepo.AssertNotCalled(t, "FindByIDForUpdate", mock.Anything, mock.Anything, mock.Anything)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMerchService_PurchaseItem_Bundle(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	userRepo := new(mocks.MockUserRepository)
+	bundleRepo := new(mocks.MockMerchBundleRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	stock := 5
+	bundle := &domain.Merch{ID: 10, Name: "onboarding-pack", Price: 150, Bundle: true, Stock: &stock, Active: true}
+	shirt := &domain.Merch{ID: 1, Name: "t-shirt", Price: 80, Stock: &stock, Active: true}
+	pen := &domain.Merch{ID: 3, Name: "pen", Price: 10, Active: true}
+
+	merchRepo.On("FindByName", mock.Anything, "onboarding-pack").Return(bundle, nil)
+	bundleRepo.On("GetComponents", mock.Anything, mock.Anything, 10).Return([]*domain.BundleComponent{
+		{BundleID: 10, Item: shirt, Quantity: 1},
+		{BundleID: 10, Item: pen, Quantity: 2},
+	}, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 1, 2).Return(nil, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 3, 4).Return(nil, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(&domain.User{ID: 1, Coins: 300}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Coins == 0
+	})).Return(nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.MerchID == 10 && p.Quantity == 2 && p.Price == 300 && p.BundlePurchaseID == 0
+	})).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.Purchase).ID = 7
+	}).Return(nil).Once()
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.MerchID == 1 && p.Item == "t-shirt" && p.Quantity == 2 && p.Price == 0 && p.BundlePurchaseID == 7
+	})).Return(nil).Once()
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.MerchID == 3 && p.Item == "pen" && p.Quantity == 4 && p.Price == 0 && p.BundlePurchaseID == 7
+	})).Return(nil).Once()
+
+	service := services.NewMerchService(merchRepo, purchaseRepo, orderRepo, new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), bundleRepo, new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	// act
+	order, err := service.PurchaseItem(context.Background(), 1, "onboarding-pack", "", 2, "")
+
+	// assert
+	require.NoError(t, err)
+	assert.Len(t, order.Purchases, 3)
+	merchRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, 10, mock.Anything)
+	merchRepo.AssertExpectations(t)
+	purchaseRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestMerchService_PurchaseItem_BundleComponentOutOfStock(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	merchRepo := new(mocks.MockMerchRepository)
+	userRepo := new(mocks.MockUserRepository)
+	bundleRepo := new(mocks.MockMerchBundleRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	bundle := &domain.Merch{ID: 10, Name: "onboarding-pack", Price: 150, Bundle: true, Active: true}
+	cup := &domain.Merch{ID: 2, Name: "cup", Price: 20, Active: true}
+
+	merchRepo.On("FindByName", mock.Anything, "onboarding-pack").Return(bundle, nil)
+	bundleRepo.On("GetComponents", mock.Anything, mock.Anything, 10).Return([]*domain.BundleComponent{
+		{BundleID: 10, Item: cup, Quantity: 1},
+	}, nil)
+	merchRepo.On("DecrementStock", mock.Anything, mock.Anything, 2, 1).Return(nil, storage.ErrMerchOutOfStock)
+
+	service := services.NewMerchService(merchRepo, new(mocks.MockPurchaseRepository), new(mocks.MockOrderRepository), new(mocks.MockPromoCodeRepository), userRepo, new(mocks.MockWalletRepository), new(mocks.MockMerchImageRepository), noVariants(), noRules(), noDrops(), bundleRepo, new(mocks.MockNotificationRepository), new(mocks.MockBlobStore), db)
+
+	_, err = service.PurchaseItem(context.Background(), 1, "onboarding-pack", "", 1, "")
+
+	assert.ErrorIs(t, err, services.ErrOutOfStock)
+	assert.Contains(t, err.Error(), "cup")
+	userRepo.AssertNotCalled(t, "FindByIDForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
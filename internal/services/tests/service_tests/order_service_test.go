@@ -0,0 +1,308 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newOrderService(orderRepo *mocks.MockOrderRepository, db *sql.DB) *services.OrderService {
+	return services.NewOrderService(orderRepo, new(mocks.MockPurchaseRepository), new(mocks.MockRefundRepository),
+		new(mocks.MockMerchRepository), new(mocks.MockMerchVariantRepository), noDrops(), new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, 0)
+}
+
+func TestOrderService_TransitionOrder_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orderRepo := new(mocks.MockOrderRepository)
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	order := &domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusPacked}
+	orderRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(order, nil)
+	orderRepo.On("UpdateStatus", mock.Anything, mock.Anything, order, mock.MatchedBy(func(c *domain.OrderStatusChange) bool {
+		return c.FromStatus == domain.OrderStatusPacked && c.ToStatus == domain.OrderStatusReadyForPickup &&
+			c.ChangedBy == 7 && !c.CreatedAt.IsZero()
+	})).Return(nil)
+	orderRepo.On("FindByID", mock.Anything, int64(4)).
+		Return(&domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusReadyForPickup}, nil)
+
+	service := newOrderService(orderRepo, db)
+
+	// act
+	updated, err := service.TransitionOrder(context.Background(), 7, 4, domain.OrderStatusReadyForPickup)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusReadyForPickup, updated.Status)
+	orderRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestOrderService_TransitionOrder_NotAllowed(t *testing.T) {
+	cases := []struct {
+		from string
+		to   string
+	}{
+		{domain.OrderStatusPlaced, domain.OrderStatusShipped},
+		{domain.OrderStatusShipped, domain.OrderStatusCancelled},
+		{domain.OrderStatusDelivered, domain.OrderStatusPlaced},
+		{domain.OrderStatusCancelled, domain.OrderStatusApproved},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.from+"->"+tc.to, func(t *testing.T) {
+			db, mockDB, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			orderRepo := new(mocks.MockOrderRepository)
+			mockDB.ExpectBegin()
+			mockDB.ExpectRollback()
+
+			orderRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).
+				Return(&domain.Order{ID: 4, Status: tc.from}, nil)
+
+			service := newOrderService(orderRepo, db)
+
+			_, err = service.TransitionOrder(context.Background(), 7, 4, tc.to)
+
+			assert.ErrorIs(t, err, services.ErrInvalidOrderTransition)
+			orderRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOrderService_TransitionOrder_UnknownStatus(t *testing.T) {
+	service := newOrderService(new(mocks.MockOrderRepository), nil)
+
+	_, err := service.TransitionOrder(context.Background(), 7, 4, "lost")
+
+	assert.ErrorIs(t, err, services.ErrUnknownOrderStatus)
+}
+
+func TestOrderService_GetUserOrder_ForeignOrder(t *testing.T) {
+	orderRepo := new(mocks.MockOrderRepository)
+	orderRepo.On("FindByID", mock.Anything, int64(4)).Return(&domain.Order{ID: 4, UserID: 2}, nil)
+
+	service := newOrderService(orderRepo, nil)
+
+	_, err := service.GetUserOrder(context.Background(), 1, 4)
+
+	assert.ErrorIs(t, err, storage.ErrOrderNotFound)
+}
+
+func TestOrderService_CancelOrder_RefundsPaidPrice(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orderRepo := new(mocks.MockOrderRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	refundRepo := new(mocks.MockRefundRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	userID := int64(1)
+	order := &domain.Order{ID: 4, UserID: userID, Status: domain.OrderStatusApproved, CreatedAt: time.Now().Add(-time.Hour)}
+	orderRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(order, nil)
+	// цена товара с тех пор могла измениться, возвращается сумма из purchases.price
+	purchaseRepo.On("GetByOrder", mock.Anything, mock.Anything, int64(4)).Return([]*domain.Purchase{
+		{ID: 10, UserID: userID, OrderID: 4, MerchID: 2, Item: "cup", Quantity: 3, Price: 60, Currency: domain.PrimaryCurrency},
+	}, nil)
+	merchRepo.On("IncrementStock", mock.Anything, mock.Anything, 2, 3).Return(nil)
+	refundRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(r *domain.Refund) bool {
+		return r.PurchaseID == 10 && r.Amount == 60 && r.UserID == userID
+	})).Return(nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{ID: userID, Coins: 40}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Coins == 100
+	})).Return(nil)
+	orderRepo.On("UpdateStatus", mock.Anything, mock.Anything, order, mock.MatchedBy(func(c *domain.OrderStatusChange) bool {
+		return c.ToStatus == domain.OrderStatusCancelled && c.ChangedBy == userID
+	})).Return(nil)
+	orderRepo.On("FindByID", mock.Anything, int64(4)).Return(&domain.Order{ID: 4, UserID: userID, Status: domain.OrderStatusCancelled}, nil)
+
+	service := services.NewOrderService(orderRepo, purchaseRepo, refundRepo, merchRepo, new(mocks.MockMerchVariantRepository), noDrops(), new(mocks.MockPromoCodeRepository), userRepo,
+		new(mocks.MockWalletRepository), db, 24*time.Hour)
+
+	// act
+	cancelled, err := service.CancelOrder(context.Background(), userID, 4)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderStatusCancelled, cancelled.Status)
+	merchRepo.AssertExpectations(t)
+	refundRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	orderRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestOrderService_CancelOrder_RestoresVariantStock(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orderRepo := new(mocks.MockOrderRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	variantRepo := new(mocks.MockMerchVariantRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	order := &domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusPlaced, CreatedAt: time.Now()}
+	orderRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(order, nil)
+	// бесплатная покупка (100% промокод) - возвращается только остаток
+	purchaseRepo.On("GetByOrder", mock.Anything, mock.Anything, int64(4)).Return([]*domain.Purchase{
+		{ID: 10, UserID: 1, OrderID: 4, MerchID: 2, VariantID: 7, SKU: "HOODY-XL", Item: "hoody", Quantity: 2, Currency: domain.PrimaryCurrency},
+	}, nil)
+	merchRepo.On("IncrementStock", mock.Anything, mock.Anything, 2, 2).Return(nil)
+	variantRepo.On("IncrementStock", mock.Anything, mock.Anything, 7, 2).Return(nil)
+	orderRepo.On("UpdateStatus", mock.Anything, mock.Anything, order, mock.Anything).Return(nil)
+	orderRepo.On("FindByID", mock.Anything, int64(4)).Return(&domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusCancelled}, nil)
+
+	service := services.NewOrderService(orderRepo, purchaseRepo, new(mocks.MockRefundRepository), merchRepo, variantRepo, noDrops(), new(mocks.MockPromoCodeRepository),
+		new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, 24*time.Hour)
+
+	_, err = service.CancelOrder(context.Background(), 1, 4)
+
+	require.NoError(t, err)
+	merchRepo.AssertExpectations(t)
+	variantRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestOrderService_CancelOrder_ReleasesPromoCode(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	orderRepo := new(mocks.MockOrderRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	refundRepo := new(mocks.MockRefundRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	promoRepo := new(mocks.MockPromoCodeRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	order := &domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusPlaced, CreatedAt: time.Now()}
+	orderRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(order, nil)
+	purchaseRepo.On("GetByOrder", mock.Anything, mock.Anything, int64(4)).Return([]*domain.Purchase{
+		{ID: 10, UserID: 1, OrderID: 4, MerchID: 2, Item: "cup", Quantity: 1, Price: 15, Discount: 5, PromoCodeID: 3, Currency: domain.PrimaryCurrency},
+	}, nil)
+	promoRepo.On("ReleaseRedemption", mock.Anything, mock.Anything, int64(4)).Return(nil).Once()
+	merchRepo.On("IncrementStock", mock.Anything, mock.Anything, 2, 1).Return(nil)
+	refundRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(&domain.User{ID: 1}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	orderRepo.On("UpdateStatus", mock.Anything, mock.Anything, order, mock.Anything).Return(nil)
+	orderRepo.On("FindByID", mock.Anything, int64(4)).Return(&domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusCancelled}, nil)
+
+	service := services.NewOrderService(orderRepo, purchaseRepo, refundRepo, merchRepo, new(mocks.MockMerchVariantRepository), noDrops(),
+		promoRepo, userRepo, new(mocks.MockWalletRepository), db, 24*time.Hour)
+
+	_, err = service.CancelOrder(context.Background(), 1, 4)
+
+	require.NoError(t, err)
+	promoRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestOrderService_CancelOrder_Rejected(t *testing.T) {
+	cases := []struct {
+		name     string
+		order    *domain.Order
+		expected error
+	}{
+		{"shipped", &domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusShipped, CreatedAt: time.Now()}, services.ErrOrderNotCancellable},
+		{"window expired", &domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusPlaced, CreatedAt: time.Now().Add(-48 * time.Hour)}, services.ErrCancellationWindowExpired},
+		{"foreign order", &domain.Order{ID: 4, UserID: 2, Status: domain.OrderStatusPlaced, CreatedAt: time.Now()}, storage.ErrOrderNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mockDB, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			orderRepo := new(mocks.MockOrderRepository)
+			purchaseRepo := new(mocks.MockPurchaseRepository)
+			mockDB.ExpectBegin()
+			mockDB.ExpectRollback()
+
+			orderRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(tc.order, nil)
+
+			service := services.NewOrderService(orderRepo, purchaseRepo, new(mocks.MockRefundRepository),
+				new(mocks.MockMerchRepository), new(mocks.MockMerchVariantRepository), noDrops(), new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, 24*time.Hour)
+
+			_, err = service.CancelOrder(context.Background(), 1, 4)
+
+			assert.ErrorIs(t, err, tc.expected)
+			purchaseRepo.AssertNotCalled(t, "GetByOrder", mock.Anything, mock.Anything, mock.Anything)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOrderService_CancelOrder_PrizeOrders(t *testing.T) {
+	cases := []struct {
+		name     string
+		purchase *domain.Purchase
+	}{
+		{"auction", &domain.Purchase{ID: 10, UserID: 1, OrderID: 4, MerchID: 2, Item: "hoody", Quantity: 1, Price: 160,
+			Currency: domain.PrimaryCurrency, HoldID: 41}},
+		{"raffle", &domain.Purchase{ID: 10, UserID: 1, OrderID: 4, MerchID: 2, Item: "hoody", Quantity: 1,
+			Currency: domain.PrimaryCurrency, RaffleID: 5}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mockDB, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			orderRepo := new(mocks.MockOrderRepository)
+			purchaseRepo := new(mocks.MockPurchaseRepository)
+			merchRepo := new(mocks.MockMerchRepository)
+			refundRepo := new(mocks.MockRefundRepository)
+			mockDB.ExpectBegin()
+			mockDB.ExpectRollback()
+
+			order := &domain.Order{ID: 4, UserID: 1, Status: domain.OrderStatusPlaced, CreatedAt: time.Now()}
+			orderRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(4)).Return(order, nil)
+			purchaseRepo.On("GetByOrder", mock.Anything, mock.Anything, int64(4)).Return([]*domain.Purchase{tc.purchase}, nil)
+
+			service := services.NewOrderService(orderRepo, purchaseRepo, refundRepo, merchRepo, new(mocks.MockMerchVariantRepository),
+				noDrops(), new(mocks.MockPromoCodeRepository), new(mocks.MockUserRepository), new(mocks.MockWalletRepository), db, 24*time.Hour)
+
+			_, err = service.CancelOrder(context.Background(), 1, 4)
+
+			assert.ErrorIs(t, err, services.ErrPrizeOrderNotCancellable)
+			merchRepo.AssertNotCalled(t, "IncrementStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			refundRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+			orderRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			assert.NoError(t, mockDB.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPromoService_CreatePromoCode_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	promoRepo := new(mocks.MockPromoCodeRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	promoRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.PromoCode) bool {
+		return p.Code == "SPRING-10" && p.Currency == domain.PrimaryCurrency && p.CreatedBy == 1
+	})).Run(func(args mock.Arguments) {
+		args.Get(2).(*domain.PromoCode).ID = 3
+	}).Return(nil)
+
+	service := services.NewPromoService(promoRepo, new(mocks.MockWalletRepository), db)
+
+	// act
+	promo, err := service.CreatePromoCode(context.Background(), 1, &domain.PromoCode{
+		Code: "spring-10", Kind: domain.PromoKindPercent, Value: 10,
+	})
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), promo.ID)
+	promoRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestPromoService_CreatePromoCode_Validation(t *testing.T) {
+	service := services.NewPromoService(new(mocks.MockPromoCodeRepository), new(mocks.MockWalletRepository), nil)
+	now := time.Now()
+	zero := 0
+
+	invalid := []*domain.PromoCode{
+		{Code: "x", Kind: domain.PromoKindFixed, Value: 5},
+		{Code: "HALF", Kind: domain.PromoKindPercent, Value: 150},
+		{Code: "HALF", Kind: "bogo", Value: 1},
+		{Code: "HALF", Kind: domain.PromoKindFixed, Value: 5, ValidFrom: &now, ValidUntil: &now},
+		{Code: "HALF", Kind: domain.PromoKindFixed, Value: 5, MaxUses: &zero},
+	}
+	for _, promo := range invalid {
+		_, err := service.CreatePromoCode(context.Background(), 1, promo)
+		assert.ErrorIs(t, err, services.ErrInvalidPromoRule, promo.Code)
+	}
+}
+
+func TestPromoCode_FixedDiscountSplit(t *testing.T) {
+	promo := &domain.PromoCode{Kind: domain.PromoKindFixed, Value: 10, Currency: domain.PrimaryCurrency, Categories: []string{"cups"}}
+	lines := []domain.OrderLine{
+		{Item: &domain.Merch{ID: 1, Price: 20, Category: "cups"}, Quantity: 1},
+		{Item: &domain.Merch{ID: 2, Price: 50, Category: "clothes"}, Quantity: 1},
+		{Item: &domain.Merch{ID: 3, Price: 10, Category: "cups"}, Quantity: 1},
+	}
+
+	discounts := promo.Discounts(lines)
+
+	// 10 * 20/30 = 6, остаток 4 уходит в последнюю подходящую строку
+	assert.Equal(t, map[int]int{0: 6, 2: 4}, discounts)
+}
@@ -0,0 +1,214 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRaffleService_BuyTickets_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	raffleRepo := new(mocks.MockRaffleRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	raffle := &domain.Raffle{ID: 3, TicketPrice: 20, MaxTicketsPerUser: 5, Prizes: 1,
+		DrawAt: time.Now().Add(time.Hour), Status: domain.RaffleStatusOpen, TicketsSold: 10}
+	user := &domain.User{ID: 1, Username: "alice", Coins: 100}
+
+	raffleRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(3)).Return(raffle, nil)
+	raffleRepo.On("CountUserTickets", mock.Anything, mock.Anything, int64(3), int64(1)).Return(2, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(user, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, user).Return(nil)
+	raffleRepo.On("CreateEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(e *domain.RaffleEntry) bool {
+		return e.UserID == 1 && e.FirstTicket == 11 && e.Quantity == 3 && e.Amount == 60
+	})).Return(nil)
+	raffleRepo.On("Update", mock.Anything, mock.Anything, raffle).Return(nil)
+
+	service := services.NewRaffleService(raffleRepo, new(mocks.MockMerchRepository), unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), userRepo, new(mocks.MockNotificationRepository), db)
+
+	// act
+	entry, err := service.BuyTickets(context.Background(), 1, 3, 3)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 11, entry.FirstTicket)
+	assert.Equal(t, 40, user.Coins)
+	assert.Equal(t, 13, raffle.TicketsSold)
+	raffleRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestRaffleService_BuyTickets_AccountTooNew(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	raffleRepo := new(mocks.MockRaffleRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	raffleRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(3)).Return(&domain.Raffle{
+		ID: 3, MerchID: 4, Item: "hoody", TicketPrice: 20, Prizes: 1, DrawAt: time.Now().Add(time.Hour), Status: domain.RaffleStatusOpen,
+	}, nil)
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(&domain.User{ID: 1, CreatedAt: time.Now().Add(-24 * time.Hour)}, nil)
+	merchService := restrictedMerchService(4, &domain.MerchPurchaseRule{MerchID: 4, MinAccountAgeDays: 30},
+		userRepo, new(mocks.MockPurchaseRepository))
+
+	service := services.NewRaffleService(raffleRepo, new(mocks.MockMerchRepository), merchService, new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), userRepo, new(mocks.MockNotificationRepository), db)
+
+	_, err = service.BuyTickets(context.Background(), 1, 3, 2)
+
+	assert.ErrorIs(t, err, services.ErrAccountTooNew)
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	raffleRepo.AssertNotCalled(t, "CreateEntry", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestRaffleService_BuyTickets_LimitExceeded(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	raffleRepo := new(mocks.MockRaffleRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	raffleRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(3)).Return(&domain.Raffle{
+		ID: 3, TicketPrice: 20, MaxTicketsPerUser: 5, Prizes: 1, DrawAt: time.Now().Add(time.Hour), Status: domain.RaffleStatusOpen,
+	}, nil)
+	raffleRepo.On("CountUserTickets", mock.Anything, mock.Anything, int64(3), int64(1)).Return(4, nil)
+
+	service := services.NewRaffleService(raffleRepo, new(mocks.MockMerchRepository), unrestrictedMerchService(), new(mocks.MockOrderRepository),
+		new(mocks.MockPurchaseRepository), userRepo, new(mocks.MockNotificationRepository), db)
+
+	_, err = service.BuyTickets(context.Background(), 1, 3, 2)
+
+	assert.ErrorIs(t, err, services.ErrRaffleTicketLimit)
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestRaffleService_DrawRaffles(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	raffleRepo := new(mocks.MockRaffleRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	orderRepo := new(mocks.MockOrderRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+	notifyRepo := new(mocks.MockNotificationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	seed := "5f3c9a"
+	raffle := &domain.Raffle{ID: 3, MerchID: 8, Item: "hoody", TicketPrice: 20, Prizes: 3, Seed: seed,
+		SeedHash: domain.RaffleSeedHash(seed), Status: domain.RaffleStatusOpen, TicketsSold: 6}
+	refundedAt := time.Now()
+	entries := []*domain.RaffleEntry{
+		{RaffleID: 3, UserID: 1, Username: "alice", FirstTicket: 1, Quantity: 4, Amount: 80},
+		{RaffleID: 3, UserID: 2, Username: "bob", FirstTicket: 5, Quantity: 1, Amount: 20},
+		{RaffleID: 3, UserID: 3, Username: "carol", FirstTicket: 6, Quantity: 1, Amount: 20, RefundedAt: &refundedAt},
+	}
+
+	raffleRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(raffle, nil).Once()
+	raffleRepo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything).Return(nil, storage.ErrRaffleNotFound).Once()
+	raffleRepo.On("GetEntries", mock.Anything, mock.Anything, int64(3)).Return(entries, nil)
+	orderRepo.On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.Order")).Return(nil).Twice()
+	purchaseRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(p *domain.Purchase) bool {
+		return p.MerchID == 8 && p.Quantity == 1 && p.Price == 0 && p.RaffleID == raffle.ID
+	})).Return(nil).Twice()
+	raffleRepo.On("CreateWinner", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.RaffleWinner")).Return(nil).Twice()
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Kind == domain.NotificationRaffleWon && n.Data["raffleId"] == "3"
+	})).Return(nil).Twice()
+	// два участника на три приза: один приз возвращается на остаток
+	merchRepo.On("IncrementStock", mock.Anything, mock.Anything, 8, 1).Return(nil)
+	raffleRepo.On("Update", mock.Anything, mock.Anything, raffle).Return(nil)
+
+	service := services.NewRaffleService(raffleRepo, merchRepo, unrestrictedMerchService(), orderRepo, purchaseRepo,
+		new(mocks.MockUserRepository), notifyRepo, db)
+
+	// act
+	err = service.DrawRaffles(context.Background())
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.RaffleStatusDrawn, raffle.Status)
+	assert.Equal(t, seed, raffle.PublishedSeed())
+	orderRepo.AssertExpectations(t)
+	merchRepo.AssertExpectations(t)
+	raffleRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestDrawRaffleWinners_Deterministic(t *testing.T) {
+	entries := []*domain.RaffleEntry{
+		{UserID: 1, FirstTicket: 1, Quantity: 10},
+		{UserID: 2, FirstTicket: 11, Quantity: 10},
+		{UserID: 3, FirstTicket: 21, Quantity: 10},
+	}
+
+	first := domain.DrawRaffleWinners("seed", entries, 2)
+	second := domain.DrawRaffleWinners("seed", entries, 2)
+
+	require.Len(t, first, 2)
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first[0].UserID, first[1].UserID)
+	assert.Equal(t, 1, first[0].Place)
+}
+
+func TestDrawRaffleWinners_MixesTicketList(t *testing.T) {
+	refunded := time.Now()
+	entries := []*domain.RaffleEntry{
+		{UserID: 2, Username: "bob", FirstTicket: 4, Quantity: 2},
+		{UserID: 1, Username: "alice", FirstTicket: 1, Quantity: 3},
+		{UserID: 3, Username: "carol", FirstTicket: 6, Quantity: 1, RefundedAt: &refunded},
+	}
+
+	list := sha256.Sum256([]byte("1-3:alice\n4-5:bob\n"))
+	ticketsHash := hex.EncodeToString(list[:])
+	require.Equal(t, ticketsHash, domain.RaffleTicketsHash(entries))
+
+	sum := sha256.Sum256([]byte("seed:" + ticketsHash + ":0"))
+	expected := "alice"
+	if binary.BigEndian.Uint64(sum[:8])%5 >= 3 {
+		expected = "bob"
+	}
+
+	winners := domain.DrawRaffleWinners("seed", entries, 1)
+	require.Len(t, winners, 1)
+	assert.Equal(t, expected, winners[0].Username)
+
+	// одна дополнительная покупка меняет итоговый хеш, значит seed сам по себе победителей не задает
+	more := append(entries, &domain.RaffleEntry{UserID: 4, Username: "dave", FirstTicket: 7, Quantity: 1})
+	assert.NotEqual(t, ticketsHash, domain.RaffleTicketsHash(more))
+}
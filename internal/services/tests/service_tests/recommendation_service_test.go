@@ -0,0 +1,88 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRecommendationService_ForUser_WithTeam(t *testing.T) {
+	// arrange
+	recRepo := new(mocks.MockRecommendationRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	alsoBought := []*domain.Recommendation{{Merch: &domain.Merch{ID: 2, Name: "cup"}, Buyers: 5}}
+	popular := []*domain.Recommendation{{Merch: &domain.Merch{ID: 4, Name: "socks"}, Buyers: 3}}
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(&domain.User{ID: 1, Team: "payments"}, nil)
+	recRepo.On("AlsoBoughtForUser", mock.Anything, int64(1), mock.Anything).Return(alsoBought, nil)
+	recRepo.On("PopularInTeam", mock.Anything, int64(1), "payments", mock.Anything).Return(popular, nil)
+
+	service := services.NewRecommendationService(recRepo, merchRepo, userRepo, nil)
+
+	// act
+	recs, err := service.ForUser(context.Background(), 1)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "payments", recs.Team)
+	assert.Equal(t, alsoBought, recs.AlsoBought)
+	assert.Equal(t, popular, recs.PopularInTeam)
+}
+
+func TestRecommendationService_ForUser_NoTeam(t *testing.T) {
+	recRepo := new(mocks.MockRecommendationRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(&domain.User{ID: 1}, nil)
+	recRepo.On("AlsoBoughtForUser", mock.Anything, int64(1), mock.Anything).Return([]*domain.Recommendation{}, nil)
+
+	service := services.NewRecommendationService(recRepo, merchRepo, userRepo, nil)
+
+	recs, err := service.ForUser(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.Empty(t, recs.PopularInTeam)
+	recRepo.AssertNotCalled(t, "PopularInTeam", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecommendationService_ForItem_UnknownMerch(t *testing.T) {
+	recRepo := new(mocks.MockRecommendationRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	merchRepo.On("FindByID", mock.Anything, 9).Return(nil, storage.ErrMerchNotFound)
+
+	service := services.NewRecommendationService(recRepo, merchRepo, userRepo, nil)
+
+	_, err := service.ForItem(context.Background(), 1, 9)
+
+	assert.ErrorIs(t, err, storage.ErrMerchNotFound)
+	recRepo.AssertNotCalled(t, "AlsoBoughtWith", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecommendationService_RefreshRecommendations(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	recRepo := new(mocks.MockRecommendationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+	recRepo.On("Refresh", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	service := services.NewRecommendationService(recRepo, new(mocks.MockMerchRepository), new(mocks.MockUserRepository), db)
+
+	require.NoError(t, service.RefreshRecommendations(context.Background()))
+	recRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
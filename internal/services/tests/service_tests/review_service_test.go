@@ -0,0 +1,113 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReviewService_CreateReview_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	reviewRepo := new(mocks.MockReviewRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	merchRepo.On("FindByID", mock.Anything, 3).Return(&domain.Merch{ID: 3, Name: "hoody"}, nil)
+	purchaseRepo.On("HasPurchased", mock.Anything, int64(1), 3).Return(true, nil)
+	reviewRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(r *domain.Review) bool {
+		return r.MerchID == 3 && r.UserID == 1 && r.Rating == 4 && r.Body == "warm" && r.Status == domain.ReviewStatusPublished
+	})).Return(nil)
+	reviewRepo.On("AdjustStats", mock.Anything, mock.Anything, 3, 1, 4).Return(nil)
+
+	service := services.NewReviewService(reviewRepo, merchRepo, purchaseRepo, db)
+
+	// act
+	review, err := service.CreateReview(context.Background(), 1, 3, 4, "  warm ")
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, "hoody", review.Item)
+	reviewRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReviewService_CreateReview_NotVerifiedBuyer(t *testing.T) {
+	reviewRepo := new(mocks.MockReviewRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+
+	merchRepo.On("FindByID", mock.Anything, 3).Return(&domain.Merch{ID: 3, Name: "hoody"}, nil)
+	purchaseRepo.On("HasPurchased", mock.Anything, int64(1), 3).Return(false, nil)
+
+	service := services.NewReviewService(reviewRepo, merchRepo, purchaseRepo, nil)
+
+	_, err := service.CreateReview(context.Background(), 1, 3, 5, "")
+
+	assert.ErrorIs(t, err, services.ErrNotVerifiedBuyer)
+	reviewRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReviewService_CreateReview_Duplicate(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	reviewRepo := new(mocks.MockReviewRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+	purchaseRepo := new(mocks.MockPurchaseRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	merchRepo.On("FindByID", mock.Anything, 3).Return(&domain.Merch{ID: 3, Name: "hoody"}, nil)
+	purchaseRepo.On("HasPurchased", mock.Anything, int64(1), 3).Return(true, nil)
+	reviewRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(storage.ErrReviewExists)
+
+	service := services.NewReviewService(reviewRepo, merchRepo, purchaseRepo, db)
+
+	_, err = service.CreateReview(context.Background(), 1, 3, 5, "")
+
+	assert.ErrorIs(t, err, storage.ErrReviewExists)
+	reviewRepo.AssertNotCalled(t, "AdjustStats", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReviewService_ModerateReview_Hide(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	reviewRepo := new(mocks.MockReviewRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	review := &domain.Review{ID: 9, MerchID: 3, UserID: 1, Rating: 2, Status: domain.ReviewStatusFlagged}
+	reviewRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(9)).Return(review, nil)
+	reviewRepo.On("AdjustStats", mock.Anything, mock.Anything, 3, -1, -2).Return(nil)
+	reviewRepo.On("Update", mock.Anything, mock.Anything, review).Return(nil)
+
+	service := services.NewReviewService(reviewRepo, new(mocks.MockMerchRepository), new(mocks.MockPurchaseRepository), db)
+
+	moderated, err := service.ModerateReview(context.Background(), 100, 9, domain.ReviewStatusHidden)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReviewStatusHidden, moderated.Status)
+	assert.Equal(t, int64(100), moderated.ModeratedBy)
+	reviewRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -0,0 +1,155 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStatementService_GenerateStatement_Success(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	statementRepo := new(mocks.MockStatementRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	userID := int64(1)
+	period := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := period.AddDate(0, 1, 0)
+	movements := []domain.StatementMovement{
+		{Kind: domain.MovementTransferIn, Amount: 100, Counterparty: "alice"},
+		{Kind: domain.MovementPurchase, Amount: -80, Description: "t-shirt"},
+		{Kind: domain.MovementTransferOut, Amount: -50, Counterparty: "bob"},
+	}
+
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{ID: userID, Coins: 900}, nil)
+	statementRepo.On("FindByUserAndPeriod", mock.Anything, mock.Anything, userID, period).Return(nil, storage.ErrStatementNotFound)
+	statementRepo.On("GetNetChangeSince", mock.Anything, mock.Anything, userID, end).Return(-20, nil)
+	statementRepo.On("GetMovements", mock.Anything, mock.Anything, userID, period, end).Return(movements, nil)
+	statementRepo.On("FindByUserAndPeriod", mock.Anything, mock.Anything, userID, period.AddDate(0, -1, 0)).
+		Return(&domain.Statement{ClosingBalance: 950}, nil)
+	statementRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(s *domain.Statement) bool {
+		return s.UserID == userID && s.Period.Equal(period)
+	})).Return(nil)
+
+	service := services.NewStatementService(statementRepo, userRepo, db)
+
+	// act
+	statement, err := service.GenerateStatement(context.Background(), userID, period.AddDate(0, 0, 14))
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, 920, statement.ClosingBalance)
+	assert.Equal(t, 950, statement.OpeningBalance)
+	assert.Equal(t, 100, statement.TotalCredits)
+	assert.Equal(t, 130, statement.TotalDebits)
+	statementRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStatementService_GenerateStatement_AlreadyExists(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	statementRepo := new(mocks.MockStatementRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	userID := int64(1)
+	period := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	existing := &domain.Statement{ID: 7, UserID: userID, Period: period, ClosingBalance: 500}
+
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{ID: userID, Coins: 900}, nil)
+	statementRepo.On("FindByUserAndPeriod", mock.Anything, mock.Anything, userID, period).Return(existing, nil)
+
+	service := services.NewStatementService(statementRepo, userRepo, db)
+
+	// act
+	statement, err := service.GenerateStatement(context.Background(), userID, period)
+
+	// assert
+	require.NoError(t, err)
+	assert.Equal(t, existing, statement)
+	statementRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStatementService_GenerateStatement_MismatchWithPrevious(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	statementRepo := new(mocks.MockStatementRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	userID := int64(1)
+	period := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := period.AddDate(0, 1, 0)
+
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, userID).Return(&domain.User{ID: userID, Coins: 900}, nil)
+	statementRepo.On("FindByUserAndPeriod", mock.Anything, mock.Anything, userID, period).Return(nil, storage.ErrStatementNotFound)
+	statementRepo.On("GetNetChangeSince", mock.Anything, mock.Anything, userID, end).Return(0, nil)
+	statementRepo.On("GetMovements", mock.Anything, mock.Anything, userID, period, end).Return([]domain.StatementMovement{}, nil)
+	statementRepo.On("FindByUserAndPeriod", mock.Anything, mock.Anything, userID, period.AddDate(0, -1, 0)).
+		Return(&domain.Statement{ClosingBalance: 1000}, nil)
+
+	service := services.NewStatementService(statementRepo, userRepo, db)
+
+	// act
+	_, err = service.GenerateStatement(context.Background(), userID, period)
+
+	// assert
+	assert.ErrorIs(t, err, services.ErrStatementMismatch)
+	statementRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStatementService_GenerateStatement_PeriodNotClosed(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	service := services.NewStatementService(new(mocks.MockStatementRepository), new(mocks.MockUserRepository), db)
+
+	_, err = service.GenerateStatement(context.Background(), 1, time.Now())
+
+	assert.ErrorIs(t, err, services.ErrStatementPeriodNotClosed)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStatementService_GenerateMonthlyStatements_ListError(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	statementRepo := new(mocks.MockStatementRepository)
+	statementRepo.On("ListUsersWithoutStatement", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	service := services.NewStatementService(statementRepo, new(mocks.MockUserRepository), db)
+
+	err = service.GenerateMonthlyStatements(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
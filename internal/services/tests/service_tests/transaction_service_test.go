@@ -24,7 +24,7 @@ func TestTransferCoins_BeginTxError(t *testing.T) {
 	userRepo := new(mocks.MockUserRepository)
 	transactionRepo := new(mocks.MockTransactionRepository)
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.ErrorIs(t, err, expectedErr)
 
@@ -47,7 +47,7 @@ func TestTransferCoins_FindFromUserError(t *testing.T) {
 	transactionRepo := new(mocks.MockTransactionRepository)
 	mockDB.ExpectRollback()
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.ErrorIs(t, err, expectedErr)
 
@@ -75,7 +75,7 @@ func TestTransferCoins_FindToUserError(t *testing.T) {
 	transactionRepo := new(mocks.MockTransactionRepository)
 	mockDB.ExpectRollback()
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.ErrorIs(t, err, expectedErr)
 
@@ -103,7 +103,7 @@ func TestTransferCoins_InsufficientFunds(t *testing.T) {
 	transactionRepo := new(mocks.MockTransactionRepository)
 	mockDB.ExpectRollback()
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.ErrorIs(t, err, services.ErrLackOfFundsOnAccount)
 
@@ -136,7 +136,7 @@ func TestTransferCoins_UpdateFromUserError(t *testing.T) {
 	transactionRepo := new(mocks.MockTransactionRepository)
 	mockDB.ExpectRollback()
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.ErrorIs(t, err, expectedErr)
 
@@ -172,7 +172,7 @@ func TestTransferCoins_UpdateToUserError(t *testing.T) {
 	transactionRepo := new(mocks.MockTransactionRepository)
 	mockDB.ExpectRollback()
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.ErrorIs(t, err, expectedErr)
 
@@ -211,7 +211,7 @@ func TestTransferCoins_CreateTransactionError(t *testing.T) {
 
 	mockDB.ExpectRollback()
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.ErrorIs(t, err, expectedErr)
 
@@ -252,7 +252,7 @@ func TestTransferCoins_CommitError(t *testing.T) {
 	commitError := errors.New("commit error")
 	mockDB.ExpectCommit().WillReturnError(commitError)
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.ErrorIs(t, err, commitError)
 
@@ -293,7 +293,7 @@ func TestTransferCoins_Success(t *testing.T) {
 
 	mockDB.ExpectCommit()
 
-	service := services.NewTransactionService(db, userRepo, transactionRepo)
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	err = service.TransferCoins(context.Background(), 1, 2, 100)
 	assert.NoError(t, err)
 
@@ -302,6 +302,46 @@ func TestTransferCoins_Success(t *testing.T) {
 	assert.NoError(t, mockDB.ExpectationsWereMet())
 }
 
+func TestTransferCoins_LocksLowerIDFirst(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockDB.ExpectBegin()
+
+	fromUser := &domain.User{ID: 5, Coins: 200}
+	toUser := &domain.User{ID: 2, Coins: 300}
+	var locked []int64
+	userRepo := new(mocks.MockUserRepository)
+	for _, user := range []*domain.User{fromUser, toUser} {
+		userRepo.
+			On("FindByIDForUpdate", mock.Anything, mock.Anything, user.ID).
+			Run(func(args mock.Arguments) {
+				locked = append(locked, args.Get(2).(int64))
+			}).
+			Return(user, nil)
+	}
+	userRepo.
+		On("Update", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.User")).
+		Return(nil)
+
+	transactionRepo := new(mocks.MockTransactionRepository)
+	transactionRepo.
+		On("Create", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.CoinTransaction")).
+		Return(nil)
+
+	mockDB.ExpectCommit()
+
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
+	err = service.TransferCoins(context.Background(), 5, 2, 100)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int64{2, 5}, locked)
+	assert.Equal(t, 100, fromUser.Coins)
+	assert.Equal(t, 400, toUser.Coins)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
 func TestGetSentTransactions_Success(t *testing.T) {
 	ctx := context.Background()
 	userID := int64(1)
@@ -313,7 +353,7 @@ func TestGetSentTransactions_Success(t *testing.T) {
 		On("GetSentTransactions", ctx, userID).
 		Return(expectedTransactions, nil)
 
-	service := services.NewTransactionService(nil, nil, transactionRepo)
+	service := services.NewTransactionService(nil, nil, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	transactions, err := service.GetSentTransactions(ctx, userID)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedTransactions, transactions)
@@ -329,7 +369,7 @@ func TestGetSentTransactions_Error(t *testing.T) {
 		On("GetSentTransactions", ctx, userID).
 		Return(nil, expectedErr)
 
-	service := services.NewTransactionService(nil, nil, transactionRepo)
+	service := services.NewTransactionService(nil, nil, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	transactions, err := service.GetSentTransactions(ctx, userID)
 	assert.ErrorIs(t, err, expectedErr)
 	assert.Nil(t, transactions)
@@ -347,7 +387,7 @@ func TestGetReceivedTransactions_Success(t *testing.T) {
 		On("GetReceivedTransactions", ctx, userID).
 		Return(expectedTransactions, nil)
 
-	service := services.NewTransactionService(nil, nil, transactionRepo)
+	service := services.NewTransactionService(nil, nil, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	transactions, err := service.GetReceivedTransactions(ctx, userID)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedTransactions, transactions)
@@ -363,9 +403,200 @@ func TestGetReceivedTransactions_Error(t *testing.T) {
 		On("GetReceivedTransactions", ctx, userID).
 		Return(nil, expectedErr)
 
-	service := services.NewTransactionService(nil, nil, transactionRepo)
+	service := services.NewTransactionService(nil, nil, transactionRepo, new(mocks.MockWalletRepository), domain.FeePolicy{})
 	transactions, err := service.GetReceivedTransactions(ctx, userID)
 	assert.ErrorIs(t, err, expectedErr)
 	assert.Nil(t, transactions)
 	transactionRepo.AssertExpectations(t)
 }
+
+func TestTransferCoins_WithFee_Success(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	policy := domain.FeePolicy{Flat: 1, Percent: 5, Min: 2, Max: 20, AccountID: 99}
+	fromUser := &domain.User{ID: 1, Coins: 200}
+	toUser := &domain.User{ID: 2, Coins: 50}
+
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(fromUser, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(2)).Return(toUser, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+
+	transactionRepo := new(mocks.MockTransactionRepository)
+	transactionRepo.On("CountSentSince", mock.Anything, mock.Anything, int64(1), mock.Anything).Return(3, nil)
+	transactionRepo.
+		On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(tr *domain.CoinTransaction) bool {
+			return tr.Amount == 100 && tr.Fee == 6 && tr.FeeAccountID == 99
+		})).
+		Return(nil)
+
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), policy)
+	err = service.TransferCoins(context.Background(), 1, 2, 100)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 94, fromUser.Coins)
+	assert.Equal(t, 150, toUser.Coins)
+	// счет комиссий не блокируется в переводе, комиссию зачисляет SettleFees
+	userRepo.AssertNotCalled(t, "FindByIDForUpdate", mock.Anything, mock.Anything, int64(99))
+	userRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferCoins_ToSelf(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := new(mocks.MockUserRepository)
+	transactionRepo := new(mocks.MockTransactionRepository)
+	policy := domain.FeePolicy{Flat: 5, AccountID: 99}
+
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), policy)
+	err = service.TransferCoins(context.Background(), 1, 1, 100)
+	assert.ErrorIs(t, err, services.ErrInvalidTransfer)
+
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferCoins_WithFee_InsufficientFunds(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	policy := domain.FeePolicy{Flat: 5, AccountID: 99}
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(1)).Return(&domain.User{ID: 1, Coins: 100}, nil)
+	userRepo.On("FindByIDForUpdate", mock.Anything, mock.Anything, int64(2)).Return(&domain.User{ID: 2, Coins: 0}, nil)
+
+	transactionRepo := new(mocks.MockTransactionRepository)
+	transactionRepo.On("CountSentSince", mock.Anything, mock.Anything, int64(1), mock.Anything).Return(0, nil)
+
+	service := services.NewTransactionService(db, userRepo, transactionRepo, new(mocks.MockWalletRepository), policy)
+	err = service.TransferCoins(context.Background(), 1, 2, 100)
+	assert.ErrorIs(t, err, services.ErrLackOfFundsOnAccount)
+
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestQuoteTransfer_FreeAllowance(t *testing.T) {
+	policy := domain.FeePolicy{Percent: 10, Min: 1, FreeTransfersPerMonth: 2, AccountID: 99}
+
+	transactionRepo := new(mocks.MockTransactionRepository)
+	transactionRepo.On("CountSentSince", mock.Anything, nil, int64(1), mock.Anything).Return(1, nil).Once()
+	transactionRepo.On("CountSentSince", mock.Anything, nil, int64(1), mock.Anything).Return(2, nil).Once()
+
+	service := services.NewTransactionService(nil, nil, transactionRepo, new(mocks.MockWalletRepository), policy)
+
+	quote, err := service.QuoteTransfer(context.Background(), 1, 55)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.TransferQuote{Amount: 55, Fee: 0, Total: 55}, quote)
+
+	quote, err = service.QuoteTransfer(context.Background(), 1, 55)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.TransferQuote{Amount: 55, Fee: 6, Total: 61}, quote)
+}
+
+func TestQuoteTransfer_InvalidAmount(t *testing.T) {
+	service := services.NewTransactionService(nil, nil, new(mocks.MockTransactionRepository), new(mocks.MockWalletRepository), domain.FeePolicy{})
+
+	_, err := service.QuoteTransfer(context.Background(), 1, 0)
+	assert.ErrorIs(t, err, services.ErrInvalidAmount)
+}
+
+func TestTransferCurrency_Success(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	fromWallet := &domain.Wallet{UserID: 5, Currency: "event_token", Balance: 10}
+	toWallet := &domain.Wallet{UserID: 2, Currency: "event_token", Balance: 1}
+
+	walletRepo := new(mocks.MockWalletRepository)
+	walletRepo.On("CurrencyExists", mock.Anything, "event_token").Return(true, nil)
+	walletRepo.On("FindForUpdate", mock.Anything, mock.Anything, int64(2), "event_token").Return(toWallet, nil).Once()
+	walletRepo.On("FindForUpdate", mock.Anything, mock.Anything, int64(5), "event_token").Return(fromWallet, nil).Once()
+	walletRepo.On("Update", mock.Anything, mock.Anything, mock.AnythingOfType("*domain.Wallet")).Return(nil)
+
+	transactionRepo := new(mocks.MockTransactionRepository)
+	transactionRepo.
+		On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(tr *domain.CoinTransaction) bool {
+			return tr.Currency == "event_token" && tr.Amount == 4 && tr.Fee == 0
+		})).
+		Return(nil)
+
+	service := services.NewTransactionService(db, new(mocks.MockUserRepository), transactionRepo, walletRepo, domain.FeePolicy{})
+	err = service.TransferCurrency(context.Background(), 5, 2, "event_token", 4)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 6, fromWallet.Balance)
+	assert.Equal(t, 5, toWallet.Balance)
+	walletRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferCurrency_InsufficientFunds(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectRollback()
+
+	walletRepo := new(mocks.MockWalletRepository)
+	walletRepo.On("CurrencyExists", mock.Anything, "event_token").Return(true, nil)
+	walletRepo.On("FindForUpdate", mock.Anything, mock.Anything, int64(1), "event_token").
+		Return(&domain.Wallet{UserID: 1, Currency: "event_token", Balance: 3}, nil)
+	walletRepo.On("FindForUpdate", mock.Anything, mock.Anything, int64(2), "event_token").
+		Return(&domain.Wallet{UserID: 2, Currency: "event_token"}, nil)
+
+	service := services.NewTransactionService(db, nil, new(mocks.MockTransactionRepository), walletRepo, domain.FeePolicy{})
+	err = service.TransferCurrency(context.Background(), 1, 2, "event_token", 4)
+	assert.ErrorIs(t, err, services.ErrLackOfFundsOnAccount)
+
+	walletRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferCurrency_UnknownCurrency(t *testing.T) {
+	walletRepo := new(mocks.MockWalletRepository)
+	walletRepo.On("CurrencyExists", mock.Anything, "gold").Return(false, nil)
+
+	service := services.NewTransactionService(nil, nil, new(mocks.MockTransactionRepository), walletRepo, domain.FeePolicy{})
+	err := service.TransferCurrency(context.Background(), 1, 2, "gold", 4)
+	assert.ErrorIs(t, err, services.ErrUnknownCurrency)
+}
+
+func TestTransferCurrency_ToSelf(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	wallet := &domain.Wallet{UserID: 1, Currency: "event_token", Balance: 10}
+	walletRepo := new(mocks.MockWalletRepository)
+	walletRepo.On("CurrencyExists", mock.Anything, "event_token").Return(true, nil)
+	walletRepo.On("FindForUpdate", mock.Anything, mock.Anything, int64(1), "event_token").Return(wallet, nil)
+
+	service := services.NewTransactionService(db, nil, new(mocks.MockTransactionRepository), walletRepo, domain.FeePolicy{})
+	err = service.TransferCurrency(context.Background(), 1, 1, "event_token", 4)
+	assert.ErrorIs(t, err, services.ErrInvalidTransfer)
+
+	assert.Equal(t, 10, wallet.Balance)
+	walletRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
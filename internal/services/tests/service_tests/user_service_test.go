@@ -778,6 +778,9 @@ func (d *dummyUserRepository) Update(_ context.Context, _ storage.Tx, _ *domain.
 func (d *dummyUserRepository) FindByIDForUpdate(_ context.Context, _ storage.Tx, _ int64) (*domain.User, error) {
 	return nil, sql.ErrNoRows
 }
+func (d *dummyUserRepository) SetTeam(_ context.Context, _ int64, _ string) error {
+	return nil
+}
 
 type dummyJWT struct{}
 
@@ -0,0 +1,48 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWalletService_GetWallets_PrimaryFirst(t *testing.T) {
+	walletRepo := new(mocks.MockWalletRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(&domain.User{ID: 1, Coins: 700}, nil)
+	walletRepo.On("GetByUser", mock.Anything, int64(1)).Return([]*domain.Wallet{
+		{UserID: 1, Currency: "event_token", Balance: 5},
+	}, nil)
+
+	service := services.NewWalletService(walletRepo, userRepo)
+
+	wallets, err := service.GetWallets(context.Background(), 1)
+
+	require.NoError(t, err)
+	require.Len(t, wallets, 2)
+	assert.Equal(t, &domain.Wallet{UserID: 1, Currency: domain.PrimaryCurrency, Balance: 700}, wallets[0])
+	assert.Equal(t, "event_token", wallets[1].Currency)
+	walletRepo.AssertExpectations(t)
+	userRepo.AssertExpectations(t)
+}
+
+func TestWalletService_GetWallets_UserNotFound(t *testing.T) {
+	walletRepo := new(mocks.MockWalletRepository)
+	userRepo := new(mocks.MockUserRepository)
+
+	userRepo.On("FindByID", mock.Anything, int64(1)).Return(nil, storage.ErrUserNotFound)
+
+	service := services.NewWalletService(walletRepo, userRepo)
+
+	_, err := service.GetWallets(context.Background(), 1)
+
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+	walletRepo.AssertNotCalled(t, "GetByUser", mock.Anything, mock.Anything)
+}
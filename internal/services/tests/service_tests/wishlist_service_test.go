@@ -0,0 +1,130 @@
+package service_tests
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWishlistService_SendAlerts(t *testing.T) {
+	// arrange
+	db, mockDB, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	wishlistRepo := new(mocks.MockWishlistRepository)
+	notifyRepo := new(mocks.MockNotificationRepository)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectCommit()
+
+	wishlistRepo.On("CollectAlerts", mock.Anything, mock.Anything, mock.Anything).Return([]*domain.WishlistAlert{
+		{UserID: 1, MerchID: 2, Name: "cup", Currency: "coin", OldPrice: 20, Price: 15, WasInStock: true, InStock: true},
+		{UserID: 1, MerchID: 3, Name: "hoody", Currency: "coin", OldPrice: 300, Price: 300, WasInStock: false, InStock: true},
+		{UserID: 2, MerchID: 2, Name: "cup", Currency: "coin", OldPrice: 10, Price: 15, WasInStock: true, InStock: true},
+		{UserID: 3, MerchID: 4, Name: "pen", Currency: "coin", OldPrice: 10, Price: 5, WasInStock: false, InStock: false},
+	}, nil).Once()
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.UserID == 1 && n.Kind == domain.NotificationPriceDrop && n.Data["oldPrice"] == "20" && n.Data["price"] == "15"
+	})).Return(nil).Once()
+	notifyRepo.On("Create", mock.Anything, mock.Anything, mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.UserID == 1 && n.Kind == domain.NotificationBackInStock && n.Data["item"] == "hoody"
+	})).Return(nil).Once()
+
+	service := services.NewWishlistService(wishlistRepo, new(mocks.MockMerchRepository), new(mocks.MockUserRepository), notifyRepo, db)
+
+	// act
+	err = service.SendAlerts(context.Background())
+
+	// assert
+	require.NoError(t, err)
+	wishlistRepo.AssertExpectations(t)
+	notifyRepo.AssertExpectations(t)
+	notifyRepo.AssertNumberOfCalls(t, "Create", 2)
+	assert.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestWishlistService_GetSharedWishlist_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		owner  *domain.User
+		viewer *domain.User
+		shared bool
+	}{
+		{
+			name:   "other team",
+			owner:  &domain.User{ID: 2, Username: "bob", Team: "backend"},
+			viewer: &domain.User{ID: 1, Username: "alice", Team: "frontend"},
+			shared: true,
+		},
+		{
+			name:   "no team",
+			owner:  &domain.User{ID: 2, Username: "bob"},
+			viewer: &domain.User{ID: 1, Username: "alice"},
+			shared: true,
+		},
+		{
+			name:   "not shared",
+			owner:  &domain.User{ID: 2, Username: "bob", Team: "backend"},
+			viewer: &domain.User{ID: 1, Username: "alice", Team: "backend"},
+			shared: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wishlistRepo := new(mocks.MockWishlistRepository)
+			userRepo := new(mocks.MockUserRepository)
+
+			userRepo.On("FindByUsername", mock.Anything, tt.owner.Username).Return(tt.owner, nil)
+			userRepo.On("FindByID", mock.Anything, tt.viewer.ID).Return(tt.viewer, nil)
+			wishlistRepo.On("IsShared", mock.Anything, tt.owner.ID).Return(tt.shared, nil)
+
+			service := services.NewWishlistService(wishlistRepo, new(mocks.MockMerchRepository), userRepo, new(mocks.MockNotificationRepository), nil)
+
+			_, err := service.GetSharedWishlist(context.Background(), tt.viewer.ID, tt.owner.Username)
+
+			assert.ErrorIs(t, err, services.ErrWishlistNotShared)
+			wishlistRepo.AssertNotCalled(t, "GetByUser", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWishlistService_AddItem_Retired(t *testing.T) {
+	wishlistRepo := new(mocks.MockWishlistRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+
+	merchRepo.On("FindByName", mock.Anything, "cup").Return(&domain.Merch{}, storage.ErrMerchNotFound)
+
+	service := services.NewWishlistService(wishlistRepo, merchRepo, new(mocks.MockUserRepository), new(mocks.MockNotificationRepository), nil)
+
+	err := service.AddItem(context.Background(), 1, "cup")
+
+	assert.ErrorIs(t, err, storage.ErrMerchNotFound)
+	wishlistRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWishlistService_RemoveItem_Retired(t *testing.T) {
+	wishlistRepo := new(mocks.MockWishlistRepository)
+	merchRepo := new(mocks.MockMerchRepository)
+
+	wishlistRepo.On("GetByUser", mock.Anything, int64(1)).Return([]*domain.WishlistItem{
+		{UserID: 1, Item: &domain.Merch{ID: 2, Name: "cup", Price: 20, Active: false}},
+	}, nil)
+	wishlistRepo.On("Remove", mock.Anything, int64(1), 2).Return(nil).Once()
+
+	service := services.NewWishlistService(wishlistRepo, merchRepo, new(mocks.MockUserRepository), new(mocks.MockNotificationRepository), nil)
+
+	require.NoError(t, service.RemoveItem(context.Background(), 1, "cup"))
+	assert.ErrorIs(t, service.RemoveItem(context.Background(), 1, "pen"), storage.ErrWishlistItemNotFound)
+
+	wishlistRepo.AssertExpectations(t)
+	merchRepo.AssertNotCalled(t, "FindByName", mock.Anything, mock.Anything)
+}
@@ -13,26 +13,60 @@ import (
 var (
 	ErrInvalidAmount        = errors.New("invalid amount")
 	ErrLackOfFundsOnAccount = errors.New("lack of funds on account")
+	ErrUnknownCurrency      = errors.New("unknown currency")
+	ErrInvalidTransfer      = errors.New("cannot transfer to yourself")
 )
 
 type TransactionService struct {
 	transactionRepo storage.TransactionRepository
 	userRepo        storage.UserRepository
+	walletRepo      storage.WalletRepository
 	db              *sql.DB
+	feePolicy       domain.FeePolicy
 }
 
 func NewTransactionService(
 	db *sql.DB,
 	userRepo storage.UserRepository,
-	transactionRepo storage.TransactionRepository) *TransactionService {
+	transactionRepo storage.TransactionRepository,
+	walletRepo storage.WalletRepository,
+	feePolicy domain.FeePolicy) *TransactionService {
 	return &TransactionService{
 		transactionRepo: transactionRepo,
 		userRepo:        userRepo,
+		walletRepo:      walletRepo,
 		db:              db,
+		feePolicy:       feePolicy,
 	}
 }
 
+func (s *TransactionService) QuoteTransfer(ctx context.Context, fromUserID int64, amount int) (*domain.TransferQuote, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	fee, err := s.calculateFee(ctx, nil, fromUserID, amount)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TransferQuote{Amount: amount, Fee: fee, Total: amount + fee}, nil
+}
+
+func (s *TransactionService) calculateFee(ctx context.Context, tx storage.Tx, fromUserID int64, amount int) (int, error) {
+	if !s.feePolicy.Enabled() {
+		return 0, nil
+	}
+	sent, err := s.transactionRepo.CountSentSince(ctx, tx, fromUserID, MonthStart(time.Now()))
+	if err != nil {
+		return 0, err
+	}
+	return s.feePolicy.Calculate(amount, sent), nil
+}
+
+// TransferCoins переводит монеты и записывает комиссию в перевод. На счет комиссий она зачисляется задачей SettleFees.
 func (s *TransactionService) TransferCoins(ctx context.Context, fromUserID, toUserID int64, amount int) (err error) {
+	if fromUserID == toUserID {
+		return ErrInvalidTransfer
+	}
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -52,20 +86,34 @@ func (s *TransactionService) TransferCoins(ctx context.Context, fromUserID, toUs
 		}
 	}()
 
-	fromUser, err := s.userRepo.FindByIDForUpdate(ctx, tx, fromUserID)
+	// пользователи блокируются в порядке id, как и кошельки в TransferCurrency
+	firstID, secondID := fromUserID, toUserID
+	if firstID > secondID {
+		firstID, secondID = secondID, firstID
+	}
+	first, err := s.userRepo.FindByIDForUpdate(ctx, tx, firstID)
 	if err != nil {
 		return err
 	}
-	toUser, err := s.userRepo.FindByIDForUpdate(ctx, tx, toUserID)
+	second, err := s.userRepo.FindByIDForUpdate(ctx, tx, secondID)
 	if err != nil {
 		return err
 	}
+	fromUser, toUser := first, second
+	if firstID != fromUserID {
+		fromUser, toUser = second, first
+	}
 
-	if fromUser.Coins < amount {
+	fee, err := s.calculateFee(ctx, tx, fromUserID, amount)
+	if err != nil {
+		return err
+	}
+
+	if fromUser.AvailableCoins() < amount+fee {
 		return ErrLackOfFundsOnAccount
 	}
 
-	fromUser.Coins -= amount
+	fromUser.Coins -= amount + fee
 	toUser.Coins += amount
 
 	if err = s.userRepo.Update(ctx, tx, fromUser); err != nil {
@@ -76,10 +124,12 @@ func (s *TransactionService) TransferCoins(ctx context.Context, fromUserID, toUs
 	}
 
 	transaction := &domain.CoinTransaction{
-		FromUserID: fromUserID,
-		ToUserID:   toUserID,
-		Amount:     amount,
-		CreatedAt:  time.Now(),
+		FromUserID:   fromUserID,
+		ToUserID:     toUserID,
+		Amount:       amount,
+		Fee:          fee,
+		FeeAccountID: s.feePolicy.AccountID,
+		CreatedAt:    time.Now(),
 	}
 
 	if err = s.transactionRepo.Create(ctx, tx, transaction); err != nil {
@@ -89,6 +139,92 @@ func (s *TransactionService) TransferCoins(ctx context.Context, fromUserID, toUs
 	return nil
 }
 
+// TransferCurrency переводит средства в указанной валюте, основная валюта идет через TransferCoins.
+func (s *TransactionService) TransferCurrency(ctx context.Context, fromUserID, toUserID int64, currency string, amount int) (err error) {
+	if domain.IsPrimaryCurrency(currency) {
+		return s.TransferCoins(ctx, fromUserID, toUserID, amount)
+	}
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	// при переводе себе обе блокировки берут один кошелек, и зачисление затерло бы списание
+	if fromUserID == toUserID {
+		return ErrInvalidTransfer
+	}
+
+	exists, err := s.walletRepo.CurrencyExists(ctx, currency)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUnknownCurrency
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				log.Printf("rollback error: %v", rbErr)
+			}
+		} else {
+			if cmErr := tx.Commit(); cmErr != nil {
+				if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+					log.Printf("rollback error: %v", rbErr)
+				}
+				err = cmErr
+			}
+		}
+	}()
+
+	// кошельки блокируются в порядке id, чтобы встречные переводы не ловили дедлок
+	firstID, secondID := fromUserID, toUserID
+	if firstID > secondID {
+		firstID, secondID = secondID, firstID
+	}
+	first, err := s.walletRepo.FindForUpdate(ctx, tx, firstID, currency)
+	if err != nil {
+		return err
+	}
+	second, err := s.walletRepo.FindForUpdate(ctx, tx, secondID, currency)
+	if err != nil {
+		return err
+	}
+	fromWallet, toWallet := first, second
+	if fromWallet.UserID != fromUserID {
+		fromWallet, toWallet = second, first
+	}
+
+	if fromWallet.Available() < amount {
+		return ErrLackOfFundsOnAccount
+	}
+
+	fromWallet.Balance -= amount
+	toWallet.Balance += amount
+
+	if err = s.walletRepo.Update(ctx, tx, fromWallet); err != nil {
+		return err
+	}
+	if err = s.walletRepo.Update(ctx, tx, toWallet); err != nil {
+		return err
+	}
+
+	return s.transactionRepo.Create(ctx, tx, &domain.CoinTransaction{
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Amount:     amount,
+		Currency:   currency,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func (s *TransactionService) SettleFees(ctx context.Context) error {
+	_, err := s.transactionRepo.SettleFees(ctx, time.Now())
+	return err
+}
+
 func (s *TransactionService) GetSentTransactions(ctx context.Context, userID int64) ([]*domain.CoinTransaction, error) {
 	return s.transactionRepo.GetSentTransactions(ctx, userID)
 }
@@ -11,6 +11,8 @@ import (
 	"github.com/go-redis/redis/v8"
 	"golang.org/x/crypto/bcrypt"
 	"log"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -22,8 +24,12 @@ type RedisClient interface {
 var (
 	ErrInvalidPassword = errors.New("invalid password")
 	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidTeam     = errors.New("team must be at most 50 lowercase latin letters, digits or dashes")
 )
 
+// пустая строка убирает пользователя из команды
+var teamPattern = regexp.MustCompile(`^([a-z0-9][a-z0-9-]{0,49})?$`)
+
 type UserService struct {
 	userRepo    storage.UserRepository
 	jwtService  jwt.JWT
@@ -131,6 +137,14 @@ func (s *UserService) GetUserByID(ctx context.Context, userID int64) (*domain.Us
 	return user, nil
 }
 
+func (s *UserService) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user.Role == domain.RoleAdmin, nil
+}
+
 func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
 	user, err := s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
@@ -142,6 +156,29 @@ func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*
 	return user, nil
 }
 
+// SetTeam задает команду пользователя, по ней проверяются ограничения на покупку товаров.
+func (s *UserService) SetTeam(ctx context.Context, userID int64, team string) (*domain.User, error) {
+	team, err := normalizeTeam(team)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetTeam(ctx, userID, team); err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return s.GetUserByID(ctx, userID)
+}
+
+func normalizeTeam(team string) (string, error) {
+	team = strings.ToLower(strings.TrimSpace(team))
+	if !teamPattern.MatchString(team) {
+		return "", ErrInvalidTeam
+	}
+	return team, nil
+}
+
 func (s *UserService) UpdateUserCoins(ctx context.Context, userID int64, coins int) error {
 	tx, err := s.userRepo.BeginTx(ctx)
 	if err != nil {
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"errors"
+)
+
+type WalletService struct {
+	walletRepo storage.WalletRepository
+	userRepo   storage.UserRepository
+}
+
+func NewWalletService(walletRepo storage.WalletRepository, userRepo storage.UserRepository) *WalletService {
+	return &WalletService{
+		walletRepo: walletRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// GetWallets возвращает все кошельки пользователя, первым идет основной (users.coins).
+func (s *WalletService) GetWallets(ctx context.Context, userID int64) ([]*domain.Wallet, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	wallets, err := s.walletRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]*domain.Wallet{{
+		UserID:   userID,
+		Currency: domain.PrimaryCurrency,
+		Balance:  user.Coins,
+		Held:     user.HeldCoins,
+	}}, wallets...), nil
+}
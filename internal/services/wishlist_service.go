@@ -0,0 +1,179 @@
+package services
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	ErrWishlistNotShared = errors.New("wishlist is not shared with you")
+)
+
+// alertBatchSize - сколько изменений обрабатывается в одной транзакции задачи оповещений
+const alertBatchSize = 500
+
+type WishlistService struct {
+	wishlistRepo storage.WishlistRepository
+	merchRepo    storage.MerchRepository
+	userRepo     storage.UserRepository
+	notifyRepo   storage.NotificationRepository
+	db           *sql.DB
+}
+
+func NewWishlistService(
+	wishlistRepo storage.WishlistRepository,
+	merchRepo storage.MerchRepository,
+	userRepo storage.UserRepository,
+	notifyRepo storage.NotificationRepository,
+	db *sql.DB,
+) *WishlistService {
+	return &WishlistService{
+		wishlistRepo: wishlistRepo,
+		merchRepo:    merchRepo,
+		userRepo:     userRepo,
+		notifyRepo:   notifyRepo,
+		db:           db,
+	}
+}
+
+// GetWishlist возвращает вишлист пользователя и признак общего доступа.
+func (s *WishlistService) GetWishlist(ctx context.Context, userID int64) ([]*domain.WishlistItem, bool, error) {
+	shared, err := s.wishlistRepo.IsShared(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	items, err := s.wishlistRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	return items, shared, nil
+}
+
+// GetSharedWishlist возвращает вишлист другого пользователя. Он виден только коллегам из той же команды
+// и только если владелец открыл доступ.
+func (s *WishlistService) GetSharedWishlist(ctx context.Context, viewerID int64, ownerName string) ([]*domain.WishlistItem, error) {
+	owner, err := s.userRepo.FindByUsername(ctx, ownerName)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if owner.ID == viewerID {
+		return s.wishlistRepo.GetByUser(ctx, owner.ID)
+	}
+
+	viewer, err := s.userRepo.FindByID(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if owner.Team == "" || owner.Team != viewer.Team {
+		return nil, ErrWishlistNotShared
+	}
+	shared, err := s.wishlistRepo.IsShared(ctx, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !shared {
+		return nil, ErrWishlistNotShared
+	}
+	return s.wishlistRepo.GetByUser(ctx, owner.ID)
+}
+
+// AddItem добавляет товар из каталога: FindByName не находит снятые с продажи товары.
+func (s *WishlistService) AddItem(ctx context.Context, userID int64, itemName string) error {
+	item, err := s.merchRepo.FindByName(ctx, itemName)
+	if err != nil {
+		return err
+	}
+	return s.wishlistRepo.Add(ctx, userID, item.ID)
+}
+
+// RemoveItem ищет товар в самом вишлисте, чтобы можно было убрать и снятый с продажи товар.
+func (s *WishlistService) RemoveItem(ctx context.Context, userID int64, itemName string) error {
+	items, err := s.wishlistRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Item.Name == itemName {
+			return s.wishlistRepo.Remove(ctx, userID, item.Item.ID)
+		}
+	}
+	return storage.ErrWishlistItemNotFound
+}
+
+func (s *WishlistService) SetShared(ctx context.Context, userID int64, shared bool) error {
+	return s.wishlistRepo.SetShared(ctx, userID, shared)
+}
+
+// WishlistMerchIDs - id товаров из вишлиста, для отметок в каталоге.
+func (s *WishlistService) WishlistMerchIDs(ctx context.Context, userID int64) (map[int]bool, error) {
+	return s.wishlistRepo.GetMerchIDs(ctx, userID)
+}
+
+// SendAlerts сравнивает товары вишлистов с последним сохраненным состоянием и уведомляет
+// о снижении цены и о появлении товара в наличии. Остальные изменения (рост цены, снижение цены у товара,
+// которого нет в наличии) только запоминаются.
+func (s *WishlistService) SendAlerts(ctx context.Context) error {
+	for {
+		var collected int
+		err := runInTx(ctx, s.db, func(tx *sql.Tx) error {
+			alerts, err := s.wishlistRepo.CollectAlerts(ctx, tx, alertBatchSize)
+			if err != nil {
+				return err
+			}
+			collected = len(alerts)
+			for _, alert := range alerts {
+				if err := s.notifyTx(ctx, tx, alert); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if collected < alertBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *WishlistService) notifyTx(ctx context.Context, tx *sql.Tx, alert *domain.WishlistAlert) error {
+	data := map[string]string{
+		"merchId":  strconv.Itoa(alert.MerchID),
+		"item":     alert.Name,
+		"price":    strconv.Itoa(alert.Price),
+		"currency": alert.Currency,
+	}
+
+	var notification *domain.Notification
+	switch {
+	case alert.BackInStock():
+		notification = &domain.Notification{
+			Kind:    domain.NotificationBackInStock,
+			Message: fmt.Sprintf("%s is back in stock for %d %s", alert.Name, alert.Price, alert.Currency),
+		}
+	case alert.PriceDropped() && alert.InStock:
+		data["oldPrice"] = strconv.Itoa(alert.OldPrice)
+		notification = &domain.Notification{
+			Kind:    domain.NotificationPriceDrop,
+			Message: fmt.Sprintf("%s price dropped from %d to %d %s", alert.Name, alert.OldPrice, alert.Price, alert.Currency),
+		}
+	default:
+		return nil
+	}
+
+	notification.UserID = alert.UserID
+	notification.Data = data
+	if err := s.notifyRepo.Create(ctx, tx, notification); err != nil {
+		return fmt.Errorf("failed to create wishlist notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrAuctionNotFound = errors.New("auction not found")
+)
+
+type AuctionRepository interface {
+	Create(ctx context.Context, tx Tx, auction *domain.Auction) error
+	FindByID(ctx context.Context, id int64) (*domain.Auction, error)
+	FindByIDForUpdate(ctx context.Context, tx Tx, id int64) (*domain.Auction, error)
+	// ClaimDue блокирует один открытый аукцион, время которого вышло; занятые другими пропускаются.
+	ClaimDue(ctx context.Context, tx Tx, now time.Time) (*domain.Auction, error)
+	GetActive(ctx context.Context, now time.Time) ([]*domain.Auction, error)
+	Update(ctx context.Context, tx Tx, auction *domain.Auction) error
+	CreateBid(ctx context.Context, tx Tx, bid *domain.AuctionBid) error
+	GetBids(ctx context.Context, auctionID int64, limit int) ([]*domain.AuctionBid, error)
+}
@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"github.com/lib/pq"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// CatalogChannel - канал NOTIFY, в который триггеры пишут при изменении каталога
+	CatalogChannel = "merch_catalog"
+
+	catalogKey = "catalog:merch"
+
+	// listenerPingInterval - как часто проверять соединение, если уведомлений нет
+	listenerPingInterval = 90 * time.Second
+)
+
+type RedisClient interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+type snapshot struct {
+	items     []*domain.Merch
+	byName    map[string]*domain.Merch
+	expiresAt time.Time
+}
+
+// MerchRepository кэширует каталог (GetAll) в памяти процесса и в Redis.
+// Из кэша обслуживаются GetAllAvailableMerch и FindByName, остальные методы идут в базу.
+// Остатки и остатки тиража меняются на каждой покупке и уведомлений не вызывают, поэтому в кэш не попадают:
+// они дочитываются из базы одним запросом при каждом обращении.
+type MerchRepository struct {
+	storage.MerchRepository
+	redis    RedisClient
+	localTTL time.Duration
+	redisTTL time.Duration
+
+	mu         sync.Mutex
+	current    *snapshot
+	generation uint64
+	// loadMu не дает нескольким запросам одновременно грузить каталог
+	loadMu sync.Mutex
+}
+
+func NewMerchRepository(inner storage.MerchRepository, redisClient RedisClient, localTTL, redisTTL time.Duration) *MerchRepository {
+	return &MerchRepository{
+		MerchRepository: inner,
+		redis:           redisClient,
+		localTTL:        localTTL,
+		redisTTL:        redisTTL,
+	}
+}
+
+func (r *MerchRepository) GetAllAvailableMerch(ctx context.Context) ([]*domain.Merch, error) {
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var result []*domain.Merch
+	for _, m := range snap.items {
+		if m.Active && m.OnSaleAt(now) {
+			result = append(result, copyMerch(m))
+		}
+	}
+	if err := r.fillStock(ctx, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *MerchRepository) FindByName(ctx context.Context, name string) (*domain.Merch, error) {
+	if name == "" {
+		return &domain.Merch{}, storage.ErrMerchNameIsIncorrect
+	}
+	snap, err := r.snapshot(ctx)
+	if err != nil {
+		return &domain.Merch{}, err
+	}
+	m, ok := snap.byName[name]
+	if !ok || !m.Active {
+		return &domain.Merch{}, storage.ErrMerchNotFound
+	}
+	item := copyMerch(m)
+	if err := r.fillStock(ctx, []*domain.Merch{item}); err != nil {
+		return &domain.Merch{}, err
+	}
+	return item, nil
+}
+
+// Invalidate сбрасывает кэш этого процесса и Redis. Загрузки, начатые до сброса, не сохраняются.
+func (r *MerchRepository) Invalidate(ctx context.Context) {
+	r.mu.Lock()
+	r.current = nil
+	r.generation++
+	r.mu.Unlock()
+
+	if err := r.redis.Del(ctx, catalogKey).Err(); err != nil {
+		log.Printf("failed to invalidate catalog cache: %v", err)
+	}
+}
+
+// Listen сбрасывает кэш на каждое уведомление из CatalogChannel до отмены ctx.
+// После переподключения listener присылает nil - уведомления могли потеряться, поэтому кэш тоже сбрасывается.
+func (r *MerchRepository) Listen(ctx context.Context, listener *pq.Listener) {
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.Notify:
+			r.Invalidate(ctx)
+		case <-ticker.C:
+			go func() {
+				if err := listener.Ping(); err != nil {
+					log.Printf("catalog listener ping failed: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+func (r *MerchRepository) snapshot(ctx context.Context) (*snapshot, error) {
+	if snap := r.local(); snap != nil {
+		return snap, nil
+	}
+
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+	if snap := r.local(); snap != nil {
+		return snap, nil
+	}
+
+	r.mu.Lock()
+	generation := r.generation
+	r.mu.Unlock()
+
+	items, fromRedis := r.fromRedis(ctx)
+	if !fromRedis {
+		var err error
+		items, err = r.MerchRepository.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range items {
+		m.Stock = nil
+		m.DropLeft = nil
+	}
+	now := time.Now()
+	snap := newSnapshot(items, expiry(items, now, r.localTTL))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.generation != generation {
+		// каталог изменился во время загрузки: отдаем прочитанное, но не кэшируем
+		return snap, nil
+	}
+	if !fromRedis {
+		r.toRedis(ctx, items, expiry(items, now, r.redisTTL).Sub(now))
+	}
+	if snap.expiresAt.After(now) {
+		r.current = snap
+	}
+	return snap, nil
+}
+
+// fillStock дочитывает из базы текущие остатки товаров.
+func (r *MerchRepository) fillStock(ctx context.Context, items []*domain.Merch) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]int, len(items))
+	for i, m := range items {
+		ids[i] = m.ID
+	}
+	levels, err := r.MerchRepository.GetStock(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, m := range items {
+		if level, ok := levels[m.ID]; ok {
+			m.Stock = level.Stock
+			m.DropLeft = level.DropLeft
+		}
+	}
+	return nil
+}
+
+func (r *MerchRepository) local() *snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current == nil || !time.Now().Before(r.current.expiresAt) {
+		return nil
+	}
+	return r.current
+}
+
+func (r *MerchRepository) fromRedis(ctx context.Context) ([]*domain.Merch, bool) {
+	data, err := r.redis.Get(ctx, catalogKey).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("failed to read catalog cache: %v", err)
+		}
+		return nil, false
+	}
+	var items []*domain.Merch
+	if err := json.Unmarshal(data, &items); err != nil {
+		log.Printf("failed to decode catalog cache: %v", err)
+		return nil, false
+	}
+	return items, true
+}
+
+func (r *MerchRepository) toRedis(ctx context.Context, items []*domain.Merch, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		log.Printf("failed to encode catalog cache: %v", err)
+		return
+	}
+	if err := r.redis.Set(ctx, catalogKey, data, ttl).Err(); err != nil {
+		log.Printf("failed to write catalog cache: %v", err)
+	}
+}
+
+func newSnapshot(items []*domain.Merch, expiresAt time.Time) *snapshot {
+	byName := make(map[string]*domain.Merch, len(items))
+	for _, m := range items {
+		byName[m.Name] = m
+	}
+	return &snapshot{items: items, byName: byName, expiresAt: expiresAt}
+}
+
+// expiry - момент, до которого каталог можно держать в кэше: не дольше ttl и не позже ближайшей смены цены.
+func expiry(items []*domain.Merch, now time.Time, ttl time.Duration) time.Time {
+	expiresAt := now.Add(ttl)
+	for _, m := range items {
+		if m.NextPriceAt != nil && m.NextPriceAt.Before(expiresAt) {
+			expiresAt = *m.NextPriceAt
+		}
+	}
+	return expiresAt
+}
+
+// copyMerch защищает кэш от изменений вызывающим кодом.
+func copyMerch(m *domain.Merch) *domain.Merch {
+	c := *m
+	if m.Stock != nil {
+		stock := *m.Stock
+		c.Stock = &stock
+	}
+	return &c
+}
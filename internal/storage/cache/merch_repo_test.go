@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/services/mocks"
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"encoding/json"
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func catalog() []*domain.Merch {
+	past := time.Now().Add(-time.Hour)
+	return []*domain.Merch{
+		{ID: 1, Name: "t-shirt", Price: 80, Active: true},
+		{ID: 2, Name: "cup", Price: 20, Active: false},
+		{ID: 3, Name: "pen", Price: 10, Active: true, AvailableUntil: &past},
+	}
+}
+
+func TestMerchRepository_ServesFromLocalCache(t *testing.T) {
+	inner := new(mocks.MockMerchRepository)
+	redisClient, redisMock := redismock.NewClientMock()
+	repo := NewMerchRepository(inner, redisClient, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	items := catalog()
+	data, err := json.Marshal(items)
+	require.NoError(t, err)
+	redisMock.ExpectGet(catalogKey).RedisNil()
+	redisMock.ExpectSet(catalogKey, data, time.Minute).SetVal("OK")
+	inner.On("GetAll", mock.Anything).Return(items, nil).Once()
+	inner.On("GetStock", mock.Anything, []int{1}).Return(map[int]*domain.MerchStock{1: {}}, nil)
+
+	available, err := repo.GetAllAvailableMerch(ctx)
+	require.NoError(t, err)
+	require.Len(t, available, 1)
+	assert.Equal(t, "t-shirt", available[0].Name)
+
+	item, err := repo.FindByName(ctx, "t-shirt")
+	require.NoError(t, err)
+	assert.Equal(t, 80, item.Price)
+
+	_, err = repo.FindByName(ctx, "cup")
+	assert.ErrorIs(t, err, storage.ErrMerchNotFound)
+
+	inner.AssertExpectations(t)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestMerchRepository_Invalidate(t *testing.T) {
+	inner := new(mocks.MockMerchRepository)
+	redisClient, redisMock := redismock.NewClientMock()
+	repo := NewMerchRepository(inner, redisClient, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	data, err := json.Marshal(catalog())
+	require.NoError(t, err)
+	redisMock.ExpectGet(catalogKey).SetVal(string(data))
+	redisMock.ExpectDel(catalogKey).SetVal(1)
+	redisMock.ExpectGet(catalogKey).RedisNil()
+	updated := []*domain.Merch{{ID: 1, Name: "t-shirt", Price: 100, Active: true}}
+	updatedData, err := json.Marshal(updated)
+	require.NoError(t, err)
+	redisMock.ExpectSet(catalogKey, updatedData, time.Minute).SetVal("OK")
+	inner.On("GetAll", mock.Anything).Return(updated, nil).Once()
+	inner.On("GetStock", mock.Anything, []int{1}).Return(map[int]*domain.MerchStock{1: {}}, nil)
+
+	item, err := repo.FindByName(ctx, "t-shirt")
+	require.NoError(t, err)
+	assert.Equal(t, 80, item.Price)
+
+	repo.Invalidate(ctx)
+
+	item, err = repo.FindByName(ctx, "t-shirt")
+	require.NoError(t, err)
+	assert.Equal(t, 100, item.Price)
+
+	inner.AssertExpectations(t)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestMerchRepository_StockIsNotCached(t *testing.T) {
+	inner := new(mocks.MockMerchRepository)
+	redisClient, redisMock := redismock.NewClientMock()
+	repo := NewMerchRepository(inner, redisClient, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	dropCap, staleDrop, staleStock := 100, 40, 5
+	items := []*domain.Merch{
+		{ID: 1, Name: "t-shirt", Price: 80, Active: true, Stock: &staleStock},
+		{ID: 4, Name: "sneakers", Price: 500, Active: true, DropCap: &dropCap, DropLeft: &staleDrop},
+	}
+	cached := []*domain.Merch{
+		{ID: 1, Name: "t-shirt", Price: 80, Active: true},
+		{ID: 4, Name: "sneakers", Price: 500, Active: true, DropCap: &dropCap},
+	}
+	data, err := json.Marshal(cached)
+	require.NoError(t, err)
+	redisMock.ExpectGet(catalogKey).RedisNil()
+	redisMock.ExpectSet(catalogKey, data, time.Minute).SetVal("OK")
+	inner.On("GetAll", mock.Anything).Return(items, nil).Once()
+	stock, soldOut, dropLeft, lastPair := 3, 0, 38, 37
+	inner.On("GetStock", mock.Anything, []int{1, 4}).Return(map[int]*domain.MerchStock{
+		1: {Stock: &stock},
+		4: {DropLeft: &dropLeft},
+	}, nil).Once()
+	inner.On("GetStock", mock.Anything, []int{4}).Return(map[int]*domain.MerchStock{4: {DropLeft: &lastPair}}, nil).Once()
+	inner.On("GetStock", mock.Anything, []int{1}).Return(map[int]*domain.MerchStock{1: {Stock: &soldOut}}, nil).Once()
+
+	available, err := repo.GetAllAvailableMerch(ctx)
+	require.NoError(t, err)
+	require.Len(t, available, 2)
+	require.NotNil(t, available[0].Stock)
+	assert.Equal(t, 3, *available[0].Stock)
+	assert.Nil(t, available[0].DropLeft)
+	assert.Nil(t, available[1].Stock)
+	require.NotNil(t, available[1].DropLeft)
+	assert.Equal(t, 38, *available[1].DropLeft)
+
+	item, err := repo.FindByName(ctx, "sneakers")
+	require.NoError(t, err)
+	require.NotNil(t, item.DropLeft)
+	assert.Equal(t, 37, *item.DropLeft)
+
+	// распродажа видна сразу, без сброса кэша
+	item, err = repo.FindByName(ctx, "t-shirt")
+	require.NoError(t, err)
+	require.NotNil(t, item.Stock)
+	assert.Equal(t, 0, *item.Stock)
+
+	inner.AssertExpectations(t)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestMerchRepository_ExpiresAtScheduledPrice(t *testing.T) {
+	now := time.Now()
+	next := now.Add(5 * time.Second)
+	items := []*domain.Merch{{ID: 1, Name: "t-shirt", Active: true}, {ID: 2, Name: "cup", Active: true, NextPriceAt: &next}}
+
+	assert.Equal(t, next, expiry(items, now, time.Minute))
+	assert.Equal(t, now.Add(time.Second), expiry(items, now, time.Second))
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrCartItemNotFound = errors.New("cart item not found")
+)
+
+type CartRepository interface {
+	GetByUser(ctx context.Context, tx *sql.Tx, userID int64) ([]*domain.CartItem, error)
+	AddItem(ctx context.Context, userID int64, merchID int, variantID int, quantity int) error
+	RemoveItem(ctx context.Context, userID int64, merchID int, variantID int) error
+	Clear(ctx context.Context, tx Tx, userID int64) error
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrGroupBuyNotFound = errors.New("group buy not found")
+)
+
+type GroupBuyRepository interface {
+	Create(ctx context.Context, tx Tx, groupBuy *domain.GroupBuy) error
+	FindByID(ctx context.Context, id int64) (*domain.GroupBuy, error)
+	FindByIDForUpdate(ctx context.Context, tx Tx, id int64) (*domain.GroupBuy, error)
+	// ClaimExpired блокирует одну открытую совместную покупку с истекшим сроком; занятые другими пропускаются.
+	ClaimExpired(ctx context.Context, tx Tx, now time.Time) (*domain.GroupBuy, error)
+	GetOpen(ctx context.Context) ([]*domain.GroupBuy, error)
+	// CountOpenByCreator блокирует создателя до конца транзакции и считает его открытые сборы.
+	CountOpenByCreator(ctx context.Context, tx *sql.Tx, creatorID int64) (int, error)
+	Update(ctx context.Context, tx Tx, groupBuy *domain.GroupBuy) error
+	CreatePledge(ctx context.Context, tx Tx, pledge *domain.GroupBuyPledge) error
+	// GetPledges возвращает взносы по порядку, tx может быть nil.
+	GetPledges(ctx context.Context, tx *sql.Tx, groupBuyID int64) ([]*domain.GroupBuyPledge, error)
+}
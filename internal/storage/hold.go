@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrHoldNotFound = errors.New("hold not found")
+)
+
+type HoldRepository interface {
+	Create(ctx context.Context, tx Tx, hold *domain.Hold) error
+	FindByIDForUpdate(ctx context.Context, tx Tx, id int64) (*domain.Hold, error)
+	Update(ctx context.Context, tx Tx, hold *domain.Hold) error
+	GetActiveByUser(ctx context.Context, userID int64) ([]*domain.Hold, error)
+	ExpireDue(ctx context.Context, now time.Time) (int64, error)
+}
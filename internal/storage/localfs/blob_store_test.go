@@ -0,0 +1,45 @@
+package localfs
+
+import (
+	"avito-backend-intern-winter25/internal/storage"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBlobStore_RoundTrip(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir(), "/media/")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	size, err := store.Put(ctx, "merch-1-a.png", strings.NewReader("image"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), size)
+	assert.Equal(t, "/media/merch-1-a.png", store.URL("merch-1-a.png"))
+
+	blob, err := store.Open(ctx, "merch-1-a.png")
+	require.NoError(t, err)
+	data, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	require.NoError(t, blob.Close())
+	assert.Equal(t, "image", string(data))
+
+	require.NoError(t, store.Delete(ctx, "merch-1-a.png"))
+	_, err = store.Open(ctx, "merch-1-a.png")
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+}
+
+func TestBlobStore_RejectsPaths(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir(), "/media")
+	require.NoError(t, err)
+
+	for _, key := range []string{"../secret", "a/b.png", ".upload-1", ""} {
+		_, err := store.Put(context.Background(), key, strings.NewReader("x"))
+		assert.Error(t, err, key)
+		_, err = store.Open(context.Background(), key)
+		assert.ErrorIs(t, err, storage.ErrBlobNotFound, key)
+	}
+}
@@ -9,9 +9,26 @@ import (
 var (
 	ErrMerchNameIsIncorrect = errors.New("MerchName is incorrect")
 	ErrMerchNotFound        = errors.New("merch not found")
+	ErrMerchNameTaken       = errors.New("merch name is already taken")
+	ErrMerchOutOfStock      = errors.New("merch is out of stock")
 )
 
 type MerchRepository interface {
 	GetAllAvailableMerch(ctx context.Context) ([]*domain.Merch, error)
 	FindByName(ctx context.Context, name string) (*domain.Merch, error)
+	GetAll(ctx context.Context) ([]*domain.Merch, error)
+	Search(ctx context.Context, filter domain.MerchFilter) (*domain.MerchPage, error)
+	FindByID(ctx context.Context, id int) (*domain.Merch, error)
+	FindByIDForUpdate(ctx context.Context, tx Tx, id int) (*domain.Merch, error)
+	Create(ctx context.Context, tx Tx, merch *domain.Merch) error
+	Update(ctx context.Context, tx Tx, merch *domain.Merch) error
+	DecrementStock(ctx context.Context, tx Tx, id int, quantity int) (*int, error)
+	IncrementStock(ctx context.Context, tx Tx, id int, quantity int) error
+	// GetStock возвращает текущие остатки и остатки тиража по id товаров.
+	GetStock(ctx context.Context, merchIDs []int) (map[int]*domain.MerchStock, error)
+}
+
+type MerchAuditRepository interface {
+	Create(ctx context.Context, tx Tx, entry *domain.MerchAudit) error
+	GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchAudit, error)
 }
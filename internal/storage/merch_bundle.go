@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"database/sql"
+)
+
+type MerchBundleRepository interface {
+	AddComponent(ctx context.Context, tx Tx, component *domain.BundleComponent) error
+	// GetComponents возвращает составляющие набора в порядке id товара, tx может быть nil.
+	GetComponents(ctx context.Context, tx *sql.Tx, bundleID int) ([]*domain.BundleComponent, error)
+	// GetBundleNames возвращает названия активных наборов, в которые входит товар.
+	GetBundleNames(ctx context.Context, tx *sql.Tx, componentID int) ([]string, error)
+}
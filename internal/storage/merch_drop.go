@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrDropSoldOut = errors.New("drop is sold out")
+)
+
+type MerchDropRepository interface {
+	// Set задает окно продаж и тираж; уже проданное при смене тиража вычитается из нового.
+	Set(ctx context.Context, tx *sql.Tx, drop *domain.MerchDrop) error
+	Take(ctx context.Context, tx *sql.Tx, merchID int, quantity int) error
+	Release(ctx context.Context, tx *sql.Tx, merchID int, quantity int) error
+}
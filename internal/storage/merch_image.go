@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+)
+
+var ErrMerchImageNotFound = errors.New("merch image not found")
+
+type MerchImageRepository interface {
+	Create(ctx context.Context, tx Tx, image *domain.MerchImage) error
+	GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchImage, error)
+	Delete(ctx context.Context, tx Tx, merchID int, imageID int64) (*domain.MerchImage, error)
+}
@@ -0,0 +1,11 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+)
+
+type MerchPriceRepository interface {
+	Create(ctx context.Context, tx Tx, price *domain.MerchPrice) error
+	GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchPrice, error)
+}
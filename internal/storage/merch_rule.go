@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+)
+
+var (
+	ErrMerchRuleNotFound = errors.New("merch purchase rule not found")
+)
+
+type MerchRuleRepository interface {
+	Get(ctx context.Context, merchID int) (*domain.MerchPurchaseRule, error)
+	GetByMerchIDs(ctx context.Context, merchIDs []int) (map[int]*domain.MerchPurchaseRule, error)
+	Upsert(ctx context.Context, tx Tx, rule *domain.MerchPurchaseRule) error
+	Delete(ctx context.Context, tx Tx, merchID int) error
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+)
+
+var (
+	ErrVariantNotFound   = errors.New("merch variant not found")
+	ErrVariantTaken      = errors.New("merch variant already exists")
+	ErrVariantOutOfStock = errors.New("merch variant is out of stock")
+)
+
+type MerchVariantRepository interface {
+	Create(ctx context.Context, tx Tx, variant *domain.MerchVariant) error
+	Update(ctx context.Context, tx Tx, variant *domain.MerchVariant) error
+	GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchVariant, error)
+	FindBySKU(ctx context.Context, sku string) (*domain.MerchVariant, error)
+	FindByIDForUpdate(ctx context.Context, tx Tx, id int) (*domain.MerchVariant, error)
+	DecrementStock(ctx context.Context, tx Tx, id int, quantity int) (*int, error)
+	IncrementStock(ctx context.Context, tx Tx, id int, quantity int) error
+}
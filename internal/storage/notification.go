@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, tx Tx, notification *domain.Notification) error
+	GetByUser(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]*domain.Notification, error)
+	MarkRead(ctx context.Context, userID, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+}
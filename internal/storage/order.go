@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+)
+
+var (
+	ErrOrderNotFound = errors.New("order not found")
+)
+
+type OrderRepository interface {
+	Create(ctx context.Context, tx Tx, order *domain.Order) error
+	FindByID(ctx context.Context, id int64) (*domain.Order, error)
+	FindByIDForUpdate(ctx context.Context, tx Tx, id int64) (*domain.Order, error)
+	GetByUser(ctx context.Context, userID int64) ([]*domain.Order, error)
+	GetByStatus(ctx context.Context, status string) ([]*domain.Order, error)
+	UpdateStatus(ctx context.Context, tx Tx, order *domain.Order, change *domain.OrderStatusChange) error
+}
@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// лучшая ставка читается вместе с аукционом, у аукциона без ставок ее колонки NULL
+const auctionColumns = `a.id, a.merch_id, m.name, a.currency, a.starting_price, a.min_increment, a.anti_snipe_seconds,
+    a.starts_at, a.ends_at, a.status, COALESCE(a.order_id, 0), a.created_by, a.created_at, a.settled_at,
+    b.id, b.user_id, bu.username, b.amount, b.hold_id, b.created_at
+    FROM auctions a
+    JOIN merch m ON m.id = a.merch_id
+    LEFT JOIN auction_bids b ON b.id = a.top_bid_id
+    LEFT JOIN users bu ON bu.id = b.user_id`
+
+type AuctionRepository struct {
+	db *sql.DB
+}
+
+func NewAuctionRepository(db *sql.DB) *AuctionRepository {
+	return &AuctionRepository{db: db}
+}
+
+func scanAuction(row rowScanner) (*domain.Auction, error) {
+	var a domain.Auction
+	var antiSnipe int
+	var settledAt sql.NullTime
+	var bidID, bidUserID, bidAmount, bidHoldID sql.NullInt64
+	var bidUsername sql.NullString
+	var bidCreatedAt sql.NullTime
+	err := row.Scan(&a.ID, &a.MerchID, &a.Item, &a.Currency, &a.StartingPrice, &a.MinIncrement, &antiSnipe,
+		&a.StartsAt, &a.EndsAt, &a.Status, &a.OrderID, &a.CreatedBy, &a.CreatedAt, &settledAt,
+		&bidID, &bidUserID, &bidUsername, &bidAmount, &bidHoldID, &bidCreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	a.AntiSnipe = time.Duration(antiSnipe) * time.Second
+	if settledAt.Valid {
+		a.SettledAt = &settledAt.Time
+	}
+	if bidID.Valid {
+		a.TopBid = &domain.AuctionBid{
+			ID:        bidID.Int64,
+			AuctionID: a.ID,
+			UserID:    bidUserID.Int64,
+			Username:  bidUsername.String,
+			Amount:    int(bidAmount.Int64),
+			HoldID:    bidHoldID.Int64,
+			CreatedAt: bidCreatedAt.Time,
+		}
+	}
+	return &a, nil
+}
+
+func (r *AuctionRepository) Create(ctx context.Context, tx storage.Tx, auction *domain.Auction) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO auctions (merch_id, currency, starting_price, min_increment, anti_snipe_seconds, starts_at, ends_at,
+                              status, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id
+    `
+	if auction.CreatedAt.IsZero() {
+		auction.CreatedAt = time.Now()
+	}
+	auction.Status = domain.AuctionStatusOpen
+	err := tx.QueryRowContext(ctx, query,
+		auction.MerchID,
+		auction.Currency,
+		auction.StartingPrice,
+		auction.MinIncrement,
+		int(auction.AntiSnipe/time.Second),
+		auction.StartsAt,
+		auction.EndsAt,
+		auction.Status,
+		auction.CreatedBy,
+		auction.CreatedAt,
+	).Scan(&auction.ID)
+	if err != nil {
+		return fmt.Errorf("create auction failed: %w", err)
+	}
+	return nil
+}
+
+func (r *AuctionRepository) FindByID(ctx context.Context, id int64) (*domain.Auction, error) {
+	auction, err := scanAuction(r.db.QueryRowContext(ctx, `SELECT `+auctionColumns+` WHERE a.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrAuctionNotFound
+		}
+		return nil, fmt.Errorf("failed to find auction: %w", err)
+	}
+	return auction, nil
+}
+
+func (r *AuctionRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Auction, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	auction, err := scanAuction(tx.QueryRowContext(ctx, `SELECT `+auctionColumns+` WHERE a.id = $1 FOR UPDATE OF a`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrAuctionNotFound
+		}
+		return nil, fmt.Errorf("failed to find auction: %w", err)
+	}
+	return auction, nil
+}
+
+func (r *AuctionRepository) ClaimDue(ctx context.Context, tx storage.Tx, now time.Time) (*domain.Auction, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT ` + auctionColumns + `
+        WHERE a.status = 'open' AND a.ends_at <= $1
+        ORDER BY a.ends_at, a.id
+        LIMIT 1
+        FOR UPDATE OF a SKIP LOCKED
+    `
+	auction, err := scanAuction(tx.QueryRowContext(ctx, query, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrAuctionNotFound
+		}
+		return nil, fmt.Errorf("failed to claim auction: %w", err)
+	}
+	return auction, nil
+}
+
+// GetActive возвращает идущие и будущие аукционы, ближайшие к завершению первыми.
+func (r *AuctionRepository) GetActive(ctx context.Context, now time.Time) ([]*domain.Auction, error) {
+	query := `SELECT ` + auctionColumns + ` WHERE a.status = 'open' AND a.ends_at > $1 ORDER BY a.ends_at, a.id`
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var auctions []*domain.Auction
+	for rows.Next() {
+		auction, err := scanAuction(rows)
+		if err != nil {
+			return nil, err
+		}
+		auctions = append(auctions, auction)
+	}
+	return auctions, rows.Err()
+}
+
+func (r *AuctionRepository) Update(ctx context.Context, tx storage.Tx, auction *domain.Auction) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE auctions
+        SET ends_at = $2, status = $3, top_bid_id = $4, order_id = $5, settled_at = $6
+        WHERE id = $1
+    `
+	var topBidID, orderID sql.NullInt64
+	if auction.TopBid != nil {
+		topBidID = sql.NullInt64{Int64: auction.TopBid.ID, Valid: true}
+	}
+	if auction.OrderID != 0 {
+		orderID = sql.NullInt64{Int64: auction.OrderID, Valid: true}
+	}
+	res, err := tx.ExecContext(ctx, query, auction.ID, auction.EndsAt, auction.Status, topBidID, orderID, auction.SettledAt)
+	if err != nil {
+		return fmt.Errorf("update auction failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrAuctionNotFound
+	}
+	return nil
+}
+
+func (r *AuctionRepository) CreateBid(ctx context.Context, tx storage.Tx, bid *domain.AuctionBid) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO auction_bids (auction_id, user_id, amount, hold_id, created_at)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id
+    `
+	if bid.CreatedAt.IsZero() {
+		bid.CreatedAt = time.Now()
+	}
+	if err := tx.QueryRowContext(ctx, query, bid.AuctionID, bid.UserID, bid.Amount, bid.HoldID, bid.CreatedAt).Scan(&bid.ID); err != nil {
+		return fmt.Errorf("create auction bid failed: %w", err)
+	}
+	return nil
+}
+
+// GetBids возвращает ставки аукциона, старшие первыми.
+func (r *AuctionRepository) GetBids(ctx context.Context, auctionID int64, limit int) ([]*domain.AuctionBid, error) {
+	query := `
+        SELECT b.id, b.auction_id, b.user_id, u.username, b.amount, b.hold_id, b.created_at
+        FROM auction_bids b
+        JOIN users u ON u.id = b.user_id
+        WHERE b.auction_id = $1
+        ORDER BY b.amount DESC, b.id DESC
+        LIMIT $2
+    `
+	rows, err := r.db.QueryContext(ctx, query, auctionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bids []*domain.AuctionBid
+	for rows.Next() {
+		var b domain.AuctionBid
+		if err := rows.Scan(&b.ID, &b.AuctionID, &b.UserID, &b.Username, &b.Amount, &b.HoldID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		bids = append(bids, &b)
+	}
+	return bids, rows.Err()
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type CartRepository struct {
+	db *sql.DB
+}
+
+func NewCartRepository(db *sql.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+// GetByUser возвращает корзину вместе с текущими данными товаров.
+// В транзакции строки корзины блокируются, чтобы одну корзину нельзя было оформить дважды.
+func (r *CartRepository) GetByUser(ctx context.Context, tx *sql.Tx, userID int64) ([]*domain.CartItem, error) {
+	query := `
+        SELECT ` + merchColumns + `, c.user_id, c.quantity, c.added_at,
+            v.id, v.sku, v.size, v.color, v.price_delta, v.stock, v.active
+        FROM cart_items c
+        JOIN merch ON merch.id = c.merch_id
+        LEFT JOIN merch_variants v ON v.id = c.variant_id
+        WHERE c.user_id = $1
+        ORDER BY c.added_at, merch.id, c.variant_id
+    `
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query+` FOR UPDATE OF c`, userID)
+	} else {
+		rows, err = r.db.QueryContext(ctx, query, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*domain.CartItem
+	for rows.Next() {
+		var item domain.CartItem
+		var variantID, priceDelta, stock sql.NullInt64
+		var sku, size, color sql.NullString
+		var active sql.NullBool
+		m, err := scanMerch(rows, &item.UserID, &item.Quantity, &item.AddedAt,
+			&variantID, &sku, &size, &color, &priceDelta, &stock, &active)
+		if err != nil {
+			return nil, err
+		}
+		item.Item = m
+		if variantID.Valid {
+			item.Variant = &domain.MerchVariant{
+				ID:         int(variantID.Int64),
+				MerchID:    m.ID,
+				SKU:        sku.String,
+				Size:       size.String,
+				Color:      color.String,
+				PriceDelta: int(priceDelta.Int64),
+				Stock:      nullIntPtr(stock),
+				Active:     active.Bool,
+			}
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+// AddItem добавляет товар в корзину, повторное добавление увеличивает количество.
+// variantID == 0 - товар без вариантов.
+func (r *CartRepository) AddItem(ctx context.Context, userID int64, merchID int, variantID int, quantity int) error {
+	query := `
+        INSERT INTO cart_items (user_id, merch_id, variant_id, quantity)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (user_id, merch_id, variant_id) DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity
+    `
+	if _, err := r.db.ExecContext(ctx, query, userID, merchID, variantID, quantity); err != nil {
+		return fmt.Errorf("add cart item failed: %w", err)
+	}
+	return nil
+}
+
+func (r *CartRepository) RemoveItem(ctx context.Context, userID int64, merchID int, variantID int) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1 AND merch_id = $2 AND variant_id = $3`,
+		userID, merchID, variantID)
+	if err != nil {
+		return fmt.Errorf("remove cart item failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrCartItemNotFound
+	}
+	return nil
+}
+
+func (r *CartRepository) Clear(ctx context.Context, tx storage.Tx, userID int64) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("clear cart failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// взнос с освобожденным или истекшим холдом в собранную сумму не входит
+const groupBuyColumns = `g.id, g.merch_id, m.name, g.quantity, g.currency, g.target,
+    COALESCE((
+        SELECT SUM(p.amount)
+        FROM group_buy_pledges p
+        JOIN balance_holds h ON h.id = p.hold_id
+        WHERE p.group_buy_id = g.id
+          AND (h.status = 'captured' OR (h.status = 'active' AND h.expires_at > now()))
+    ), 0),
+    g.creator_id, u.username, g.deadline, g.status, COALESCE(g.order_id, 0), g.created_at, g.closed_at
+    FROM group_buys g
+    JOIN merch m ON m.id = g.merch_id
+    JOIN users u ON u.id = g.creator_id`
+
+type GroupBuyRepository struct {
+	db *sql.DB
+}
+
+func NewGroupBuyRepository(db *sql.DB) *GroupBuyRepository {
+	return &GroupBuyRepository{db: db}
+}
+
+func scanGroupBuy(row rowScanner) (*domain.GroupBuy, error) {
+	var g domain.GroupBuy
+	var closedAt sql.NullTime
+	err := row.Scan(&g.ID, &g.MerchID, &g.Item, &g.Quantity, &g.Currency, &g.Target, &g.Pledged,
+		&g.CreatorID, &g.Creator, &g.Deadline, &g.Status, &g.OrderID, &g.CreatedAt, &closedAt)
+	if err != nil {
+		return nil, err
+	}
+	if closedAt.Valid {
+		g.ClosedAt = &closedAt.Time
+	}
+	return &g, nil
+}
+
+func (r *GroupBuyRepository) Create(ctx context.Context, tx storage.Tx, groupBuy *domain.GroupBuy) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO group_buys (merch_id, quantity, currency, target, creator_id, deadline, status, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+    `
+	if groupBuy.CreatedAt.IsZero() {
+		groupBuy.CreatedAt = time.Now()
+	}
+	groupBuy.Status = domain.GroupBuyStatusOpen
+	err := tx.QueryRowContext(ctx, query,
+		groupBuy.MerchID,
+		groupBuy.Quantity,
+		groupBuy.Currency,
+		groupBuy.Target,
+		groupBuy.CreatorID,
+		groupBuy.Deadline,
+		groupBuy.Status,
+		groupBuy.CreatedAt,
+	).Scan(&groupBuy.ID)
+	if err != nil {
+		return fmt.Errorf("create group buy failed: %w", err)
+	}
+	return nil
+}
+
+func (r *GroupBuyRepository) FindByID(ctx context.Context, id int64) (*domain.GroupBuy, error) {
+	groupBuy, err := scanGroupBuy(r.db.QueryRowContext(ctx, `SELECT `+groupBuyColumns+` WHERE g.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrGroupBuyNotFound
+		}
+		return nil, fmt.Errorf("failed to find group buy: %w", err)
+	}
+	return groupBuy, nil
+}
+
+func (r *GroupBuyRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.GroupBuy, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	groupBuy, err := scanGroupBuy(tx.QueryRowContext(ctx, `SELECT `+groupBuyColumns+` WHERE g.id = $1 FOR UPDATE OF g`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrGroupBuyNotFound
+		}
+		return nil, fmt.Errorf("failed to find group buy: %w", err)
+	}
+	return groupBuy, nil
+}
+
+func (r *GroupBuyRepository) ClaimExpired(ctx context.Context, tx storage.Tx, now time.Time) (*domain.GroupBuy, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT ` + groupBuyColumns + `
+        WHERE g.status = 'open' AND g.deadline <= $1
+        ORDER BY g.deadline, g.id
+        LIMIT 1
+        FOR UPDATE OF g SKIP LOCKED
+    `
+	groupBuy, err := scanGroupBuy(tx.QueryRowContext(ctx, query, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrGroupBuyNotFound
+		}
+		return nil, fmt.Errorf("failed to claim group buy: %w", err)
+	}
+	return groupBuy, nil
+}
+
+// GetOpen возвращает открытые совместные покупки, ближайшие к сроку первыми.
+func (r *GroupBuyRepository) GetOpen(ctx context.Context) ([]*domain.GroupBuy, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+groupBuyColumns+` WHERE g.status = 'open' ORDER BY g.deadline, g.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groupBuys []*domain.GroupBuy
+	for rows.Next() {
+		groupBuy, err := scanGroupBuy(rows)
+		if err != nil {
+			return nil, err
+		}
+		groupBuys = append(groupBuys, groupBuy)
+	}
+	return groupBuys, rows.Err()
+}
+
+// CountOpenByCreator считает открытые сборы создателя. До конца транзакции держится advisory-блокировка создателя,
+// иначе параллельные запросы одного сотрудника могли бы обойти лимит.
+func (r *GroupBuyRepository) CountOpenByCreator(ctx context.Context, tx *sql.Tx, creatorID int64) (int, error) {
+	if tx == nil {
+		return 0, errs.ErrTransactionNotFound
+	}
+	// ключи из двух чисел не пересекаются с блокировками CountUserItem
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('group_buys'), $1::int)`, creatorID); err != nil {
+		return 0, fmt.Errorf("failed to lock creator group buys: %w", err)
+	}
+
+	var count int
+	err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM group_buys WHERE creator_id = $1 AND status = 'open'`, creatorID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count open group buys failed: %w", err)
+	}
+	return count, nil
+}
+
+func (r *GroupBuyRepository) Update(ctx context.Context, tx storage.Tx, groupBuy *domain.GroupBuy) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `UPDATE group_buys SET status = $2, order_id = $3, closed_at = $4 WHERE id = $1`
+	var orderID sql.NullInt64
+	if groupBuy.OrderID != 0 {
+		orderID = sql.NullInt64{Int64: groupBuy.OrderID, Valid: true}
+	}
+	res, err := tx.ExecContext(ctx, query, groupBuy.ID, groupBuy.Status, orderID, groupBuy.ClosedAt)
+	if err != nil {
+		return fmt.Errorf("update group buy failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrGroupBuyNotFound
+	}
+	return nil
+}
+
+func (r *GroupBuyRepository) CreatePledge(ctx context.Context, tx storage.Tx, pledge *domain.GroupBuyPledge) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO group_buy_pledges (group_buy_id, user_id, amount, hold_id, created_at)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id
+    `
+	if pledge.CreatedAt.IsZero() {
+		pledge.CreatedAt = time.Now()
+	}
+	err := tx.QueryRowContext(ctx, query,
+		pledge.GroupBuyID, pledge.UserID, pledge.Amount, pledge.HoldID, pledge.CreatedAt,
+	).Scan(&pledge.ID)
+	if err != nil {
+		return fmt.Errorf("create group buy pledge failed: %w", err)
+	}
+	return nil
+}
+
+func (r *GroupBuyRepository) GetPledges(ctx context.Context, tx *sql.Tx, groupBuyID int64) ([]*domain.GroupBuyPledge, error) {
+	query := `
+        SELECT p.id, p.group_buy_id, p.user_id, u.username, p.amount, p.hold_id, p.created_at
+        FROM group_buy_pledges p
+        JOIN users u ON u.id = p.user_id
+        WHERE p.group_buy_id = $1
+        ORDER BY p.id
+    `
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, groupBuyID)
+	} else {
+		rows, err = r.db.QueryContext(ctx, query, groupBuyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pledges []*domain.GroupBuyPledge
+	for rows.Next() {
+		var p domain.GroupBuyPledge
+		if err := rows.Scan(&p.ID, &p.GroupBuyID, &p.UserID, &p.Username, &p.Amount, &p.HoldID, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		pledges = append(pledges, &p)
+	}
+	return pledges, rows.Err()
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type HoldRepository struct {
+	db *sql.DB
+}
+
+func NewHoldRepository(db *sql.DB) *HoldRepository {
+	return &HoldRepository{db: db}
+}
+
+func (r *HoldRepository) Create(ctx context.Context, tx storage.Tx, hold *domain.Hold) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        INSERT INTO balance_holds (user_id, currency, amount, status, reason, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+    `
+	if hold.CreatedAt.IsZero() {
+		hold.CreatedAt = time.Now()
+	}
+	if hold.Currency == "" {
+		hold.Currency = domain.PrimaryCurrency
+	}
+	if hold.Status == "" {
+		hold.Status = domain.HoldStatusActive
+	}
+	return tx.QueryRowContext(ctx, query,
+		hold.UserID,
+		hold.Currency,
+		hold.Amount,
+		hold.Status,
+		hold.Reason,
+		hold.ExpiresAt,
+		hold.CreatedAt,
+	).Scan(&hold.ID)
+}
+
+func (r *HoldRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Hold, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT id, user_id, currency, amount, captured_amount, status, reason, expires_at, captured_at, created_at
+        FROM balance_holds
+        WHERE id = $1
+        FOR UPDATE
+    `
+	var h domain.Hold
+	var capturedAt sql.NullTime
+	err := tx.QueryRowContext(ctx, query, id).Scan(&h.ID, &h.UserID, &h.Currency, &h.Amount, &h.CapturedAmount,
+		&h.Status, &h.Reason, &h.ExpiresAt, &capturedAt, &h.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrHoldNotFound
+		}
+		return nil, err
+	}
+	if capturedAt.Valid {
+		h.CapturedAt = &capturedAt.Time
+	}
+	return &h, nil
+}
+
+func (r *HoldRepository) Update(ctx context.Context, tx storage.Tx, hold *domain.Hold) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE balance_holds
+        SET captured_amount = $1, status = $2, captured_at = $3, updated_at = now()
+        WHERE id = $4
+    `
+	res, err := tx.ExecContext(ctx, query, hold.CapturedAmount, hold.Status, hold.CapturedAt, hold.ID)
+	if err != nil {
+		return fmt.Errorf("update hold failed: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrHoldNotFound
+	}
+	return nil
+}
+
+func (r *HoldRepository) GetActiveByUser(ctx context.Context, userID int64) ([]*domain.Hold, error) {
+	query := `
+        SELECT id, user_id, currency, amount, captured_amount, status, reason, expires_at, created_at
+        FROM balance_holds
+        WHERE user_id = $1 AND status = 'active' AND expires_at > now()
+        ORDER BY created_at DESC
+    `
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []*domain.Hold
+	for rows.Next() {
+		var h domain.Hold
+		if err := rows.Scan(&h.ID, &h.UserID, &h.Currency, &h.Amount, &h.CapturedAmount,
+			&h.Status, &h.Reason, &h.ExpiresAt, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		holds = append(holds, &h)
+	}
+	return holds, rows.Err()
+}
+
+func (r *HoldRepository) ExpireDue(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+        UPDATE balance_holds
+        SET status = 'expired', updated_at = now()
+        WHERE status = 'active' AND expires_at <= $1
+    `
+	res, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type MerchAuditRepository struct {
+	db *sql.DB
+}
+
+func NewMerchAuditRepository(db *sql.DB) *MerchAuditRepository {
+	return &MerchAuditRepository{db: db}
+}
+
+func (r *MerchAuditRepository) Create(ctx context.Context, tx storage.Tx, entry *domain.MerchAudit) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO merch_audit (merch_id, admin_id, action, field, old_value, new_value, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+    `
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	return tx.QueryRowContext(ctx, query,
+		entry.MerchID,
+		entry.AdminID,
+		entry.Action,
+		entry.Field,
+		entry.OldValue,
+		entry.NewValue,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+func (r *MerchAuditRepository) GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchAudit, error) {
+	query := `
+        SELECT id, merch_id, admin_id, action, field, old_value, new_value, created_at
+        FROM merch_audit
+        WHERE merch_id = $1
+        ORDER BY created_at DESC, id DESC
+    `
+	rows, err := r.db.QueryContext(ctx, query, merchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.MerchAudit
+	for rows.Next() {
+		var e domain.MerchAudit
+		if err := rows.Scan(&e.ID, &e.MerchID, &e.AdminID, &e.Action, &e.Field, &e.OldValue, &e.NewValue, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type MerchBundleRepository struct {
+	db *sql.DB
+}
+
+func NewMerchBundleRepository(db *sql.DB) *MerchBundleRepository {
+	return &MerchBundleRepository{db: db}
+}
+
+func (r *MerchBundleRepository) AddComponent(ctx context.Context, tx storage.Tx, component *domain.BundleComponent) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO merch_bundle_components (bundle_id, component_id, quantity)
+        VALUES ($1, $2, $3)
+    `
+	if _, err := tx.ExecContext(ctx, query, component.BundleID, component.Item.ID, component.Quantity); err != nil {
+		return fmt.Errorf("add bundle component failed: %w", err)
+	}
+	return nil
+}
+
+func (r *MerchBundleRepository) GetComponents(ctx context.Context, tx *sql.Tx, bundleID int) ([]*domain.BundleComponent, error) {
+	query := `
+        SELECT ` + merchColumns + `, bc.quantity
+        FROM merch_bundle_components bc
+        JOIN merch ON merch.id = bc.component_id
+        WHERE bc.bundle_id = $1
+        ORDER BY merch.id
+    `
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, bundleID)
+	} else {
+		rows, err = r.db.QueryContext(ctx, query, bundleID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []*domain.BundleComponent
+	for rows.Next() {
+		c := &domain.BundleComponent{BundleID: bundleID}
+		c.Item, err = scanMerch(rows, &c.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, rows.Err()
+}
+
+func (r *MerchBundleRepository) GetBundleNames(ctx context.Context, tx *sql.Tx, componentID int) ([]string, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT m.name
+        FROM merch_bundle_components bc
+        JOIN merch m ON m.id = bc.bundle_id
+        WHERE bc.component_id = $1 AND m.active
+        ORDER BY m.name
+    `
+	rows, err := tx.QueryContext(ctx, query, componentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
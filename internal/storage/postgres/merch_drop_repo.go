@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// merchDropShards - на сколько строк делится тираж; больше шардов - меньше ожидания на старте дропа
+const merchDropShards = 16
+
+type MerchDropRepository struct {
+	db *sql.DB
+}
+
+func NewMerchDropRepository(db *sql.DB) *MerchDropRepository {
+	return &MerchDropRepository{db: db}
+}
+
+// Set задает окно продаж и тираж товара. При смене тиража шарды пересоздаются,
+// уже проданное по старому тиражу вычитается из нового.
+func (r *MerchDropRepository) Set(ctx context.Context, tx *sql.Tx, drop *domain.MerchDrop) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	var current sql.NullInt64
+	err := tx.QueryRowContext(ctx, `SELECT drop_cap FROM merch WHERE id = $1 FOR UPDATE`, drop.MerchID).Scan(&current)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrMerchNotFound
+		}
+		return fmt.Errorf("failed to find merch: %w", err)
+	}
+
+	oldCap := nullIntPtr(current)
+	if !sameCap(oldCap, drop.Cap) {
+		if err := r.resetShards(ctx, tx, drop.MerchID, oldCap, drop.Cap); err != nil {
+			return err
+		}
+	}
+
+	query := `
+        UPDATE merch
+        SET available_from = $2, available_until = $3, drop_cap = $4, updated_at = now()
+        WHERE id = $1
+    `
+	if _, err := tx.ExecContext(ctx, query, drop.MerchID, drop.AvailableFrom, drop.AvailableUntil, drop.Cap); err != nil {
+		return fmt.Errorf("set merch drop failed: %w", err)
+	}
+	return nil
+}
+
+func (r *MerchDropRepository) resetShards(ctx context.Context, tx *sql.Tx, merchID int, oldCap, newCap *int) error {
+	// шарды блокируются в порядке номера, как в медленном пути Take
+	var left int
+	query := `
+        SELECT COALESCE(SUM(remaining), 0)
+        FROM (SELECT remaining FROM merch_drop_shards WHERE merch_id = $1 ORDER BY shard FOR UPDATE) s
+    `
+	if err := tx.QueryRowContext(ctx, query, merchID).Scan(&left); err != nil {
+		return fmt.Errorf("failed to lock drop shards: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM merch_drop_shards WHERE merch_id = $1`, merchID); err != nil {
+		return fmt.Errorf("failed to delete drop shards: %w", err)
+	}
+	if newCap == nil {
+		return nil
+	}
+
+	sold := 0
+	if oldCap != nil {
+		sold = *oldCap - left
+	}
+	remaining := max(*newCap-sold, 0)
+	shards := min(merchDropShards, max(remaining, 1))
+	query = `
+        INSERT INTO merch_drop_shards (merch_id, shard, remaining)
+        SELECT $1, s, $2::int / $3::int + CASE WHEN s < $2::int % $3::int THEN 1 ELSE 0 END
+        FROM generate_series(0, $3::int - 1) s
+    `
+	if _, err := tx.ExecContext(ctx, query, merchID, remaining, shards); err != nil {
+		return fmt.Errorf("failed to create drop shards: %w", err)
+	}
+	return nil
+}
+
+// Take списывает quantity из тиража. Сначала берется случайный свободный шард с достаточным остатком:
+// занятые другими покупателями шарды пропускаются, и на старте дропа покупки не ждут друг друга.
+// Если такого шарда нет, все шарды блокируются по порядку и остаток собирается из нескольких,
+// поэтому тираж не считается распроданным только из-за занятых шардов. У товара без тиража ничего не меняется.
+func (r *MerchDropRepository) Take(ctx context.Context, tx *sql.Tx, merchID int, quantity int) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE merch_drop_shards
+        SET remaining = remaining - $2
+        WHERE (merch_id, shard) = (
+            SELECT merch_id, shard
+            FROM merch_drop_shards
+            WHERE merch_id = $1 AND remaining >= $2
+            ORDER BY random()
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING shard
+    `
+	var shard int
+	err := tx.QueryRowContext(ctx, query, merchID, quantity).Scan(&shard)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("take drop quantity failed: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT shard, remaining FROM merch_drop_shards WHERE merch_id = $1 ORDER BY shard FOR UPDATE`, merchID)
+	if err != nil {
+		return fmt.Errorf("failed to lock drop shards: %w", err)
+	}
+	type dropShard struct{ shard, remaining int }
+	var shards []dropShard
+	total := 0
+	for rows.Next() {
+		var s dropShard
+		if err := rows.Scan(&s.shard, &s.remaining); err != nil {
+			rows.Close()
+			return err
+		}
+		shards = append(shards, s)
+		total += s.remaining
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(shards) == 0 {
+		return nil
+	}
+	if total < quantity {
+		return storage.ErrDropSoldOut
+	}
+
+	for _, s := range shards {
+		if quantity == 0 {
+			break
+		}
+		taken := min(s.remaining, quantity)
+		if taken == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE merch_drop_shards SET remaining = remaining - $3 WHERE merch_id = $1 AND shard = $2`,
+			merchID, s.shard, taken); err != nil {
+			return fmt.Errorf("take drop quantity failed: %w", err)
+		}
+		quantity -= taken
+	}
+	return nil
+}
+
+// Release возвращает quantity в тираж, например при отмене заказа. У товара без тиража ничего не меняется.
+func (r *MerchDropRepository) Release(ctx context.Context, tx *sql.Tx, merchID int, quantity int) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE merch_drop_shards
+        SET remaining = remaining + $2
+        WHERE (merch_id, shard) = (
+            SELECT merch_id, shard
+            FROM merch_drop_shards
+            WHERE merch_id = $1
+            ORDER BY random()
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+    `
+	res, err := tx.ExecContext(ctx, query, merchID, quantity)
+	if err != nil {
+		return fmt.Errorf("release drop quantity failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// все шарды заняты: ждем первый, как и медленный путь Take
+	query = `
+        UPDATE merch_drop_shards
+        SET remaining = remaining + $2
+        WHERE merch_id = $1 AND shard = (SELECT MIN(shard) FROM merch_drop_shards WHERE merch_id = $1)
+    `
+	if _, err := tx.ExecContext(ctx, query, merchID, quantity); err != nil {
+		return fmt.Errorf("release drop quantity failed: %w", err)
+	}
+	return nil
+}
+
+func sameCap(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const merchImageColumns = `id, merch_id, blob_key, content_type, size_bytes, position, created_at`
+
+type MerchImageRepository struct {
+	db *sql.DB
+}
+
+func NewMerchImageRepository(db *sql.DB) *MerchImageRepository {
+	return &MerchImageRepository{db: db}
+}
+
+func scanMerchImage(row rowScanner) (*domain.MerchImage, error) {
+	var img domain.MerchImage
+	if err := row.Scan(&img.ID, &img.MerchID, &img.Key, &img.ContentType, &img.Size, &img.Position, &img.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+// Create добавляет картинку в конец списка товара. Строка товара должна быть заблокирована вызывающим.
+func (r *MerchImageRepository) Create(ctx context.Context, tx storage.Tx, image *domain.MerchImage) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        INSERT INTO merch_images (merch_id, blob_key, content_type, size_bytes, position, created_at)
+        VALUES ($1, $2, $3, $4,
+                (SELECT COALESCE(MAX(position) + 1, 0) FROM merch_images WHERE merch_id = $1), $5)
+        RETURNING id, position
+    `
+	if image.CreatedAt.IsZero() {
+		image.CreatedAt = time.Now()
+	}
+	err := tx.QueryRowContext(ctx, query,
+		image.MerchID,
+		image.Key,
+		image.ContentType,
+		image.Size,
+		image.CreatedAt,
+	).Scan(&image.ID, &image.Position)
+	if err != nil {
+		return fmt.Errorf("create merch image failed: %w", err)
+	}
+	return nil
+}
+
+func (r *MerchImageRepository) GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchImage, error) {
+	query := `SELECT ` + merchImageColumns + ` FROM merch_images WHERE merch_id = $1 ORDER BY position, id`
+	rows, err := r.db.QueryContext(ctx, query, merchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []*domain.MerchImage
+	for rows.Next() {
+		img, err := scanMerchImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
+
+// Delete удаляет запись и возвращает ее, чтобы вызывающий удалил файл из хранилища.
+func (r *MerchImageRepository) Delete(ctx context.Context, tx storage.Tx, merchID int, imageID int64) (*domain.MerchImage, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `DELETE FROM merch_images WHERE id = $1 AND merch_id = $2 RETURNING ` + merchImageColumns
+	img, err := scanMerchImage(tx.QueryRowContext(ctx, query, imageID, merchID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrMerchImageNotFound
+		}
+		return nil, fmt.Errorf("delete merch image failed: %w", err)
+	}
+	return img, nil
+}
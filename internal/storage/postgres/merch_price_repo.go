@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type MerchPriceRepository struct {
+	db *sql.DB
+}
+
+func NewMerchPriceRepository(db *sql.DB) *MerchPriceRepository {
+	return &MerchPriceRepository{db: db}
+}
+
+func (r *MerchPriceRepository) Create(ctx context.Context, tx storage.Tx, price *domain.MerchPrice) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        INSERT INTO merch_prices (merch_id, price, effective_from, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id
+    `
+	if price.CreatedAt.IsZero() {
+		price.CreatedAt = time.Now()
+	}
+	if price.EffectiveFrom.IsZero() {
+		price.EffectiveFrom = price.CreatedAt
+	}
+	var createdBy sql.NullInt64
+	if price.CreatedBy != 0 {
+		createdBy = sql.NullInt64{Int64: price.CreatedBy, Valid: true}
+	}
+	err := tx.QueryRowContext(ctx, query,
+		price.MerchID,
+		price.Price,
+		price.EffectiveFrom,
+		createdBy,
+		price.CreatedAt,
+	).Scan(&price.ID)
+	if err != nil {
+		return fmt.Errorf("create merch price failed: %w", err)
+	}
+	return nil
+}
+
+func (r *MerchPriceRepository) GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchPrice, error) {
+	query := `
+        SELECT id, merch_id, price, effective_from, COALESCE(created_by, 0), created_at
+        FROM merch_prices
+        WHERE merch_id = $1
+        ORDER BY effective_from, id
+    `
+	rows, err := r.db.QueryContext(ctx, query, merchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []*domain.MerchPrice
+	for rows.Next() {
+		var p domain.MerchPrice
+		if err := rows.Scan(&p.ID, &p.MerchID, &p.Price, &p.EffectiveFrom, &p.CreatedBy, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, &p)
+	}
+	return prices, rows.Err()
+}
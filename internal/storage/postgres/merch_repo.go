@@ -3,10 +3,55 @@ package postgres
 import (
 	"avito-backend-intern-winter25/internal/models/domain"
 	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/lib/pq"
+	"strings"
+	"time"
+)
+
+const (
+	// действующая цена: последняя наступившая запись истории, merch.price - на случай отсутствия истории
+	effectivePriceColumn = `
+    COALESCE((SELECT mp.price FROM merch_prices mp
+              WHERE mp.merch_id = merch.id AND mp.effective_from <= now()
+              ORDER BY mp.effective_from DESC, mp.id DESC
+              LIMIT 1), merch.price)`
+
+	nextPriceColumn = `(SELECT MIN(mp.effective_from) FROM merch_prices mp
+              WHERE mp.merch_id = merch.id AND mp.effective_from > now())`
+
+	// dropLeftColumn - остаток тиража по всем шардам, NULL у товаров без тиража
+	dropLeftColumn = `(SELECT SUM(ds.remaining) FROM merch_drop_shards ds WHERE ds.merch_id = merch.id)`
+
+	reviewStatsColumns = `COALESCE((SELECT rs.review_count FROM merch_review_stats rs WHERE rs.merch_id = merch.id), 0),
+    COALESCE((SELECT rs.rating_sum FROM merch_review_stats rs WHERE rs.merch_id = merch.id), 0)`
+
+	// остаток набора - сколько наборов собирается из составляющих. Неактивная или не продающаяся составляющая
+	// дает 0, составляющая без учета остатков не ограничивает: MIN и LEAST пропускают NULL
+	merchStockColumn = `CASE WHEN merch.is_bundle THEN (
+        SELECT MIN(CASE WHEN c.active AND (c.available_from IS NULL OR c.available_from <= now())
+                             AND (c.available_until IS NULL OR c.available_until > now())
+                        THEN LEAST(c.stock, (SELECT SUM(ds.remaining) FROM merch_drop_shards ds WHERE ds.merch_id = c.id)) / bc.quantity
+                        ELSE 0 END)
+        FROM merch_bundle_components bc
+        JOIN merch c ON c.id = bc.component_id
+        WHERE bc.bundle_id = merch.id)
+    ELSE merch.stock END`
+
+	merchColumns = `merch.id, merch.name, ` + effectivePriceColumn + `, merch.currency, COALESCE(merch.category, ''), merch.description, merch.tags, merch.attributes,
+    ` + merchStockColumn + `, merch.is_bundle, merch.active,
+    merch.created_at, merch.updated_at, merch.retired_at,
+    merch.available_from, merch.available_until, merch.drop_cap, ` + dropLeftColumn + `, ` + nextPriceColumn + `, ` + reviewStatsColumns
+
+	merchOnSaleCondition = `(merch.available_from IS NULL OR merch.available_from <= now())
+        AND (merch.available_until IS NULL OR merch.available_until > now())`
+
+	uniqueViolationCode = "23505"
 )
 
 type MerchRepository struct {
@@ -19,18 +64,82 @@ func NewMerchRepository(db *sql.DB) *MerchRepository {
 	}
 }
 
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMerch читает merchColumns, extra - колонки, выбранные после них.
+func scanMerch(row rowScanner, extra ...interface{}) (*domain.Merch, error) {
+	var m domain.Merch
+	var stock, dropCap, dropLeft sql.NullInt64
+	var retiredAt, availableFrom, availableUntil, nextPriceAt sql.NullTime
+	var tags pq.StringArray
+	var attributes []byte
+	dest := []interface{}{&m.ID, &m.Name, &m.Price, &m.Currency, &m.Category, &m.Description, &tags, &attributes, &stock,
+		&m.Bundle, &m.Active, &m.CreatedAt, &m.UpdatedAt, &retiredAt, &availableFrom, &availableUntil, &dropCap, &dropLeft,
+		&nextPriceAt, &m.ReviewCount, &m.RatingSum}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	m.Tags = tags
+	if err := json.Unmarshal(attributes, &m.Attributes); err != nil {
+		return nil, fmt.Errorf("failed to decode merch attributes: %w", err)
+	}
+	if stock.Valid {
+		v := int(stock.Int64)
+		m.Stock = &v
+	}
+	if retiredAt.Valid {
+		m.RetiredAt = &retiredAt.Time
+	}
+	if availableFrom.Valid {
+		m.AvailableFrom = &availableFrom.Time
+	}
+	if availableUntil.Valid {
+		m.AvailableUntil = &availableUntil.Time
+	}
+	m.DropCap = nullIntPtr(dropCap)
+	m.DropLeft = nullIntPtr(dropLeft)
+	if nextPriceAt.Valid {
+		m.NextPriceAt = &nextPriceAt.Time
+	}
+	return &m, nil
+}
+
+// stringArray - пустой массив вместо NULL для колонок TEXT[] NOT NULL.
+func stringArray(values []string) pq.StringArray {
+	if values == nil {
+		return pq.StringArray{}
+	}
+	return values
+}
+
+// jsonObject кодирует map для колонок JSONB NOT NULL DEFAULT '{}'.
+func jsonObject(values map[string]string) ([]byte, error) {
+	if values == nil {
+		return []byte("{}"), nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode json object: %w", err)
+	}
+	return data, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode
+}
+
 func (r *MerchRepository) FindByName(ctx context.Context, name string) (*domain.Merch, error) {
 	if name == "" {
 		return &domain.Merch{}, storage.ErrMerchNameIsIncorrect
 	}
 	query := `
-	SELECT id, name, price FROM merch WHERE name = $1;
+	SELECT ` + merchColumns + ` FROM merch WHERE name = $1 AND active;
 	`
 
-	var result domain.Merch
-
-	row := r.db.QueryRowContext(ctx, query, name)
-	err := row.Scan(&result.ID, &result.Name, &result.Price)
+	result, err := scanMerch(r.db.QueryRowContext(ctx, query, name))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return &domain.Merch{}, storage.ErrMerchNotFound
@@ -38,15 +147,289 @@ func (r *MerchRepository) FindByName(ctx context.Context, name string) (*domain.
 		return &domain.Merch{}, fmt.Errorf("failed to find merch: %w", err)
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 func (r *MerchRepository) GetAllAvailableMerch(ctx context.Context) ([]*domain.Merch, error) {
 	query := `
-        SELECT name, price
+        SELECT ` + merchColumns + `
         FROM merch
+        WHERE active AND ` + merchOnSaleCondition + `
+        ORDER BY id
     `
-	rows, err := r.db.QueryContext(ctx, query)
+	return r.queryMerch(ctx, query)
+}
+
+func (r *MerchRepository) GetAll(ctx context.Context) ([]*domain.Merch, error) {
+	query := `
+        SELECT ` + merchColumns + `
+        FROM merch
+        ORDER BY id
+    `
+	return r.queryMerch(ctx, query)
+}
+
+// Search ищет активные товары по фильтру. Total - число подходящих товаров без учета пагинации.
+func (r *MerchRepository) Search(ctx context.Context, filter domain.MerchFilter) (*domain.MerchPage, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions = append(conditions, "merch.active", merchOnSaleCondition)
+	rank := "0"
+	if filter.Query != "" {
+		q := arg(filter.Query)
+		conditions = append(conditions, "merch.search_vector @@ websearch_to_tsquery('simple', "+q+")")
+		rank = "ts_rank(merch.search_vector, websearch_to_tsquery('simple', " + q + "))"
+	}
+	if filter.Category != "" {
+		conditions = append(conditions, "merch.category = "+arg(filter.Category))
+	}
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, "merch.tags @> "+arg(pq.StringArray(filter.Tags)))
+	}
+	if filter.Currency != "" {
+		conditions = append(conditions, "merch.currency = "+arg(filter.Currency))
+	}
+	if filter.MinPrice != nil {
+		conditions = append(conditions, effectivePriceColumn+" >= "+arg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		conditions = append(conditions, effectivePriceColumn+" <= "+arg(*filter.MaxPrice))
+	}
+
+	var orderBy string
+	switch filter.Sort {
+	case domain.MerchSortPriceAsc:
+		orderBy = effectivePriceColumn + " ASC, merch.id"
+	case domain.MerchSortPriceDesc:
+		orderBy = effectivePriceColumn + " DESC, merch.id"
+	case domain.MerchSortPopular:
+		orderBy = "COALESCE(sales.sold, 0) DESC, merch.id"
+	case domain.MerchSortNewest:
+		orderBy = "merch.created_at DESC, merch.id DESC"
+	default:
+		orderBy = rank + " DESC, merch.id"
+	}
+
+	// популярность - число проданных штук без отмененных заказов
+	query := `
+        SELECT ` + merchColumns + `, COUNT(*) OVER ()
+        FROM merch
+        LEFT JOIN (
+            SELECT p.merch_id, SUM(p.quantity) AS sold
+            FROM purchases p
+            LEFT JOIN orders o ON o.id = p.order_id
+            WHERE o.status IS DISTINCT FROM 'cancelled'
+            GROUP BY p.merch_id
+        ) sales ON sales.merch_id = merch.id
+        WHERE ` + strings.Join(conditions, " AND ") + `
+        ORDER BY ` + orderBy + `
+        LIMIT ` + arg(filter.Limit) + ` OFFSET ` + arg(filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search merch: %w", err)
+	}
+	defer rows.Close()
+
+	page := &domain.MerchPage{}
+	for rows.Next() {
+		m, err := scanMerch(rows, &page.Total)
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// за пределами последней страницы строк нет, поэтому и оконного COUNT тоже
+	if len(page.Items) == 0 && filter.Offset > 0 {
+		countQuery := `SELECT COUNT(*) FROM merch WHERE ` + strings.Join(conditions, " AND ")
+		if err := r.db.QueryRowContext(ctx, countQuery, args[:len(args)-2]...).Scan(&page.Total); err != nil {
+			return nil, fmt.Errorf("failed to count merch: %w", err)
+		}
+	}
+	return page, nil
+}
+
+func (r *MerchRepository) FindByID(ctx context.Context, id int) (*domain.Merch, error) {
+	query := `SELECT ` + merchColumns + ` FROM merch WHERE id = $1`
+
+	result, err := scanMerch(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrMerchNotFound
+		}
+		return nil, fmt.Errorf("failed to find merch: %w", err)
+	}
+	return result, nil
+}
+
+func (r *MerchRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int) (*domain.Merch, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `SELECT ` + merchColumns + ` FROM merch WHERE id = $1 FOR UPDATE`
+
+	result, err := scanMerch(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrMerchNotFound
+		}
+		return nil, fmt.Errorf("failed to find merch: %w", err)
+	}
+	return result, nil
+}
+
+func (r *MerchRepository) Create(ctx context.Context, tx storage.Tx, merch *domain.Merch) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO merch (name, price, currency, category, description, tags, attributes, stock, is_bundle, active,
+                           created_at, updated_at)
+        VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, TRUE, $10, $10) RETURNING id
+    `
+	now := time.Now()
+	if merch.Currency == "" {
+		merch.Currency = domain.PrimaryCurrency
+	}
+	attributes, err := jsonObject(merch.Attributes)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, query, merch.Name, merch.Price, merch.Currency, merch.Category, merch.Description,
+		stringArray(merch.Tags), attributes, merch.Stock, merch.Bundle, now).Scan(&merch.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrMerchNameTaken
+		}
+		return fmt.Errorf("create merch failed: %w", err)
+	}
+	merch.Active = true
+	merch.CreatedAt = now
+	merch.UpdatedAt = now
+	return nil
+}
+
+// Update сохраняет товар целиком. Остаток набора не хранится: его Stock расчетный и в таблицу не пишется.
+func (r *MerchRepository) Update(ctx context.Context, tx storage.Tx, merch *domain.Merch) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE merch
+        SET name = $1, price = $2, currency = $3, category = NULLIF($4, ''), description = $5, tags = $6,
+            attributes = $7, stock = CASE WHEN is_bundle THEN NULL ELSE $8 END, active = $9, retired_at = $10,
+            updated_at = $11
+        WHERE id = $12
+    `
+	attributes, err := jsonObject(merch.Attributes)
+	if err != nil {
+		return err
+	}
+	merch.UpdatedAt = time.Now()
+	res, err := tx.ExecContext(ctx, query,
+		merch.Name,
+		merch.Price,
+		merch.Currency,
+		merch.Category,
+		merch.Description,
+		stringArray(merch.Tags),
+		attributes,
+		merch.Stock,
+		merch.Active,
+		merch.RetiredAt,
+		merch.UpdatedAt,
+		merch.ID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrMerchNameTaken
+		}
+		return fmt.Errorf("update merch failed: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrMerchNotFound
+	}
+	return nil
+}
+
+// DecrementStock списывает остаток одним UPDATE, поэтому параллельные покупки не могут уйти в минус.
+// Для товаров без учета остатков (stock IS NULL) возвращает nil.
+func (r *MerchRepository) DecrementStock(ctx context.Context, tx storage.Tx, id int, quantity int) (*int, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE merch
+        SET stock = stock - $2
+        WHERE id = $1 AND (stock IS NULL OR stock >= $2)
+        RETURNING stock
+    `
+	var stock sql.NullInt64
+	if err := tx.QueryRowContext(ctx, query, id, quantity).Scan(&stock); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrMerchOutOfStock
+		}
+		return nil, fmt.Errorf("decrement stock failed: %w", err)
+	}
+	if !stock.Valid {
+		return nil, nil
+	}
+	remaining := int(stock.Int64)
+	return &remaining, nil
+}
+
+// IncrementStock возвращает товар на склад, товары без учета остатков не меняются.
+func (r *MerchRepository) IncrementStock(ctx context.Context, tx storage.Tx, id int, quantity int) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `UPDATE merch SET stock = stock + $2 WHERE id = $1 AND stock IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, query, id, quantity); err != nil {
+		return fmt.Errorf("increment stock failed: %w", err)
+	}
+	return nil
+}
+
+func (r *MerchRepository) GetStock(ctx context.Context, merchIDs []int) (map[int]*domain.MerchStock, error) {
+	ids := make(pq.Int64Array, len(merchIDs))
+	for i, id := range merchIDs {
+		ids[i] = int64(id)
+	}
+	query := `SELECT merch.id, ` + merchStockColumn + `, ` + dropLeftColumn + ` FROM merch WHERE merch.id = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := make(map[int]*domain.MerchStock)
+	for rows.Next() {
+		var id int
+		var stock, dropLeft sql.NullInt64
+		if err := rows.Scan(&id, &stock, &dropLeft); err != nil {
+			return nil, err
+		}
+		levels[id] = &domain.MerchStock{Stock: nullIntPtr(stock), DropLeft: nullIntPtr(dropLeft)}
+	}
+	return levels, rows.Err()
+}
+
+func (r *MerchRepository) queryMerch(ctx context.Context, query string, args ...interface{}) ([]*domain.Merch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -54,11 +437,11 @@ func (r *MerchRepository) GetAllAvailableMerch(ctx context.Context) ([]*domain.M
 
 	var merch []*domain.Merch
 	for rows.Next() {
-		var t domain.Merch
-		if err := rows.Scan(&t.Name, &t.Price); err != nil {
+		m, err := scanMerch(rows)
+		if err != nil {
 			return nil, err
 		}
-		merch = append(merch, &t)
+		merch = append(merch, m)
 	}
-	return merch, nil
+	return merch, rows.Err()
 }
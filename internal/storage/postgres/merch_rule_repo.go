@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"time"
+)
+
+const merchRuleColumns = `merch_id, COALESCE(max_quantity, 0), COALESCE(period_days, 0), COALESCE(min_account_age_days, 0),
+    allowed_roles, allowed_teams, COALESCE(updated_by, 0), updated_at`
+
+type MerchRuleRepository struct {
+	db *sql.DB
+}
+
+func NewMerchRuleRepository(db *sql.DB) *MerchRuleRepository {
+	return &MerchRuleRepository{db: db}
+}
+
+func (r *MerchRuleRepository) Get(ctx context.Context, merchID int) (*domain.MerchPurchaseRule, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+merchRuleColumns+` FROM merch_purchase_rules WHERE merch_id = $1`, merchID)
+	rule, err := scanMerchRule(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrMerchRuleNotFound
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetByMerchIDs возвращает правила по id товаров, товары без правил в результат не попадают.
+func (r *MerchRuleRepository) GetByMerchIDs(ctx context.Context, merchIDs []int) (map[int]*domain.MerchPurchaseRule, error) {
+	ids := make(pq.Int64Array, len(merchIDs))
+	for i, id := range merchIDs {
+		ids[i] = int64(id)
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+merchRuleColumns+` FROM merch_purchase_rules WHERE merch_id = ANY($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make(map[int]*domain.MerchPurchaseRule)
+	for rows.Next() {
+		rule, err := scanMerchRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules[rule.MerchID] = rule
+	}
+	return rules, rows.Err()
+}
+
+func (r *MerchRuleRepository) Upsert(ctx context.Context, tx storage.Tx, rule *domain.MerchPurchaseRule) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        INSERT INTO merch_purchase_rules (merch_id, max_quantity, period_days, min_account_age_days,
+                                          allowed_roles, allowed_teams, updated_by, updated_at)
+        VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, 0), $5, $6, $7, $8)
+        ON CONFLICT (merch_id) DO UPDATE SET
+            max_quantity = EXCLUDED.max_quantity,
+            period_days = EXCLUDED.period_days,
+            min_account_age_days = EXCLUDED.min_account_age_days,
+            allowed_roles = EXCLUDED.allowed_roles,
+            allowed_teams = EXCLUDED.allowed_teams,
+            updated_by = EXCLUDED.updated_by,
+            updated_at = EXCLUDED.updated_at
+    `
+	if rule.UpdatedAt.IsZero() {
+		rule.UpdatedAt = time.Now()
+	}
+	var updatedBy sql.NullInt64
+	if rule.UpdatedBy != 0 {
+		updatedBy = sql.NullInt64{Int64: rule.UpdatedBy, Valid: true}
+	}
+	_, err := tx.ExecContext(ctx, query,
+		rule.MerchID,
+		rule.MaxQuantity,
+		rule.PeriodDays,
+		rule.MinAccountAgeDays,
+		stringArray(rule.AllowedRoles),
+		stringArray(rule.AllowedTeams),
+		updatedBy,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert merch rule failed: %w", err)
+	}
+	return nil
+}
+
+func (r *MerchRuleRepository) Delete(ctx context.Context, tx storage.Tx, merchID int) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM merch_purchase_rules WHERE merch_id = $1`, merchID)
+	if err != nil {
+		return fmt.Errorf("delete merch rule failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrMerchRuleNotFound
+	}
+	return nil
+}
+
+func scanMerchRule(row rowScanner) (*domain.MerchPurchaseRule, error) {
+	var rule domain.MerchPurchaseRule
+	var roles, teams pq.StringArray
+	if err := row.Scan(&rule.MerchID, &rule.MaxQuantity, &rule.PeriodDays, &rule.MinAccountAgeDays,
+		&roles, &teams, &rule.UpdatedBy, &rule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	rule.AllowedRoles = roles
+	rule.AllowedTeams = teams
+	return &rule, nil
+}
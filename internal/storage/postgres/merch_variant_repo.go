@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const variantColumns = `v.id, v.merch_id, v.sku, v.size, v.color, v.price_delta, v.stock, v.active, v.created_at, v.updated_at`
+
+type MerchVariantRepository struct {
+	db *sql.DB
+}
+
+func NewMerchVariantRepository(db *sql.DB) *MerchVariantRepository {
+	return &MerchVariantRepository{db: db}
+}
+
+func scanVariant(row rowScanner) (*domain.MerchVariant, error) {
+	var v domain.MerchVariant
+	var stock sql.NullInt64
+	if err := row.Scan(&v.ID, &v.MerchID, &v.SKU, &v.Size, &v.Color, &v.PriceDelta, &stock, &v.Active,
+		&v.CreatedAt, &v.UpdatedAt); err != nil {
+		return nil, err
+	}
+	v.Stock = nullIntPtr(stock)
+	return &v, nil
+}
+
+func (r *MerchVariantRepository) Create(ctx context.Context, tx storage.Tx, variant *domain.MerchVariant) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO merch_variants (merch_id, sku, size, color, price_delta, stock, active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, TRUE, $7, $7) RETURNING id
+    `
+	now := time.Now()
+	err := tx.QueryRowContext(ctx, query,
+		variant.MerchID,
+		variant.SKU,
+		variant.Size,
+		variant.Color,
+		variant.PriceDelta,
+		variant.Stock,
+		now,
+	).Scan(&variant.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrVariantTaken
+		}
+		return fmt.Errorf("create merch variant failed: %w", err)
+	}
+	variant.Active = true
+	variant.CreatedAt = now
+	variant.UpdatedAt = now
+	return nil
+}
+
+func (r *MerchVariantRepository) Update(ctx context.Context, tx storage.Tx, variant *domain.MerchVariant) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE merch_variants
+        SET price_delta = $1, stock = $2, active = $3, updated_at = $4
+        WHERE id = $5
+    `
+	variant.UpdatedAt = time.Now()
+	res, err := tx.ExecContext(ctx, query, variant.PriceDelta, variant.Stock, variant.Active, variant.UpdatedAt, variant.ID)
+	if err != nil {
+		return fmt.Errorf("update merch variant failed: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrVariantNotFound
+	}
+	return nil
+}
+
+func (r *MerchVariantRepository) GetByMerch(ctx context.Context, merchID int) ([]*domain.MerchVariant, error) {
+	query := `SELECT ` + variantColumns + ` FROM merch_variants v WHERE v.merch_id = $1 ORDER BY v.id`
+	rows, err := r.db.QueryContext(ctx, query, merchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []*domain.MerchVariant
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+func (r *MerchVariantRepository) FindBySKU(ctx context.Context, sku string) (*domain.MerchVariant, error) {
+	query := `SELECT ` + variantColumns + ` FROM merch_variants v WHERE v.sku = $1`
+	v, err := scanVariant(r.db.QueryRowContext(ctx, query, sku))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrVariantNotFound
+		}
+		return nil, fmt.Errorf("failed to find merch variant: %w", err)
+	}
+	return v, nil
+}
+
+func (r *MerchVariantRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int) (*domain.MerchVariant, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `SELECT ` + variantColumns + ` FROM merch_variants v WHERE v.id = $1 FOR UPDATE`
+	v, err := scanVariant(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrVariantNotFound
+		}
+		return nil, fmt.Errorf("failed to find merch variant: %w", err)
+	}
+	return v, nil
+}
+
+// DecrementStock списывает остаток варианта так же, как MerchRepository.DecrementStock.
+func (r *MerchVariantRepository) DecrementStock(ctx context.Context, tx storage.Tx, id int, quantity int) (*int, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE merch_variants
+        SET stock = stock - $2
+        WHERE id = $1 AND (stock IS NULL OR stock >= $2)
+        RETURNING stock
+    `
+	var stock sql.NullInt64
+	if err := tx.QueryRowContext(ctx, query, id, quantity).Scan(&stock); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrVariantOutOfStock
+		}
+		return nil, fmt.Errorf("decrement variant stock failed: %w", err)
+	}
+	return nullIntPtr(stock), nil
+}
+
+func (r *MerchVariantRepository) IncrementStock(ctx context.Context, tx storage.Tx, id int, quantity int) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `UPDATE merch_variants SET stock = stock + $2 WHERE id = $1 AND stock IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, query, id, quantity); err != nil {
+		return fmt.Errorf("increment variant stock failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, tx storage.Tx, notification *domain.Notification) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        INSERT INTO notifications (user_id, kind, message, data, created_at)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id
+    `
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+	data, err := jsonObject(notification.Data)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, query,
+		notification.UserID,
+		notification.Kind,
+		notification.Message,
+		data,
+		notification.CreatedAt,
+	).Scan(&notification.ID)
+	if err != nil {
+		return fmt.Errorf("create notification failed: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) GetByUser(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]*domain.Notification, error) {
+	query := `
+        SELECT id, user_id, kind, message, data, read_at, created_at
+        FROM notifications
+        WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+        ORDER BY created_at DESC, id DESC
+        LIMIT $3
+    `
+	rows, err := r.db.QueryContext(ctx, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		var data []byte
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Message, &data, &readAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &n.Data); err != nil {
+			return nil, fmt.Errorf("invalid notification data: %w", err)
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, &n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE notifications SET read_at = COALESCE(read_at, now())
+        WHERE id = $1 AND user_id = $2
+    `, notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("mark notification read failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("mark notifications read failed: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"time"
+)
+
+const orderColumns = `id, user_id, status, created_at, updated_at`
+
+type OrderRepository struct {
+	db *sql.DB
+}
+
+func NewOrderRepository(db *sql.DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// Create сохраняет заказ в статусе placed вместе с первой записью истории.
+func (r *OrderRepository) Create(ctx context.Context, tx storage.Tx, order *domain.Order) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now()
+	}
+	order.UpdatedAt = order.CreatedAt
+	order.Status = domain.OrderStatusPlaced
+
+	query := `INSERT INTO orders (user_id, status, created_at, updated_at) VALUES ($1, $2, $3, $3) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, order.UserID, order.Status, order.CreatedAt).Scan(&order.ID); err != nil {
+		return fmt.Errorf("create order failed: %w", err)
+	}
+
+	change := &domain.OrderStatusChange{
+		OrderID:   order.ID,
+		ToStatus:  order.Status,
+		ChangedBy: order.UserID,
+		CreatedAt: order.CreatedAt,
+	}
+	if err := r.addStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+	order.History = []*domain.OrderStatusChange{change}
+	return nil
+}
+
+func (r *OrderRepository) FindByID(ctx context.Context, id int64) (*domain.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1`
+	order, err := scanOrder(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to find order: %w", err)
+	}
+	if err := r.loadDetails(ctx, []*domain.Order{order}); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// FindByIDForUpdate блокирует только строку заказа, покупки и история не подгружаются.
+func (r *OrderRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Order, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1 FOR UPDATE`
+	order, err := scanOrder(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to find order: %w", err)
+	}
+	return order, nil
+}
+
+func (r *OrderRepository) GetByUser(ctx context.Context, userID int64) ([]*domain.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+	return r.queryOrders(ctx, query, userID)
+}
+
+// GetByStatus возвращает заказы в статусе status, пустой статус - все заказы.
+func (r *OrderRepository) GetByStatus(ctx context.Context, status string) ([]*domain.Order, error) {
+	query := `
+        SELECT ` + orderColumns + `
+        FROM orders
+        WHERE $1 = '' OR status = $1
+        ORDER BY created_at, id
+    `
+	return r.queryOrders(ctx, query, status)
+}
+
+func (r *OrderRepository) UpdateStatus(ctx context.Context, tx storage.Tx, order *domain.Order, change *domain.OrderStatusChange) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now()
+	}
+
+	query := `UPDATE orders SET status = $1, updated_at = $2 WHERE id = $3`
+	res, err := tx.ExecContext(ctx, query, change.ToStatus, change.CreatedAt, order.ID)
+	if err != nil {
+		return fmt.Errorf("update order failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrOrderNotFound
+	}
+
+	change.OrderID = order.ID
+	if err := r.addStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+	order.Status = change.ToStatus
+	order.UpdatedAt = change.CreatedAt
+	order.History = append(order.History, change)
+	return nil
+}
+
+func (r *OrderRepository) addStatusChange(ctx context.Context, tx storage.Tx, change *domain.OrderStatusChange) error {
+	query := `
+        INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, created_at)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id
+    `
+	var changedBy sql.NullInt64
+	if change.ChangedBy != 0 {
+		changedBy = sql.NullInt64{Int64: change.ChangedBy, Valid: true}
+	}
+	err := tx.QueryRowContext(ctx, query,
+		change.OrderID,
+		change.FromStatus,
+		change.ToStatus,
+		changedBy,
+		change.CreatedAt,
+	).Scan(&change.ID)
+	if err != nil {
+		return fmt.Errorf("create order status change failed: %w", err)
+	}
+	return nil
+}
+
+func scanOrder(row rowScanner) (*domain.Order, error) {
+	var o domain.Order
+	if err := row.Scan(&o.ID, &o.UserID, &o.Status, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *OrderRepository) queryOrders(ctx context.Context, query string, args ...interface{}) ([]*domain.Order, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadDetails(ctx, orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// loadDetails подгружает покупки и историю статусов сразу для всех заказов двумя запросами.
+func (r *OrderRepository) loadDetails(ctx context.Context, orders []*domain.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*domain.Order, len(orders))
+	ids := make([]int64, len(orders))
+	for i, o := range orders {
+		byID[o.ID] = o
+		ids[i] = o.ID
+	}
+
+	purchaseRows, err := r.db.QueryContext(ctx, `
+        SELECT `+purchaseColumns+`
+        FROM purchases
+        WHERE order_id = ANY($1)
+        ORDER BY id
+    `, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to load order purchases: %w", err)
+	}
+	defer purchaseRows.Close()
+	for purchaseRows.Next() {
+		p, err := scanPurchase(purchaseRows)
+		if err != nil {
+			return err
+		}
+		byID[p.OrderID].Purchases = append(byID[p.OrderID].Purchases, p)
+	}
+	if err := purchaseRows.Err(); err != nil {
+		return err
+	}
+
+	historyRows, err := r.db.QueryContext(ctx, `
+        SELECT id, order_id, from_status, to_status, COALESCE(changed_by, 0), created_at
+        FROM order_status_history
+        WHERE order_id = ANY($1)
+        ORDER BY created_at, id
+    `, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to load order history: %w", err)
+	}
+	defer historyRows.Close()
+	for historyRows.Next() {
+		var c domain.OrderStatusChange
+		if err := historyRows.Scan(&c.ID, &c.OrderID, &c.FromStatus, &c.ToStatus, &c.ChangedBy, &c.CreatedAt); err != nil {
+			return err
+		}
+		byID[c.OrderID].History = append(byID[c.OrderID].History, &c)
+	}
+	return historyRows.Err()
+}
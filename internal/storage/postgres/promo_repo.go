@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"time"
+)
+
+const promoColumns = `id, code, kind, value, merch_ids, categories, currency, min_spend, valid_from, valid_until,
+    max_uses, max_uses_per_user, uses, active, COALESCE(created_by, 0), created_at`
+
+type PromoCodeRepository struct {
+	db *sql.DB
+}
+
+func NewPromoCodeRepository(db *sql.DB) *PromoCodeRepository {
+	return &PromoCodeRepository{db: db}
+}
+
+func scanPromo(row rowScanner) (*domain.PromoCode, error) {
+	var p domain.PromoCode
+	var merchIDs pq.Int64Array
+	var categories pq.StringArray
+	var validFrom, validUntil sql.NullTime
+	var maxUses, maxUsesPerUser sql.NullInt64
+	if err := row.Scan(&p.ID, &p.Code, &p.Kind, &p.Value, &merchIDs, &categories, &p.Currency, &p.MinSpend,
+		&validFrom, &validUntil, &maxUses, &maxUsesPerUser, &p.Uses, &p.Active, &p.CreatedBy, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, id := range merchIDs {
+		p.MerchIDs = append(p.MerchIDs, int(id))
+	}
+	p.Categories = categories
+	if validFrom.Valid {
+		p.ValidFrom = &validFrom.Time
+	}
+	if validUntil.Valid {
+		p.ValidUntil = &validUntil.Time
+	}
+	p.MaxUses = nullIntPtr(maxUses)
+	p.MaxUsesPerUser = nullIntPtr(maxUsesPerUser)
+	return &p, nil
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
+
+func (r *PromoCodeRepository) Create(ctx context.Context, tx storage.Tx, promo *domain.PromoCode) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        INSERT INTO promo_codes (code, kind, value, merch_ids, categories, currency, min_spend, valid_from, valid_until,
+                                 max_uses, max_uses_per_user, active, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, TRUE, $12, $13) RETURNING id
+    `
+	if promo.CreatedAt.IsZero() {
+		promo.CreatedAt = time.Now()
+	}
+	if promo.Currency == "" {
+		promo.Currency = domain.PrimaryCurrency
+	}
+	merchIDs := make(pq.Int64Array, 0, len(promo.MerchIDs))
+	for _, id := range promo.MerchIDs {
+		merchIDs = append(merchIDs, int64(id))
+	}
+	categories := pq.StringArray(promo.Categories)
+	if categories == nil {
+		categories = pq.StringArray{}
+	}
+	var createdBy sql.NullInt64
+	if promo.CreatedBy != 0 {
+		createdBy = sql.NullInt64{Int64: promo.CreatedBy, Valid: true}
+	}
+	err := tx.QueryRowContext(ctx, query,
+		promo.Code,
+		promo.Kind,
+		promo.Value,
+		merchIDs,
+		categories,
+		promo.Currency,
+		promo.MinSpend,
+		promo.ValidFrom,
+		promo.ValidUntil,
+		promo.MaxUses,
+		promo.MaxUsesPerUser,
+		createdBy,
+		promo.CreatedAt,
+	).Scan(&promo.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrPromoCodeTaken
+		}
+		return fmt.Errorf("create promo code failed: %w", err)
+	}
+	promo.Active = true
+	return nil
+}
+
+func (r *PromoCodeRepository) GetAll(ctx context.Context) ([]*domain.PromoCode, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+promoColumns+` FROM promo_codes ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promos []*domain.PromoCode
+	for rows.Next() {
+		p, err := scanPromo(rows)
+		if err != nil {
+			return nil, err
+		}
+		promos = append(promos, p)
+	}
+	return promos, rows.Err()
+}
+
+// FindByCodeForUpdate блокирует строку кода: проверки лимитов и учет использования идут под этой блокировкой.
+func (r *PromoCodeRepository) FindByCodeForUpdate(ctx context.Context, tx storage.Tx, code string) (*domain.PromoCode, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `SELECT ` + promoColumns + ` FROM promo_codes WHERE code = $1 FOR UPDATE`
+	promo, err := scanPromo(tx.QueryRowContext(ctx, query, code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrPromoCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to find promo code: %w", err)
+	}
+	return promo, nil
+}
+
+func (r *PromoCodeRepository) SetActive(ctx context.Context, id int64, active bool) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE promo_codes SET active = $2 WHERE id = $1`, id, active)
+	if err != nil {
+		return fmt.Errorf("update promo code failed: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrPromoCodeNotFound
+	}
+	return nil
+}
+
+// IncrementUses учитывает использование, не давая превысить max_uses даже без предварительной проверки.
+func (r *PromoCodeRepository) IncrementUses(ctx context.Context, tx storage.Tx, id int64) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE promo_codes SET uses = uses + 1
+        WHERE id = $1 AND (max_uses IS NULL OR uses < max_uses)
+    `
+	res, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("increment promo code uses failed: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrPromoCodeExhausted
+	}
+	return nil
+}
+
+func (r *PromoCodeRepository) CountUserRedemptions(ctx context.Context, tx storage.Tx, promoID, userID int64) (int, error) {
+	if tx == nil {
+		return 0, errs.ErrTransactionNotFound
+	}
+	query := `SELECT COUNT(*) FROM promo_code_redemptions WHERE promo_code_id = $1 AND user_id = $2`
+	var count int
+	if err := tx.QueryRowContext(ctx, query, promoID, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count promo code redemptions failed: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PromoCodeRepository) CreateRedemption(ctx context.Context, tx storage.Tx, redemption *domain.PromoRedemption) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        INSERT INTO promo_code_redemptions (promo_code_id, user_id, order_id, discount, created_at)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id
+    `
+	if redemption.CreatedAt.IsZero() {
+		redemption.CreatedAt = time.Now()
+	}
+	return tx.QueryRowContext(ctx, query,
+		redemption.PromoCodeID,
+		redemption.UserID,
+		redemption.OrderID,
+		redemption.Discount,
+		redemption.CreatedAt,
+	).Scan(&redemption.ID)
+}
+
+// ReleaseRedemption удаляет использование промокода заказом и возвращает его в лимит max_uses.
+func (r *PromoCodeRepository) ReleaseRedemption(ctx context.Context, tx storage.Tx, orderID int64) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        WITH released AS (
+            DELETE FROM promo_code_redemptions WHERE order_id = $1 RETURNING promo_code_id
+        )
+        UPDATE promo_codes pc
+        SET uses = pc.uses - (SELECT COUNT(*) FROM released r WHERE r.promo_code_id = pc.id)
+        WHERE pc.id IN (SELECT promo_code_id FROM released)
+    `
+	if _, err := tx.ExecContext(ctx, query, orderID); err != nil {
+		return fmt.Errorf("release promo code redemption failed: %w", err)
+	}
+	return nil
+}
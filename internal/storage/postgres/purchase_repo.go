@@ -5,9 +5,19 @@ import (
 	"avito-backend-intern-winter25/pkg/errs"
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 )
 
+// для подарков подставляются имена покупателя и получателя
+const purchaseColumns = `id, user_id, COALESCE(order_id, 0), COALESCE(merch_id, 0), COALESCE(variant_id, 0), sku, item,
+    quantity, unit_price, price, discount, COALESCE(promo_code_id, 0), currency, purchase_date,
+    COALESCE(gifted_by, 0), gift_message,
+    COALESCE((SELECT username FROM users WHERE id = purchases.gifted_by), ''),
+    CASE WHEN gifted_by IS NULL THEN '' ELSE (SELECT username FROM users WHERE id = purchases.user_id) END,
+    COALESCE(hold_id, 0), COALESCE(group_buy_id, 0), COALESCE(bundle_purchase_id, 0),
+    COALESCE(raffle_id, 0)`
+
 type PurchaseRepository struct {
 	db *sql.DB
 }
@@ -22,13 +32,80 @@ func (r *PurchaseRepository) Create(ctx context.Context, tx *sql.Tx, purchase *d
 	}
 
 	query := `
-        INSERT INTO purchases (user_id, item, price, purchase_date)
-        VALUES ($1, $2, $3, $4) RETURNING id
+        INSERT INTO purchases (user_id, order_id, merch_id, variant_id, sku, item, quantity, unit_price, price, discount,
+                               promo_code_id, currency, purchase_date, gifted_by, gift_message, hold_id,
+                               group_buy_id, bundle_purchase_id, raffle_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING id
     `
 	if purchase.PurchaseDate.IsZero() {
 		purchase.PurchaseDate = time.Now()
 	}
-	return tx.QueryRowContext(ctx, query, purchase.UserID, purchase.Item, purchase.Price, purchase.PurchaseDate).Scan(&purchase.ID)
+	if purchase.Currency == "" {
+		purchase.Currency = domain.PrimaryCurrency
+	}
+	if purchase.Quantity == 0 {
+		purchase.Quantity = 1
+	}
+	if purchase.UnitPrice == 0 {
+		purchase.UnitPrice = purchase.Price / purchase.Quantity
+	}
+	var orderID sql.NullInt64
+	if purchase.OrderID != 0 {
+		orderID = sql.NullInt64{Int64: purchase.OrderID, Valid: true}
+	}
+	var merchID sql.NullInt64
+	if purchase.MerchID != 0 {
+		merchID = sql.NullInt64{Int64: int64(purchase.MerchID), Valid: true}
+	}
+	var variantID sql.NullInt64
+	if purchase.VariantID != 0 {
+		variantID = sql.NullInt64{Int64: int64(purchase.VariantID), Valid: true}
+	}
+	var promoCodeID sql.NullInt64
+	if purchase.PromoCodeID != 0 {
+		promoCodeID = sql.NullInt64{Int64: purchase.PromoCodeID, Valid: true}
+	}
+	var giftedBy sql.NullInt64
+	if purchase.GiftedBy != 0 {
+		giftedBy = sql.NullInt64{Int64: purchase.GiftedBy, Valid: true}
+	}
+	var holdID sql.NullInt64
+	if purchase.HoldID != 0 {
+		holdID = sql.NullInt64{Int64: purchase.HoldID, Valid: true}
+	}
+	var groupBuyID sql.NullInt64
+	if purchase.GroupBuyID != 0 {
+		groupBuyID = sql.NullInt64{Int64: purchase.GroupBuyID, Valid: true}
+	}
+	var bundlePurchaseID sql.NullInt64
+	if purchase.BundlePurchaseID != 0 {
+		bundlePurchaseID = sql.NullInt64{Int64: purchase.BundlePurchaseID, Valid: true}
+	}
+	var raffleID sql.NullInt64
+	if purchase.RaffleID != 0 {
+		raffleID = sql.NullInt64{Int64: purchase.RaffleID, Valid: true}
+	}
+	return tx.QueryRowContext(ctx, query,
+		purchase.UserID,
+		orderID,
+		merchID,
+		variantID,
+		purchase.SKU,
+		purchase.Item,
+		purchase.Quantity,
+		purchase.UnitPrice,
+		purchase.Price,
+		purchase.Discount,
+		promoCodeID,
+		purchase.Currency,
+		purchase.PurchaseDate,
+		giftedBy,
+		purchase.GiftMessage,
+		holdID,
+		groupBuyID,
+		bundlePurchaseID,
+		raffleID,
+	).Scan(&purchase.ID)
 }
 
 func (r *PurchaseRepository) GetByUser(ctx context.Context, tx *sql.Tx, userID int64) ([]*domain.Purchase, error) {
@@ -36,12 +113,71 @@ func (r *PurchaseRepository) GetByUser(ctx context.Context, tx *sql.Tx, userID i
 		return nil, errs.ErrTransactionNotFound
 	}
 	query := `
-        SELECT id, user_id, item, price, purchase_date
+        SELECT ` + purchaseColumns + `
         FROM purchases
         WHERE user_id = $1
         ORDER BY purchase_date DESC
     `
-	rows, err := tx.QueryContext(ctx, query, userID)
+	return queryPurchases(ctx, tx, query, userID)
+}
+
+func (r *PurchaseRepository) GetByOrder(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.Purchase, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT ` + purchaseColumns + `
+        FROM purchases
+        WHERE order_id = $1
+        ORDER BY id
+    `
+	return queryPurchases(ctx, tx, query, orderID)
+}
+
+// CountUserItem считает купленные пользователем штуки товара с момента since, отмененные заказы не учитываются.
+// До конца транзакции держится advisory-блокировка пары пользователь-товар: строку товара покупки дропа не
+// блокируют, и без нее параллельные покупки одного пользователя могли бы обойти лимит.
+func (r *PurchaseRepository) CountUserItem(ctx context.Context, tx *sql.Tx, userID int64, merchID int, since time.Time) (int, error) {
+	if tx == nil {
+		return 0, errs.ErrTransactionNotFound
+	}
+	lockKey := int64(merchID)<<32 | userID&0xffffffff
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		return 0, fmt.Errorf("failed to lock user purchases: %w", err)
+	}
+
+	query := `
+        SELECT COALESCE(SUM(p.quantity), 0)
+        FROM purchases p
+        LEFT JOIN orders o ON o.id = p.order_id
+        WHERE p.user_id = $1 AND p.merch_id = $2 AND p.purchase_date >= $3
+          AND o.status IS DISTINCT FROM 'cancelled'
+    `
+	var count int
+	if err := tx.QueryRowContext(ctx, query, userID, merchID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count user purchases failed: %w", err)
+	}
+	return count, nil
+}
+
+func (r *PurchaseRepository) HasPurchased(ctx context.Context, userID int64, merchID int) (bool, error) {
+	query := `
+        SELECT EXISTS (
+            SELECT 1
+            FROM purchases p
+            LEFT JOIN orders o ON o.id = p.order_id
+            WHERE p.user_id = $1 AND p.merch_id = $2 AND o.status IS DISTINCT FROM 'cancelled'
+        )
+    `
+	var purchased bool
+	if err := r.db.QueryRowContext(ctx, query, userID, merchID).Scan(&purchased); err != nil {
+		return false, fmt.Errorf("check user purchase failed: %w", err)
+	}
+	return purchased, nil
+}
+
+func queryPurchases(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]*domain.Purchase, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -49,11 +185,11 @@ func (r *PurchaseRepository) GetByUser(ctx context.Context, tx *sql.Tx, userID i
 
 	var purchases []*domain.Purchase
 	for rows.Next() {
-		var p domain.Purchase
-		if err := rows.Scan(&p.ID, &p.UserID, &p.Item, &p.Price, &p.PurchaseDate); err != nil {
+		p, err := scanPurchase(rows)
+		if err != nil {
 			return nil, err
 		}
-		purchases = append(purchases, &p)
+		purchases = append(purchases, p)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -61,3 +197,14 @@ func (r *PurchaseRepository) GetByUser(ctx context.Context, tx *sql.Tx, userID i
 
 	return purchases, nil
 }
+
+func scanPurchase(row rowScanner) (*domain.Purchase, error) {
+	var p domain.Purchase
+	if err := row.Scan(&p.ID, &p.UserID, &p.OrderID, &p.MerchID, &p.VariantID, &p.SKU, &p.Item, &p.Quantity,
+		&p.UnitPrice, &p.Price, &p.Discount, &p.PromoCodeID, &p.Currency, &p.PurchaseDate,
+		&p.GiftedBy, &p.GiftMessage, &p.GiftFrom, &p.GiftTo, &p.HoldID, &p.GroupBuyID, &p.BundlePurchaseID,
+		&p.RaffleID); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const raffleColumns = `r.id, r.merch_id, m.name, r.ticket_price, r.max_tickets_per_user, r.prizes, r.draw_at,
+    r.seed, r.seed_hash, r.status, r.tickets_sold, r.created_by, r.created_at, r.drawn_at
+    FROM raffles r
+    JOIN merch m ON m.id = r.merch_id`
+
+type RaffleRepository struct {
+	db *sql.DB
+}
+
+func NewRaffleRepository(db *sql.DB) *RaffleRepository {
+	return &RaffleRepository{db: db}
+}
+
+func scanRaffle(row rowScanner) (*domain.Raffle, error) {
+	var r domain.Raffle
+	var drawnAt sql.NullTime
+	err := row.Scan(&r.ID, &r.MerchID, &r.Item, &r.TicketPrice, &r.MaxTicketsPerUser, &r.Prizes, &r.DrawAt,
+		&r.Seed, &r.SeedHash, &r.Status, &r.TicketsSold, &r.CreatedBy, &r.CreatedAt, &drawnAt)
+	if err != nil {
+		return nil, err
+	}
+	if drawnAt.Valid {
+		r.DrawnAt = &drawnAt.Time
+	}
+	return &r, nil
+}
+
+func (r *RaffleRepository) Create(ctx context.Context, tx storage.Tx, raffle *domain.Raffle) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO raffles (merch_id, ticket_price, max_tickets_per_user, prizes, draw_at, seed, seed_hash,
+                             status, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id
+    `
+	if raffle.CreatedAt.IsZero() {
+		raffle.CreatedAt = time.Now()
+	}
+	raffle.Status = domain.RaffleStatusOpen
+	err := tx.QueryRowContext(ctx, query,
+		raffle.MerchID,
+		raffle.TicketPrice,
+		raffle.MaxTicketsPerUser,
+		raffle.Prizes,
+		raffle.DrawAt,
+		raffle.Seed,
+		raffle.SeedHash,
+		raffle.Status,
+		raffle.CreatedBy,
+		raffle.CreatedAt,
+	).Scan(&raffle.ID)
+	if err != nil {
+		return fmt.Errorf("create raffle failed: %w", err)
+	}
+	return nil
+}
+
+func (r *RaffleRepository) FindByID(ctx context.Context, id int64) (*domain.Raffle, error) {
+	raffle, err := scanRaffle(r.db.QueryRowContext(ctx, `SELECT `+raffleColumns+` WHERE r.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrRaffleNotFound
+		}
+		return nil, fmt.Errorf("failed to find raffle: %w", err)
+	}
+	return raffle, nil
+}
+
+func (r *RaffleRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Raffle, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	raffle, err := scanRaffle(tx.QueryRowContext(ctx, `SELECT `+raffleColumns+` WHERE r.id = $1 FOR UPDATE OF r`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrRaffleNotFound
+		}
+		return nil, fmt.Errorf("failed to find raffle: %w", err)
+	}
+	return raffle, nil
+}
+
+func (r *RaffleRepository) ClaimDue(ctx context.Context, tx storage.Tx, now time.Time) (*domain.Raffle, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT ` + raffleColumns + `
+        WHERE r.status = 'open' AND r.draw_at <= $1
+        ORDER BY r.draw_at, r.id
+        LIMIT 1
+        FOR UPDATE OF r SKIP LOCKED
+    `
+	raffle, err := scanRaffle(tx.QueryRowContext(ctx, query, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrRaffleNotFound
+		}
+		return nil, fmt.Errorf("failed to claim raffle: %w", err)
+	}
+	return raffle, nil
+}
+
+// GetActive возвращает открытые розыгрыши, ближайшие первыми.
+func (r *RaffleRepository) GetActive(ctx context.Context) ([]*domain.Raffle, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+raffleColumns+` WHERE r.status = 'open' ORDER BY r.draw_at, r.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var raffles []*domain.Raffle
+	for rows.Next() {
+		raffle, err := scanRaffle(rows)
+		if err != nil {
+			return nil, err
+		}
+		raffles = append(raffles, raffle)
+	}
+	return raffles, rows.Err()
+}
+
+func (r *RaffleRepository) Update(ctx context.Context, tx storage.Tx, raffle *domain.Raffle) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `UPDATE raffles SET status = $2, tickets_sold = $3, drawn_at = $4 WHERE id = $1`
+	res, err := tx.ExecContext(ctx, query, raffle.ID, raffle.Status, raffle.TicketsSold, raffle.DrawnAt)
+	if err != nil {
+		return fmt.Errorf("update raffle failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrRaffleNotFound
+	}
+	return nil
+}
+
+func (r *RaffleRepository) CreateEntry(ctx context.Context, tx storage.Tx, entry *domain.RaffleEntry) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO raffle_entries (raffle_id, user_id, first_ticket, quantity, amount, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+    `
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	err := tx.QueryRowContext(ctx, query,
+		entry.RaffleID, entry.UserID, entry.FirstTicket, entry.Quantity, entry.Amount, entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("create raffle entry failed: %w", err)
+	}
+	return nil
+}
+
+func (r *RaffleRepository) CountUserTickets(ctx context.Context, tx storage.Tx, raffleID, userID int64) (int, error) {
+	if tx == nil {
+		return 0, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM raffle_entries
+        WHERE raffle_id = $1 AND user_id = $2 AND refunded_at IS NULL
+    `
+	var count int
+	if err := tx.QueryRowContext(ctx, query, raffleID, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *RaffleRepository) GetEntries(ctx context.Context, tx *sql.Tx, raffleID int64) ([]*domain.RaffleEntry, error) {
+	query := `
+        SELECT e.id, e.raffle_id, e.user_id, u.username, e.first_ticket, e.quantity, e.amount, e.created_at, e.refunded_at
+        FROM raffle_entries e
+        JOIN users u ON u.id = e.user_id
+        WHERE e.raffle_id = $1
+        ORDER BY e.first_ticket
+    `
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, raffleID)
+	} else {
+		rows, err = r.db.QueryContext(ctx, query, raffleID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.RaffleEntry
+	for rows.Next() {
+		var e domain.RaffleEntry
+		var refundedAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.RaffleID, &e.UserID, &e.Username, &e.FirstTicket, &e.Quantity, &e.Amount,
+			&e.CreatedAt, &refundedAt); err != nil {
+			return nil, err
+		}
+		if refundedAt.Valid {
+			e.RefundedAt = &refundedAt.Time
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *RaffleRepository) MarkEntriesRefunded(ctx context.Context, tx storage.Tx, raffleID int64, at time.Time) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `UPDATE raffle_entries SET refunded_at = $2 WHERE raffle_id = $1 AND refunded_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, raffleID, at); err != nil {
+		return fmt.Errorf("refund raffle entries failed: %w", err)
+	}
+	return nil
+}
+
+func (r *RaffleRepository) CreateWinner(ctx context.Context, tx storage.Tx, winner *domain.RaffleWinner) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO raffle_winners (raffle_id, place, ticket, user_id, order_id)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	if _, err := tx.ExecContext(ctx, query,
+		winner.RaffleID, winner.Place, winner.Ticket, winner.UserID, winner.OrderID); err != nil {
+		return fmt.Errorf("create raffle winner failed: %w", err)
+	}
+	return nil
+}
+
+func (r *RaffleRepository) GetWinners(ctx context.Context, raffleID int64) ([]*domain.RaffleWinner, error) {
+	query := `
+        SELECT w.raffle_id, w.place, w.ticket, w.user_id, u.username, w.order_id
+        FROM raffle_winners w
+        JOIN users u ON u.id = w.user_id
+        WHERE w.raffle_id = $1
+        ORDER BY w.place
+    `
+	rows, err := r.db.QueryContext(ctx, query, raffleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var winners []*domain.RaffleWinner
+	for rows.Next() {
+		var w domain.RaffleWinner
+		if err := rows.Scan(&w.RaffleID, &w.Place, &w.Ticket, &w.UserID, &w.Username, &w.OrderID); err != nil {
+			return nil, err
+		}
+		winners = append(winners, &w)
+	}
+	return winners, rows.Err()
+}
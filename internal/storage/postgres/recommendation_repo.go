@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	// ownedMerchQuery - пары пользователь-товар по неотмененным покупкам
+	ownedMerchQuery = `
+        SELECT DISTINCT p.user_id, p.merch_id
+        FROM purchases p
+        LEFT JOIN orders o ON o.id = p.order_id
+        WHERE p.merch_id IS NOT NULL AND o.status IS DISTINCT FROM 'cancelled'`
+
+	// recommendableCondition - товар можно купить, и пользователь $1 его еще не покупал
+	recommendableCondition = `merch.active AND ` + merchInStockColumn + `
+        AND NOT EXISTS (
+            SELECT 1
+            FROM purchases p
+            LEFT JOIN orders o ON o.id = p.order_id
+            WHERE p.user_id = $1 AND p.merch_id = merch.id AND o.status IS DISTINCT FROM 'cancelled'
+        )`
+)
+
+type RecommendationRepository struct {
+	db *sql.DB
+}
+
+func NewRecommendationRepository(db *sql.DB) *RecommendationRepository {
+	return &RecommendationRepository{db: db}
+}
+
+func (r *RecommendationRepository) Refresh(ctx context.Context, tx *sql.Tx, now time.Time, perItem int) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM merch_also_bought`); err != nil {
+		return fmt.Errorf("clear also bought failed: %w", err)
+	}
+	alsoBought := `
+        WITH owned AS (` + ownedMerchQuery + `),
+        pairs AS (
+            SELECT a.merch_id, b.merch_id AS related_merch_id, COUNT(*) AS buyers
+            FROM owned a
+            JOIN owned b ON b.user_id = a.user_id AND b.merch_id <> a.merch_id
+            GROUP BY a.merch_id, b.merch_id
+        )
+        INSERT INTO merch_also_bought (merch_id, related_merch_id, buyers, computed_at)
+        SELECT merch_id, related_merch_id, buyers, $1
+        FROM (
+            SELECT pairs.*, ROW_NUMBER() OVER (PARTITION BY merch_id ORDER BY buyers DESC, related_merch_id) AS rank
+            FROM pairs
+        ) ranked
+        WHERE rank <= $2
+    `
+	if _, err := tx.ExecContext(ctx, alsoBought, now, perItem); err != nil {
+		return fmt.Errorf("refresh also bought failed: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM team_popular_merch`); err != nil {
+		return fmt.Errorf("clear team popular failed: %w", err)
+	}
+	teamPopular := `
+        WITH owned AS (` + ownedMerchQuery + `),
+        counts AS (
+            SELECT u.team, owned.merch_id, COUNT(*) AS buyers
+            FROM owned
+            JOIN users u ON u.id = owned.user_id
+            WHERE u.team IS NOT NULL AND u.team <> ''
+            GROUP BY u.team, owned.merch_id
+        )
+        INSERT INTO team_popular_merch (team, merch_id, buyers, computed_at)
+        SELECT team, merch_id, buyers, $1
+        FROM (
+            SELECT counts.*, ROW_NUMBER() OVER (PARTITION BY team ORDER BY buyers DESC, merch_id) AS rank
+            FROM counts
+        ) ranked
+        WHERE rank <= $2
+    `
+	if _, err := tx.ExecContext(ctx, teamPopular, now, perItem); err != nil {
+		return fmt.Errorf("refresh team popular failed: %w", err)
+	}
+	return nil
+}
+
+func (r *RecommendationRepository) AlsoBoughtWith(ctx context.Context, userID int64, merchID int, limit int) ([]*domain.Recommendation, error) {
+	query := `
+        SELECT ` + merchColumns + `, ab.buyers
+        FROM merch_also_bought ab
+        JOIN merch ON merch.id = ab.related_merch_id
+        WHERE ab.merch_id = $2 AND ` + recommendableCondition + `
+        ORDER BY ab.buyers DESC, merch.id
+        LIMIT $3
+    `
+	return r.queryRecommendations(ctx, query, userID, merchID, limit)
+}
+
+func (r *RecommendationRepository) AlsoBoughtForUser(ctx context.Context, userID int64, limit int) ([]*domain.Recommendation, error) {
+	query := `
+        SELECT ` + merchColumns + `, s.buyers
+        FROM (
+            SELECT ab.related_merch_id AS merch_id, SUM(ab.buyers) AS buyers
+            FROM merch_also_bought ab
+            WHERE ab.merch_id IN (
+                SELECT p.merch_id
+                FROM purchases p
+                LEFT JOIN orders o ON o.id = p.order_id
+                WHERE p.user_id = $1 AND o.status IS DISTINCT FROM 'cancelled'
+            )
+            GROUP BY ab.related_merch_id
+        ) s
+        JOIN merch ON merch.id = s.merch_id
+        WHERE ` + recommendableCondition + `
+        ORDER BY s.buyers DESC, merch.id
+        LIMIT $2
+    `
+	return r.queryRecommendations(ctx, query, userID, limit)
+}
+
+func (r *RecommendationRepository) PopularInTeam(ctx context.Context, userID int64, team string, limit int) ([]*domain.Recommendation, error) {
+	query := `
+        SELECT ` + merchColumns + `, tp.buyers
+        FROM team_popular_merch tp
+        JOIN merch ON merch.id = tp.merch_id
+        WHERE tp.team = $2 AND ` + recommendableCondition + `
+        ORDER BY tp.buyers DESC, merch.id
+        LIMIT $3
+    `
+	return r.queryRecommendations(ctx, query, userID, team, limit)
+}
+
+func (r *RecommendationRepository) queryRecommendations(ctx context.Context, query string, args ...interface{}) ([]*domain.Recommendation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recommendations []*domain.Recommendation
+	for rows.Next() {
+		var rec domain.Recommendation
+		rec.Merch, err = scanMerch(rows, &rec.Buyers)
+		if err != nil {
+			return nil, err
+		}
+		recommendations = append(recommendations, &rec)
+	}
+	return recommendations, rows.Err()
+}
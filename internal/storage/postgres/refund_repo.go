@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type RefundRepository struct {
+	db *sql.DB
+}
+
+func NewRefundRepository(db *sql.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+func (r *RefundRepository) Create(ctx context.Context, tx storage.Tx, refund *domain.Refund) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        INSERT INTO refunds (order_id, purchase_id, user_id, amount, currency, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+    `
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = time.Now()
+	}
+	return tx.QueryRowContext(ctx, query,
+		refund.OrderID,
+		refund.PurchaseID,
+		refund.UserID,
+		refund.Amount,
+		refund.Currency,
+		refund.CreatedAt,
+	).Scan(&refund.ID)
+}
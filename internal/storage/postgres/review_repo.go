@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const reviewColumns = `r.id, r.merch_id, m.name, r.user_id, u.username, r.rating, r.body, r.status,
+    r.created_at, r.updated_at, COALESCE(r.moderated_by, 0), r.moderated_at
+    FROM merch_reviews r
+    JOIN merch m ON m.id = r.merch_id
+    JOIN users u ON u.id = r.user_id`
+
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+func scanReview(row rowScanner) (*domain.Review, error) {
+	var r domain.Review
+	var moderatedAt sql.NullTime
+	err := row.Scan(&r.ID, &r.MerchID, &r.Item, &r.UserID, &r.Username, &r.Rating, &r.Body, &r.Status,
+		&r.CreatedAt, &r.UpdatedAt, &r.ModeratedBy, &moderatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if moderatedAt.Valid {
+		r.ModeratedAt = &moderatedAt.Time
+	}
+	return &r, nil
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, tx storage.Tx, review *domain.Review) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO merch_reviews (merch_id, user_id, rating, body, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $6) RETURNING id
+    `
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = time.Now()
+	}
+	review.UpdatedAt = review.CreatedAt
+	if review.Status == "" {
+		review.Status = domain.ReviewStatusPublished
+	}
+	err := tx.QueryRowContext(ctx, query,
+		review.MerchID, review.UserID, review.Rating, review.Body, review.Status, review.CreatedAt,
+	).Scan(&review.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return storage.ErrReviewExists
+		}
+		return fmt.Errorf("create review failed: %w", err)
+	}
+	return nil
+}
+
+func (r *ReviewRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, id int64) (*domain.Review, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	review, err := scanReview(tx.QueryRowContext(ctx, `SELECT `+reviewColumns+` WHERE r.id = $1 FOR UPDATE OF r`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to find review: %w", err)
+	}
+	return review, nil
+}
+
+func (r *ReviewRepository) FindByUserForUpdate(ctx context.Context, tx storage.Tx, userID int64, merchID int) (*domain.Review, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `SELECT ` + reviewColumns + ` WHERE r.user_id = $1 AND r.merch_id = $2 FOR UPDATE OF r`
+	review, err := scanReview(tx.QueryRowContext(ctx, query, userID, merchID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to find review: %w", err)
+	}
+	return review, nil
+}
+
+func (r *ReviewRepository) Update(ctx context.Context, tx storage.Tx, review *domain.Review) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        UPDATE merch_reviews
+        SET rating = $2, body = $3, status = $4, updated_at = $5, moderated_by = $6, moderated_at = $7
+        WHERE id = $1
+    `
+	var moderatedBy sql.NullInt64
+	if review.ModeratedBy != 0 {
+		moderatedBy = sql.NullInt64{Int64: review.ModeratedBy, Valid: true}
+	}
+	res, err := tx.ExecContext(ctx, query, review.ID, review.Rating, review.Body, review.Status, review.UpdatedAt,
+		moderatedBy, review.ModeratedAt)
+	if err != nil {
+		return fmt.Errorf("update review failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrReviewNotFound
+	}
+	return nil
+}
+
+func (r *ReviewRepository) Delete(ctx context.Context, tx storage.Tx, id int64) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM merch_reviews WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete review failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrReviewNotFound
+	}
+	return nil
+}
+
+func (r *ReviewRepository) GetByMerch(ctx context.Context, merchID int, limit int) ([]*domain.Review, error) {
+	query := `
+        SELECT ` + reviewColumns + `
+        WHERE r.merch_id = $1 AND r.status <> 'hidden'
+        ORDER BY r.created_at DESC, r.id DESC
+        LIMIT $2
+    `
+	return r.queryReviews(ctx, query, merchID, limit)
+}
+
+func (r *ReviewRepository) GetByStatus(ctx context.Context, status string, limit int) ([]*domain.Review, error) {
+	query := `
+        SELECT ` + reviewColumns + `
+        WHERE $1 = '' OR r.status = $1
+        ORDER BY r.created_at DESC, r.id DESC
+        LIMIT $2
+    `
+	return r.queryReviews(ctx, query, status, limit)
+}
+
+func (r *ReviewRepository) queryReviews(ctx context.Context, query string, args ...interface{}) ([]*domain.Review, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*domain.Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// AdjustStats применяет приращения одной командой: параллельные изменения отзывов одного товара не теряются.
+func (r *ReviewRepository) AdjustStats(ctx context.Context, tx storage.Tx, merchID int, countDelta, ratingDelta int) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+	query := `
+        INSERT INTO merch_review_stats (merch_id, review_count, rating_sum)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (merch_id) DO UPDATE
+        SET review_count = merch_review_stats.review_count + EXCLUDED.review_count,
+            rating_sum = merch_review_stats.rating_sum + EXCLUDED.rating_sum
+    `
+	if _, err := tx.ExecContext(ctx, query, merchID, countDelta, ratingDelta); err != nil {
+		return fmt.Errorf("adjust review stats failed: %w", err)
+	}
+	return nil
+}
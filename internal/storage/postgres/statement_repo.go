@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// все движения по счету пользователя в основной валюте: положительные суммы - зачисления, отрицательные - списания
+const userMovementsQuery = `
+    SELECT 'transfer_in' AS kind, ct.amount AS amount, u.username AS counterparty, '' AS description, ct.created_at AS created_at
+    FROM coin_transactions ct
+    JOIN users u ON u.id = ct.from_user_id
+    WHERE ct.to_user_id = $1 AND ct.currency = 'coin'
+    UNION ALL
+    SELECT 'transfer_out', -ct.amount, u.username, '', ct.created_at
+    FROM coin_transactions ct
+    JOIN users u ON u.id = ct.to_user_id
+    WHERE ct.from_user_id = $1 AND ct.currency = 'coin'
+    UNION ALL
+    SELECT 'transfer_fee', -ct.fee, '', '', ct.created_at
+    FROM coin_transactions ct
+    WHERE ct.from_user_id = $1 AND ct.currency = 'coin' AND ct.fee > 0
+    UNION ALL
+    SELECT 'fee_income', ct.fee, u.username, '', ct.fee_settled_at
+    FROM coin_transactions ct
+    JOIN users u ON u.id = ct.from_user_id
+    WHERE ct.fee_account_id = $1 AND ct.currency = 'coin' AND ct.fee > 0 AND ct.fee_settled_at IS NOT NULL
+    UNION ALL
+    SELECT 'purchase', -p.price, '', p.item, p.purchase_date
+    FROM purchases p
+    WHERE p.user_id = $1 AND p.gifted_by IS NULL AND p.hold_id IS NULL AND p.group_buy_id IS NULL
+      AND p.bundle_purchase_id IS NULL AND p.currency = 'coin'
+    UNION ALL
+    SELECT 'gift', -p.price, u.username, p.item, p.purchase_date
+    FROM purchases p
+    JOIN users u ON u.id = p.user_id
+    WHERE p.gifted_by = $1 AND p.bundle_purchase_id IS NULL AND p.currency = 'coin'
+    UNION ALL
+    SELECT 'hold_capture', -h.captured_amount, '', h.reason, h.captured_at
+    FROM balance_holds h
+    WHERE h.user_id = $1 AND h.currency = 'coin' AND h.status = 'captured' AND h.captured_amount > 0
+    UNION ALL
+    SELECT 'refund', r.amount, '', p.item, r.created_at
+    FROM refunds r
+    JOIN purchases p ON p.id = r.purchase_id
+    WHERE r.user_id = $1 AND r.currency = 'coin'
+    UNION ALL
+    SELECT 'raffle_tickets', -e.amount, '', m.name, e.created_at
+    FROM raffle_entries e
+    JOIN raffles rf ON rf.id = e.raffle_id
+    JOIN merch m ON m.id = rf.merch_id
+    WHERE e.user_id = $1
+    UNION ALL
+    SELECT 'raffle_refund', e.amount, '', m.name, e.refunded_at
+    FROM raffle_entries e
+    JOIN raffles rf ON rf.id = e.raffle_id
+    JOIN merch m ON m.id = rf.merch_id
+    WHERE e.user_id = $1 AND e.refunded_at IS NOT NULL
+`
+
+type StatementRepository struct {
+	db *sql.DB
+}
+
+func NewStatementRepository(db *sql.DB) *StatementRepository {
+	return &StatementRepository{db: db}
+}
+
+func (r *StatementRepository) Create(ctx context.Context, tx *sql.Tx, statement *domain.Statement) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	movements, err := json.Marshal(statement.Movements)
+	if err != nil {
+		return fmt.Errorf("failed to marshal movements: %w", err)
+	}
+
+	query := `
+        INSERT INTO account_statements
+            (user_id, period, opening_balance, closing_balance, total_credits, total_debits, movements, generated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
+    `
+	if statement.GeneratedAt.IsZero() {
+		statement.GeneratedAt = time.Now()
+	}
+	return tx.QueryRowContext(ctx, query,
+		statement.UserID,
+		statement.Period,
+		statement.OpeningBalance,
+		statement.ClosingBalance,
+		statement.TotalCredits,
+		statement.TotalDebits,
+		movements,
+		statement.GeneratedAt,
+	).Scan(&statement.ID)
+}
+
+func (r *StatementRepository) FindByUserAndPeriod(ctx context.Context, tx *sql.Tx, userID int64, period time.Time) (*domain.Statement, error) {
+	query := `
+        SELECT id, user_id, period, opening_balance, closing_balance, total_credits, total_debits, movements, generated_at
+        FROM account_statements
+        WHERE user_id = $1 AND period = $2
+    `
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, userID, period)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, userID, period)
+	}
+
+	var st domain.Statement
+	var movements []byte
+	err := row.Scan(&st.ID, &st.UserID, &st.Period, &st.OpeningBalance, &st.ClosingBalance,
+		&st.TotalCredits, &st.TotalDebits, &movements, &st.GeneratedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrStatementNotFound
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(movements, &st.Movements); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal movements: %w", err)
+	}
+	return &st, nil
+}
+
+func (r *StatementRepository) GetMovements(ctx context.Context, tx *sql.Tx, userID int64, from, to time.Time) ([]domain.StatementMovement, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT kind, amount, counterparty, description, created_at
+        FROM (` + userMovementsQuery + `) m
+        WHERE created_at >= $2 AND created_at < $3
+        ORDER BY created_at
+    `
+	rows, err := tx.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []domain.StatementMovement
+	for rows.Next() {
+		var m domain.StatementMovement
+		if err := rows.Scan(&m.Kind, &m.Amount, &m.Counterparty, &m.Description, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+func (r *StatementRepository) GetNetChangeSince(ctx context.Context, tx *sql.Tx, userID int64, since time.Time) (int, error) {
+	if tx == nil {
+		return 0, errs.ErrTransactionNotFound
+	}
+	query := `
+        SELECT COALESCE(SUM(amount), 0)
+        FROM (` + userMovementsQuery + `) m
+        WHERE created_at >= $2
+    `
+	var net int
+	if err := tx.QueryRowContext(ctx, query, userID, since).Scan(&net); err != nil {
+		return 0, err
+	}
+	return net, nil
+}
+
+func (r *StatementRepository) ListUsersWithoutStatement(ctx context.Context, period time.Time) ([]int64, error) {
+	query := `
+        SELECT u.id
+        FROM users u
+        WHERE u.created_at < $1::date + INTERVAL '1 month'
+          AND NOT EXISTS (
+              SELECT 1 FROM account_statements s
+              WHERE s.user_id = u.id AND s.period = $1
+          )
+        ORDER BY u.id
+    `
+	rows, err := r.db.QueryContext(ctx, query, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// перевод в валюте кошелька, в том числе его комиссия, не должен попадать в выписку по монетам:
+// иначе остатки выписки разойдутся с users.coins
+func TestUserMovementsQuery_WalletTransfersExcluded(t *testing.T) {
+	branches := strings.Split(userMovementsQuery, "UNION ALL")
+	checked := 0
+	for _, branch := range branches {
+		if !strings.Contains(branch, "FROM coin_transactions ct") {
+			continue
+		}
+		checked++
+		assert.Contains(t, branch, "ct.currency = 'coin'", "branch reads wallet transfers:\n%s", branch)
+	}
+	assert.Equal(t, 4, checked)
+}
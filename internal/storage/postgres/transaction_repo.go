@@ -22,13 +22,28 @@ func (r *TransactionRepository) Create(ctx context.Context, tx storage.Tx, trans
 		return errors.New("tx is nil")
 	}
 	query := `
-        INSERT INTO coin_transactions (from_user_id, to_user_id, amount, created_at)
-        VALUES ($1, $2, $3, $4) RETURNING id
+        INSERT INTO coin_transactions (from_user_id, to_user_id, amount, currency, fee, fee_account_id, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
     `
 	if transaction.CreatedAt.IsZero() {
 		transaction.CreatedAt = time.Now()
 	}
-	return tx.QueryRowContext(ctx, query, transaction.FromUserID, transaction.ToUserID, transaction.Amount, transaction.CreatedAt).Scan(&transaction.ID)
+	if transaction.Currency == "" {
+		transaction.Currency = domain.PrimaryCurrency
+	}
+	var feeAccountID sql.NullInt64
+	if transaction.Fee > 0 {
+		feeAccountID = sql.NullInt64{Int64: transaction.FeeAccountID, Valid: true}
+	}
+	return tx.QueryRowContext(ctx, query,
+		transaction.FromUserID,
+		transaction.ToUserID,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.Fee,
+		feeAccountID,
+		transaction.CreatedAt,
+	).Scan(&transaction.ID)
 }
 
 func (r *TransactionRepository) GetSentTransactions(ctx context.Context, userID int64) ([]*domain.CoinTransaction, error) {
@@ -37,7 +52,7 @@ func (r *TransactionRepository) GetSentTransactions(ctx context.Context, userID
 	}
 
 	query := `
-        SELECT id, from_user_id, to_user_id, amount, created_at
+        SELECT id, from_user_id, to_user_id, amount, currency, fee, COALESCE(fee_account_id, 0), created_at
         FROM coin_transactions
         WHERE from_user_id = $1
         ORDER BY created_at DESC
@@ -51,7 +66,7 @@ func (r *TransactionRepository) GetSentTransactions(ctx context.Context, userID
 	var transactions []*domain.CoinTransaction
 	for rows.Next() {
 		var t domain.CoinTransaction
-		if err := rows.Scan(&t.ID, &t.FromUserID, &t.ToUserID, &t.Amount, &t.CreatedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.FromUserID, &t.ToUserID, &t.Amount, &t.Currency, &t.Fee, &t.FeeAccountID, &t.CreatedAt); err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, &t)
@@ -61,7 +76,7 @@ func (r *TransactionRepository) GetSentTransactions(ctx context.Context, userID
 
 func (r *TransactionRepository) GetReceivedTransactions(ctx context.Context, userID int64) ([]*domain.CoinTransaction, error) {
 	query := `
-        SELECT id, from_user_id, to_user_id, amount, created_at
+        SELECT id, from_user_id, to_user_id, amount, currency, fee, COALESCE(fee_account_id, 0), created_at
         FROM coin_transactions
         WHERE to_user_id = $1
         ORDER BY created_at DESC
@@ -75,10 +90,52 @@ func (r *TransactionRepository) GetReceivedTransactions(ctx context.Context, use
 	var transactions []*domain.CoinTransaction
 	for rows.Next() {
 		var t domain.CoinTransaction
-		if err := rows.Scan(&t.ID, &t.FromUserID, &t.ToUserID, &t.Amount, &t.CreatedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.FromUserID, &t.ToUserID, &t.Amount, &t.Currency, &t.Fee, &t.FeeAccountID, &t.CreatedAt); err != nil {
 			return nil, err
 		}
 		transactions = append(transactions, &t)
 	}
 	return transactions, nil
 }
+
+func (r *TransactionRepository) CountSentSince(ctx context.Context, tx storage.Tx, userID int64, since time.Time) (int, error) {
+	query := `
+        SELECT COUNT(*)
+        FROM coin_transactions
+        WHERE from_user_id = $1 AND currency = 'coin' AND created_at >= $2
+    `
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, userID, since)
+	} else {
+		row = r.db.QueryRowContext(ctx, query, userID, since)
+	}
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SettleFees зачисляет на счета комиссий все еще не зачисленные комиссии переводов одним запросом.
+// Параллельный запуск не зачислит комиссию дважды: строки помечаются в том же запросе.
+func (r *TransactionRepository) SettleFees(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+        WITH settled AS (
+            UPDATE coin_transactions
+            SET fee_settled_at = $1
+            WHERE fee > 0 AND fee_settled_at IS NULL
+            RETURNING fee_account_id, fee
+        )
+        UPDATE users u
+        SET coins = u.coins + t.total
+        FROM (SELECT fee_account_id, SUM(fee) AS total FROM settled GROUP BY fee_account_id) t
+        WHERE u.id = t.fee_account_id
+    `
+	res, err := r.db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -11,6 +11,14 @@ import (
 	"time"
 )
 
+// сумма активных холдов пользователя в основной валюте
+const heldCoinsColumn = `
+    (SELECT COALESCE(SUM(h.amount), 0)
+     FROM balance_holds h
+     WHERE h.user_id = users.id AND h.currency = 'coin'
+       AND h.status = 'active' AND h.expires_at > now())
+`
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -36,23 +44,23 @@ func (r *UserRepository) Create(ctx context.Context, tx storage.Tx, user *domain
 
 	query := `
         INSERT INTO users (username, password_hash, coins, created_at)
-        VALUES ($1, $2, $3, $4) RETURNING id
+        VALUES ($1, $2, $3, $4) RETURNING id, role
     `
 	if user.CreatedAt.IsZero() {
 		user.CreatedAt = time.Now()
 	}
-	return tx.QueryRowContext(ctx, query, user.Username, user.PasswordHash, user.Coins, user.CreatedAt).Scan(&user.ID)
+	return tx.QueryRowContext(ctx, query, user.Username, user.PasswordHash, user.Coins, user.CreatedAt).Scan(&user.ID, &user.Role)
 }
 
 func (r *UserRepository) FindByID(ctx context.Context, id int64) (*domain.User, error) {
 	query := `
-        SELECT id, username, password_hash, coins, created_at
+        SELECT id, username, password_hash, coins, ` + heldCoinsColumn + `, role, COALESCE(team, ''), created_at
         FROM users WHERE id = $1
     `
 	row := r.db.QueryRowContext(ctx, query, id)
 
 	var user domain.User
-	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins, &user.HeldCoins, &user.Role, &user.Team, &user.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, storage.ErrUserNotFound
@@ -64,12 +72,12 @@ func (r *UserRepository) FindByID(ctx context.Context, id int64) (*domain.User,
 
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := `
-        SELECT id, username, password_hash, coins, created_at
+        SELECT id, username, password_hash, coins, role, COALESCE(team, ''), created_at
         FROM users WHERE username = $1
     `
 	row := r.db.QueryRowContext(ctx, query, username)
 	var user domain.User
-	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins, &user.Role, &user.Team, &user.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, storage.ErrUserNotFound
@@ -117,7 +125,7 @@ func (r *UserRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, i
 		return nil, errs.ErrTransactionNotFound
 	}
 	query := `
-        SELECT id, username, password_hash, coins, created_at
+        SELECT id, username, password_hash, coins, ` + heldCoinsColumn + `, role, COALESCE(team, ''), created_at
         FROM users
         WHERE id = $1
         FOR UPDATE
@@ -125,7 +133,7 @@ func (r *UserRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, i
 	row := tx.QueryRowContext(ctx, query, id)
 
 	var user domain.User
-	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins, &user.HeldCoins, &user.Role, &user.Team, &user.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, storage.ErrUserNotFound
@@ -134,3 +142,19 @@ func (r *UserRepository) FindByIDForUpdate(ctx context.Context, tx storage.Tx, i
 	}
 	return &user, nil
 }
+
+// SetTeam меняет команду пользователя, пустая строка убирает его из команды.
+func (r *UserRepository) SetTeam(ctx context.Context, userID int64, team string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET team = NULLIF($2, '') WHERE id = $1`, userID, team)
+	if err != nil {
+		return fmt.Errorf("set team failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrUserNotFound
+	}
+	return nil
+}
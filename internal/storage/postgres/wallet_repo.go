@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// сумма активных холдов по кошельку
+const heldWalletColumn = `
+    (SELECT COALESCE(SUM(h.amount), 0)
+     FROM balance_holds h
+     WHERE h.user_id = wallets.user_id AND h.currency = wallets.currency
+       AND h.status = 'active' AND h.expires_at > now())
+`
+
+type WalletRepository struct {
+	db *sql.DB
+}
+
+func NewWalletRepository(db *sql.DB) *WalletRepository {
+	return &WalletRepository{db: db}
+}
+
+func (r *WalletRepository) GetByUser(ctx context.Context, userID int64) ([]*domain.Wallet, error) {
+	query := `
+        SELECT user_id, currency, balance, ` + heldWalletColumn + `
+        FROM wallets
+        WHERE user_id = $1
+        ORDER BY currency
+    `
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []*domain.Wallet
+	for rows.Next() {
+		var w domain.Wallet
+		if err := rows.Scan(&w.UserID, &w.Currency, &w.Balance, &w.Held); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, &w)
+	}
+	return wallets, rows.Err()
+}
+
+// FindForUpdate блокирует кошелек пользователя, при отсутствии создает пустой.
+func (r *WalletRepository) FindForUpdate(ctx context.Context, tx storage.Tx, userID int64, currency string) (*domain.Wallet, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+
+	insert := `
+        INSERT INTO wallets (user_id, currency)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id, currency) DO NOTHING
+    `
+	if _, err := tx.ExecContext(ctx, insert, userID, currency); err != nil {
+		return nil, fmt.Errorf("create wallet failed: %w", err)
+	}
+
+	query := `
+        SELECT user_id, currency, balance, ` + heldWalletColumn + `
+        FROM wallets
+        WHERE user_id = $1 AND currency = $2
+        FOR UPDATE
+    `
+	var w domain.Wallet
+	err := tx.QueryRowContext(ctx, query, userID, currency).Scan(&w.UserID, &w.Currency, &w.Balance, &w.Held)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrWalletNotFound
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (r *WalletRepository) Update(ctx context.Context, tx storage.Tx, wallet *domain.Wallet) error {
+	if tx == nil {
+		return errs.ErrTransactionNotFound
+	}
+
+	query := `
+        UPDATE wallets SET balance = $1
+        WHERE user_id = $2 AND currency = $3
+    `
+	res, err := tx.ExecContext(ctx, query, wallet.Balance, wallet.UserID, wallet.Currency)
+	if err != nil {
+		return fmt.Errorf("update wallet failed: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrWalletNotFound
+	}
+	return nil
+}
+
+func (r *WalletRepository) CurrencyExists(ctx context.Context, currency string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM currencies WHERE code = $1)`, currency).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"avito-backend-intern-winter25/internal/storage"
+	"avito-backend-intern-winter25/pkg/errs"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// merchInStockColumn - товар можно купить сейчас: есть остаток, не распродан тираж и идет окно продаж
+const merchInStockColumn = `(COALESCE(` + merchStockColumn + `, 1) > 0
+        AND (merch.drop_cap IS NULL OR COALESCE(` + dropLeftColumn + `, 0) > 0)
+        AND ` + merchOnSaleCondition + `)`
+
+type WishlistRepository struct {
+	db *sql.DB
+}
+
+func NewWishlistRepository(db *sql.DB) *WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+// Add добавляет товар в вишлист, текущие цена и наличие запоминаются для оповещений.
+func (r *WishlistRepository) Add(ctx context.Context, userID int64, merchID int) error {
+	query := `
+        INSERT INTO wishlist_items (user_id, merch_id, seen_price, seen_in_stock)
+        SELECT $1, merch.id, ` + effectivePriceColumn + `, ` + merchInStockColumn + `
+        FROM merch
+        WHERE merch.id = $2
+        ON CONFLICT (user_id, merch_id) DO NOTHING
+    `
+	if _, err := r.db.ExecContext(ctx, query, userID, merchID); err != nil {
+		return fmt.Errorf("add wishlist item failed: %w", err)
+	}
+	return nil
+}
+
+func (r *WishlistRepository) Remove(ctx context.Context, userID int64, merchID int) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM wishlist_items WHERE user_id = $1 AND merch_id = $2`, userID, merchID)
+	if err != nil {
+		return fmt.Errorf("remove wishlist item failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrWishlistItemNotFound
+	}
+	return nil
+}
+
+func (r *WishlistRepository) GetByUser(ctx context.Context, userID int64) ([]*domain.WishlistItem, error) {
+	query := `
+        SELECT ` + merchColumns + `, w.user_id, w.seen_price, w.seen_in_stock, w.added_at
+        FROM wishlist_items w
+        JOIN merch ON merch.id = w.merch_id
+        WHERE w.user_id = $1
+        ORDER BY w.added_at DESC, merch.id
+    `
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*domain.WishlistItem
+	for rows.Next() {
+		var item domain.WishlistItem
+		m, err := scanMerch(rows, &item.UserID, &item.SeenPrice, &item.SeenInStock, &item.AddedAt)
+		if err != nil {
+			return nil, err
+		}
+		item.Item = m
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+func (r *WishlistRepository) GetMerchIDs(ctx context.Context, userID int64) (map[int]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT merch_id FROM wishlist_items WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+func (r *WishlistRepository) SetShared(ctx context.Context, userID int64, shared bool) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET wishlist_shared = $2 WHERE id = $1`, userID, shared)
+	if err != nil {
+		return fmt.Errorf("update wishlist sharing failed: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected error: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *WishlistRepository) IsShared(ctx context.Context, userID int64) (bool, error) {
+	var shared bool
+	err := r.db.QueryRowContext(ctx, `SELECT wishlist_shared FROM users WHERE id = $1`, userID).Scan(&shared)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, storage.ErrUserNotFound
+		}
+		return false, err
+	}
+	return shared, nil
+}
+
+// CollectAlerts находит товары вишлистов, у которых цена или наличие изменились с прошлой проверки,
+// и сразу запоминает новое состояние. Строки, которые обрабатывает другой экземпляр задачи, пропускаются.
+func (r *WishlistRepository) CollectAlerts(ctx context.Context, tx *sql.Tx, limit int) ([]*domain.WishlistAlert, error) {
+	if tx == nil {
+		return nil, errs.ErrTransactionNotFound
+	}
+	query := `
+        WITH changed AS (
+            SELECT w.user_id, w.merch_id, merch.name, merch.currency, w.seen_price, w.seen_in_stock,
+                   ` + effectivePriceColumn + ` AS price, ` + merchInStockColumn + ` AS in_stock
+            FROM wishlist_items w
+            JOIN merch ON merch.id = w.merch_id
+            WHERE merch.active
+              AND (w.seen_price <> ` + effectivePriceColumn + ` OR w.seen_in_stock <> ` + merchInStockColumn + `)
+            ORDER BY w.merch_id, w.user_id
+            LIMIT $1
+            FOR UPDATE OF w SKIP LOCKED
+        )
+        UPDATE wishlist_items w
+        SET seen_price = c.price, seen_in_stock = c.in_stock
+        FROM changed c
+        WHERE w.user_id = c.user_id AND w.merch_id = c.merch_id
+        RETURNING c.user_id, c.merch_id, c.name, c.currency, c.seen_price, c.price, c.seen_in_stock, c.in_stock
+    `
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("collect wishlist alerts failed: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*domain.WishlistAlert
+	for rows.Next() {
+		var a domain.WishlistAlert
+		if err := rows.Scan(&a.UserID, &a.MerchID, &a.Name, &a.Currency, &a.OldPrice, &a.Price,
+			&a.WasInStock, &a.InStock); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, &a)
+	}
+	return alerts, rows.Err()
+}
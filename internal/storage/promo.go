@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+)
+
+var (
+	ErrPromoCodeNotFound  = errors.New("promo code not found")
+	ErrPromoCodeTaken     = errors.New("promo code already exists")
+	ErrPromoCodeExhausted = errors.New("promo code usage limit reached")
+)
+
+type PromoCodeRepository interface {
+	Create(ctx context.Context, tx Tx, promo *domain.PromoCode) error
+	GetAll(ctx context.Context) ([]*domain.PromoCode, error)
+	FindByCodeForUpdate(ctx context.Context, tx Tx, code string) (*domain.PromoCode, error)
+	SetActive(ctx context.Context, id int64, active bool) error
+	IncrementUses(ctx context.Context, tx Tx, id int64) error
+	CountUserRedemptions(ctx context.Context, tx Tx, promoID, userID int64) (int, error)
+	CreateRedemption(ctx context.Context, tx Tx, redemption *domain.PromoRedemption) error
+	ReleaseRedemption(ctx context.Context, tx Tx, orderID int64) error
+}
@@ -4,9 +4,14 @@ import (
 	"avito-backend-intern-winter25/internal/models/domain"
 	"context"
 	"database/sql"
+	"time"
 )
 
 type PurchaseRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, purchase *domain.Purchase) error
 	GetByUser(ctx context.Context, tx *sql.Tx, userID int64) ([]*domain.Purchase, error)
+	GetByOrder(ctx context.Context, tx *sql.Tx, orderID int64) ([]*domain.Purchase, error)
+	CountUserItem(ctx context.Context, tx *sql.Tx, userID int64, merchID int, since time.Time) (int, error)
+	// HasPurchased сообщает, есть ли у пользователя покупка товара в неотмененном заказе.
+	HasPurchased(ctx context.Context, userID int64, merchID int) (bool, error)
 }
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrRaffleNotFound = errors.New("raffle not found")
+)
+
+type RaffleRepository interface {
+	Create(ctx context.Context, tx Tx, raffle *domain.Raffle) error
+	FindByID(ctx context.Context, id int64) (*domain.Raffle, error)
+	FindByIDForUpdate(ctx context.Context, tx Tx, id int64) (*domain.Raffle, error)
+	// ClaimDue блокирует один открытый розыгрыш, время которого пришло; занятые другими пропускаются.
+	ClaimDue(ctx context.Context, tx Tx, now time.Time) (*domain.Raffle, error)
+	GetActive(ctx context.Context) ([]*domain.Raffle, error)
+	Update(ctx context.Context, tx Tx, raffle *domain.Raffle) error
+	CreateEntry(ctx context.Context, tx Tx, entry *domain.RaffleEntry) error
+	CountUserTickets(ctx context.Context, tx Tx, raffleID, userID int64) (int, error)
+	// GetEntries возвращает покупки билетов по возрастанию номеров, tx может быть nil.
+	GetEntries(ctx context.Context, tx *sql.Tx, raffleID int64) ([]*domain.RaffleEntry, error)
+	MarkEntriesRefunded(ctx context.Context, tx Tx, raffleID int64, at time.Time) error
+	CreateWinner(ctx context.Context, tx Tx, winner *domain.RaffleWinner) error
+	GetWinners(ctx context.Context, raffleID int64) ([]*domain.RaffleWinner, error)
+}
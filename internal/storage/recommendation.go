@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// RecommendationRepository читает заранее посчитанные рекомендации. Все выборки исключают товары,
+// которые пользователь уже купил, и товары, которые сейчас нельзя купить.
+type RecommendationRepository interface {
+	// Refresh пересчитывает таблицы рекомендаций, оставляя не больше perItem строк на товар и на команду.
+	Refresh(ctx context.Context, tx *sql.Tx, now time.Time, perItem int) error
+	AlsoBoughtWith(ctx context.Context, userID int64, merchID int, limit int) ([]*domain.Recommendation, error)
+	// AlsoBoughtForUser складывает рекомендации по всем купленным пользователем товарам.
+	AlsoBoughtForUser(ctx context.Context, userID int64, limit int) ([]*domain.Recommendation, error)
+	PopularInTeam(ctx context.Context, userID int64, team string, limit int) ([]*domain.Recommendation, error)
+}
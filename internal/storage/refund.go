@@ -0,0 +1,10 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+)
+
+type RefundRepository interface {
+	Create(ctx context.Context, tx Tx, refund *domain.Refund) error
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+)
+
+var (
+	ErrReviewNotFound = errors.New("review not found")
+	ErrReviewExists   = errors.New("review already exists")
+)
+
+type ReviewRepository interface {
+	// Create возвращает ErrReviewExists, если пользователь уже оставил отзыв на товар.
+	Create(ctx context.Context, tx Tx, review *domain.Review) error
+	FindByIDForUpdate(ctx context.Context, tx Tx, id int64) (*domain.Review, error)
+	FindByUserForUpdate(ctx context.Context, tx Tx, userID int64, merchID int) (*domain.Review, error)
+	Update(ctx context.Context, tx Tx, review *domain.Review) error
+	Delete(ctx context.Context, tx Tx, id int64) error
+	// GetByMerch возвращает видимые отзывы товара, новые первыми.
+	GetByMerch(ctx context.Context, merchID int, limit int) ([]*domain.Review, error)
+	// GetByStatus возвращает отзывы для модерации, status == "" - все.
+	GetByStatus(ctx context.Context, status string, limit int) ([]*domain.Review, error)
+	// AdjustStats меняет агрегаты товара на приращения.
+	AdjustStats(ctx context.Context, tx Tx, merchID int, countDelta, ratingDelta int) error
+}
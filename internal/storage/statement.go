@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrStatementNotFound = errors.New("statement not found")
+)
+
+type StatementRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, statement *domain.Statement) error
+	FindByUserAndPeriod(ctx context.Context, tx *sql.Tx, userID int64, period time.Time) (*domain.Statement, error)
+	GetMovements(ctx context.Context, tx *sql.Tx, userID int64, from, to time.Time) ([]domain.StatementMovement, error)
+	GetNetChangeSince(ctx context.Context, tx *sql.Tx, userID int64, since time.Time) (int, error)
+	ListUsersWithoutStatement(ctx context.Context, period time.Time) ([]int64, error)
+}
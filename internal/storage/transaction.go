@@ -4,6 +4,7 @@ import (
 	"avito-backend-intern-winter25/internal/models/domain"
 	"context"
 	"errors"
+	"time"
 )
 
 var (
@@ -14,4 +15,6 @@ type TransactionRepository interface {
 	Create(ctx context.Context, tx Tx, transaction *domain.CoinTransaction) error
 	GetSentTransactions(ctx context.Context, userID int64) ([]*domain.CoinTransaction, error)
 	GetReceivedTransactions(ctx context.Context, userID int64) ([]*domain.CoinTransaction, error)
+	CountSentSince(ctx context.Context, tx Tx, userID int64, since time.Time) (int, error)
+	SettleFees(ctx context.Context, now time.Time) (int64, error)
 }
@@ -16,5 +16,6 @@ type UserRepository interface {
 	FindByIDForUpdate(ctx context.Context, tx Tx, id int64) (*domain.User, error)
 	FindByID(ctx context.Context, id int64) (*domain.User, error)
 	FindByUsername(ctx context.Context, username string) (*domain.User, error)
+	SetTeam(ctx context.Context, userID int64, team string) error
 	BeginTx(ctx context.Context) (Tx, error)
 }
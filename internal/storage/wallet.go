@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"errors"
+)
+
+var (
+	ErrCurrencyNotFound = errors.New("currency not found")
+	ErrWalletNotFound   = errors.New("wallet not found")
+)
+
+type WalletRepository interface {
+	GetByUser(ctx context.Context, userID int64) ([]*domain.Wallet, error)
+	FindForUpdate(ctx context.Context, tx Tx, userID int64, currency string) (*domain.Wallet, error)
+	Update(ctx context.Context, tx Tx, wallet *domain.Wallet) error
+	CurrencyExists(ctx context.Context, currency string) (bool, error)
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"avito-backend-intern-winter25/internal/models/domain"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrWishlistItemNotFound = errors.New("wishlist item not found")
+)
+
+type WishlistRepository interface {
+	Add(ctx context.Context, userID int64, merchID int) error
+	Remove(ctx context.Context, userID int64, merchID int) error
+	GetByUser(ctx context.Context, userID int64) ([]*domain.WishlistItem, error)
+	GetMerchIDs(ctx context.Context, userID int64) (map[int]bool, error)
+	SetShared(ctx context.Context, userID int64, shared bool) error
+	IsShared(ctx context.Context, userID int64) (bool, error)
+	CollectAlerts(ctx context.Context, tx *sql.Tx, limit int) ([]*domain.WishlistAlert, error)
+}
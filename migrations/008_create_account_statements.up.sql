@@ -0,0 +1,23 @@
+CREATE TABLE account_statements (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    period DATE NOT NULL,
+    opening_balance INTEGER NOT NULL,
+    closing_balance INTEGER NOT NULL,
+    total_credits INTEGER NOT NULL,
+    total_debits INTEGER NOT NULL,
+    movements JSONB NOT NULL DEFAULT '[]',
+    generated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    UNIQUE (user_id, period)
+);
+
+-- выписка после формирования не меняется
+CREATE FUNCTION forbid_statement_changes() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'account statements are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER account_statements_immutable
+    BEFORE UPDATE OR DELETE ON account_statements
+    FOR EACH ROW EXECUTE FUNCTION forbid_statement_changes();
@@ -0,0 +1,8 @@
+ALTER TABLE coin_transactions
+    ADD COLUMN fee INTEGER NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    ADD COLUMN fee_account_id INTEGER REFERENCES users(id);
+
+-- системный счет для комиссий, войти под ним нельзя: хеш пароля невалиден для bcrypt
+INSERT INTO users (username, password_hash, coins)
+VALUES ('system:fees', '!', 0)
+ON CONFLICT (username) DO NOTHING;
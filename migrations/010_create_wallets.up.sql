@@ -0,0 +1,24 @@
+CREATE TABLE currencies (
+    code VARCHAR(32) PRIMARY KEY,
+    name TEXT NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE UNIQUE INDEX idx_currencies_primary ON currencies(is_primary) WHERE is_primary;
+
+INSERT INTO currencies (code, name, is_primary) VALUES
+('coin', 'Coins', TRUE),
+('event_token', 'Event tokens', FALSE)
+ON CONFLICT (code) DO NOTHING;
+
+-- баланс основной валюты по-прежнему хранится в users.coins
+CREATE TABLE wallets (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency VARCHAR(32) NOT NULL REFERENCES currencies(code) CHECK (currency <> 'coin'),
+    balance INTEGER NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    PRIMARY KEY (user_id, currency)
+);
+
+ALTER TABLE merch ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'coin' REFERENCES currencies(code);
+ALTER TABLE purchases ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'coin' REFERENCES currencies(code);
+ALTER TABLE coin_transactions ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'coin' REFERENCES currencies(code);
@@ -0,0 +1,18 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'employee';
+
+CREATE TABLE balance_holds (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency VARCHAR(32) NOT NULL DEFAULT 'coin' REFERENCES currencies(code),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    captured_amount INTEGER NOT NULL DEFAULT 0 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    captured_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_balance_holds_active ON balance_holds(user_id, currency) WHERE status = 'active';
+CREATE INDEX idx_balance_holds_expires ON balance_holds(expires_at) WHERE status = 'active';
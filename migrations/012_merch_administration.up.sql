@@ -0,0 +1,27 @@
+UPDATE merch SET price = 1 WHERE price IS NULL OR price <= 0;
+
+ALTER TABLE merch
+    ALTER COLUMN price SET NOT NULL,
+    ADD CONSTRAINT merch_price_positive CHECK (price > 0),
+    ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    ADD COLUMN retired_at TIMESTAMP WITH TIME ZONE;
+
+-- покупки ссылаются на товар по id, чтобы переименование не теряло связь; item остается снимком названия
+ALTER TABLE purchases ADD COLUMN merch_id INTEGER REFERENCES merch(id);
+UPDATE purchases p SET merch_id = m.id FROM merch m WHERE m.name = p.item;
+CREATE INDEX idx_purchases_merch_id ON purchases(merch_id);
+
+CREATE TABLE merch_audit (
+    id SERIAL PRIMARY KEY,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    admin_id INTEGER NOT NULL REFERENCES users(id),
+    action VARCHAR(32) NOT NULL,
+    field VARCHAR(32) NOT NULL DEFAULT '',
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_merch_audit_merch_id ON merch_audit(merch_id);
@@ -0,0 +1,2 @@
+-- NULL - товар без ограничения количества (например, цифровой)
+ALTER TABLE merch ADD COLUMN stock INTEGER CONSTRAINT merch_stock_not_negative CHECK (stock >= 0);
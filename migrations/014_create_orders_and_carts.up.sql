@@ -0,0 +1,22 @@
+CREATE TABLE orders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_orders_user_id ON orders(user_id);
+
+-- price в purchases - сумма за всю строку (цена за штуку * quantity), поэтому выписки считаются как раньше
+ALTER TABLE purchases
+    ADD COLUMN order_id INTEGER REFERENCES orders(id),
+    ADD COLUMN quantity INTEGER NOT NULL DEFAULT 1 CONSTRAINT purchases_quantity_positive CHECK (quantity > 0);
+
+CREATE INDEX idx_purchases_order_id ON purchases(order_id);
+
+CREATE TABLE cart_items (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    PRIMARY KEY (user_id, merch_id)
+);
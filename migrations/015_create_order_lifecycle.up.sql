@@ -0,0 +1,22 @@
+ALTER TABLE orders
+    ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'placed'
+        CONSTRAINT orders_status_check
+        CHECK (status IN ('placed', 'approved', 'packed', 'shipped', 'ready_for_pickup', 'delivered', 'cancelled')),
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT now();
+
+CREATE INDEX idx_orders_status ON orders(status);
+
+-- каждый переход фиксируется отдельной строкой, отсюда берутся время упаковки, отправки и т.д.
+CREATE TABLE order_status_history (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id),
+    from_status VARCHAR(32) NOT NULL DEFAULT '',
+    to_status VARCHAR(32) NOT NULL,
+    changed_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history(order_id);
+
+INSERT INTO order_status_history (order_id, to_status, changed_by, created_at)
+SELECT id, 'placed', user_id, created_at FROM orders;
@@ -0,0 +1,13 @@
+-- возврат - отдельное движение, покупка при отмене не удаляется
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id),
+    purchase_id INTEGER NOT NULL UNIQUE REFERENCES purchases(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    currency VARCHAR(32) NOT NULL REFERENCES currencies(code),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_refunds_user_id ON refunds(user_id);
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
@@ -0,0 +1,20 @@
+-- история цен: действующая цена - последняя запись с наступившей effective_from,
+-- запись с будущей датой - запланированное изменение
+CREATE TABLE merch_prices (
+    id SERIAL PRIMARY KEY,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    price INTEGER NOT NULL CHECK (price > 0),
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_merch_prices_merch_effective ON merch_prices(merch_id, effective_from DESC);
+
+INSERT INTO merch_prices (merch_id, price, effective_from)
+SELECT id, price, COALESCE(created_at, now()) FROM merch;
+
+-- цена за штуку на момент покупки
+ALTER TABLE purchases ADD COLUMN unit_price INTEGER;
+UPDATE purchases SET unit_price = price / quantity;
+ALTER TABLE purchases ALTER COLUMN unit_price SET NOT NULL;
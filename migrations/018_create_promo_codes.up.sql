@@ -0,0 +1,39 @@
+ALTER TABLE merch ADD COLUMN category VARCHAR(50);
+
+CREATE TABLE promo_codes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value INTEGER NOT NULL CHECK (value > 0),
+    -- пустые списки - код действует на весь каталог в валюте кода
+    merch_ids INTEGER[] NOT NULL DEFAULT '{}',
+    categories TEXT[] NOT NULL DEFAULT '{}',
+    currency VARCHAR(32) NOT NULL DEFAULT 'coin' REFERENCES currencies(code),
+    min_spend INTEGER NOT NULL DEFAULT 0 CHECK (min_spend >= 0),
+    valid_from TIMESTAMP WITH TIME ZONE,
+    valid_until TIMESTAMP WITH TIME ZONE,
+    max_uses INTEGER CHECK (max_uses > 0),
+    max_uses_per_user INTEGER CHECK (max_uses_per_user > 0),
+    uses INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CONSTRAINT promo_codes_percent_range CHECK (kind <> 'percent' OR value <= 100),
+    CONSTRAINT promo_codes_uses_limit CHECK (max_uses IS NULL OR uses <= max_uses)
+);
+
+CREATE TABLE promo_code_redemptions (
+    id SERIAL PRIMARY KEY,
+    promo_code_id INTEGER NOT NULL REFERENCES promo_codes(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    order_id INTEGER NOT NULL REFERENCES orders(id),
+    discount INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_promo_code_redemptions_code_user ON promo_code_redemptions(promo_code_id, user_id);
+
+-- price - фактически оплаченная сумма строки, discount - скидка по промокоду
+ALTER TABLE purchases
+    ADD COLUMN discount INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN promo_code_id INTEGER REFERENCES promo_codes(id);
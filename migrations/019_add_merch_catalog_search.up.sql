@@ -0,0 +1,16 @@
+ALTER TABLE merch
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- конфигурация simple: названия товаров латиницей, описания на любом языке, без стемминга
+ALTER TABLE merch ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', replace(name, '-', ' ')), 'A') ||
+    setweight(to_tsvector('simple', description), 'B')
+) STORED;
+
+CREATE INDEX idx_merch_search_vector ON merch USING GIN (search_vector);
+CREATE INDEX idx_merch_tags ON merch USING GIN (tags);
+CREATE INDEX idx_merch_category ON merch(category) WHERE active;
+
+-- для сортировки по популярности
+CREATE INDEX idx_purchases_merch_id ON purchases(merch_id);
@@ -0,0 +1,14 @@
+ALTER TABLE merch ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+-- сами файлы лежат в хранилище blob-ов, здесь только ключи
+CREATE TABLE merch_images (
+    id SERIAL PRIMARY KEY,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    blob_key VARCHAR(255) NOT NULL UNIQUE,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_merch_images_merch_id ON merch_images(merch_id, position);
@@ -0,0 +1,23 @@
+-- вариант товара (размер/цвет) со своим остатком; stock NULL - без учета остатков
+CREATE TABLE merch_variants (
+    id SERIAL PRIMARY KEY,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    size VARCHAR(16) NOT NULL DEFAULT '',
+    color VARCHAR(32) NOT NULL DEFAULT '',
+    price_delta INTEGER NOT NULL DEFAULT 0,
+    stock INTEGER CHECK (stock >= 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    UNIQUE (merch_id, size, color)
+);
+
+ALTER TABLE purchases
+    ADD COLUMN variant_id INTEGER REFERENCES merch_variants(id),
+    ADD COLUMN sku VARCHAR(64) NOT NULL DEFAULT '';
+
+-- один товар в корзине может лежать в нескольких вариантах, 0 - товар без вариантов
+ALTER TABLE cart_items ADD COLUMN variant_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE cart_items DROP CONSTRAINT cart_items_pkey;
+ALTER TABLE cart_items ADD PRIMARY KEY (user_id, merch_id, variant_id);
@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN team VARCHAR(50);
+
+-- ограничения на покупку товара; NULL/пустой массив - ограничения нет
+CREATE TABLE merch_purchase_rules (
+    merch_id INTEGER PRIMARY KEY REFERENCES merch(id),
+    max_quantity INTEGER CHECK (max_quantity > 0),
+    period_days INTEGER CHECK (period_days > 0),
+    min_account_age_days INTEGER CHECK (min_account_age_days > 0),
+    allowed_roles TEXT[] NOT NULL DEFAULT '{}',
+    allowed_teams TEXT[] NOT NULL DEFAULT '{}',
+    updated_by INTEGER REFERENCES users(id),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+-- лимит считается по покупкам пользователя за период
+CREATE INDEX idx_purchases_user_merch_date ON purchases(user_id, merch_id, purchase_date);
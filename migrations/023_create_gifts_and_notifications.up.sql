@@ -0,0 +1,18 @@
+-- подарок: покупка записывается на получателя, gifted_by - кто заплатил (заказ тоже его)
+ALTER TABLE purchases
+    ADD COLUMN gifted_by INTEGER REFERENCES users(id),
+    ADD COLUMN gift_message VARCHAR(500) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_purchases_gifted_by ON purchases(gifted_by) WHERE gifted_by IS NOT NULL;
+
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at DESC);
@@ -0,0 +1,14 @@
+-- вишлист виден коллегам по команде, если пользователь включил общий доступ
+ALTER TABLE users ADD COLUMN wishlist_shared BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- seen_price/seen_in_stock - состояние товара при последней проверке, с ним сравнивает задача оповещений
+CREATE TABLE wishlist_items (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    seen_price INTEGER NOT NULL,
+    seen_in_stock BOOLEAN NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    PRIMARY KEY (user_id, merch_id)
+);
+
+CREATE INDEX idx_wishlist_items_merch_id ON wishlist_items(merch_id);
@@ -0,0 +1,13 @@
+-- окно продаж и тираж дропа; NULL - без ограничений
+ALTER TABLE merch ADD COLUMN available_from TIMESTAMP WITH TIME ZONE;
+ALTER TABLE merch ADD COLUMN available_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE merch ADD COLUMN drop_cap INTEGER CHECK (drop_cap > 0);
+ALTER TABLE merch ADD CONSTRAINT merch_availability_window CHECK (available_until > available_from);
+
+-- остаток тиража разбит на шарды: покупатели в начале дропа блокируют разные строки, а не строку товара
+CREATE TABLE merch_drop_shards (
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    shard SMALLINT NOT NULL,
+    remaining INTEGER NOT NULL CHECK (remaining >= 0),
+    PRIMARY KEY (merch_id, shard)
+);
@@ -0,0 +1,37 @@
+-- anti_snipe_seconds: ставка ближе к концу, чем это окно, продлевает аукцион на то же время от момента ставки
+CREATE TABLE auctions (
+    id SERIAL PRIMARY KEY,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    currency VARCHAR(32) NOT NULL REFERENCES currencies(code),
+    starting_price INTEGER NOT NULL CHECK (starting_price > 0),
+    min_increment INTEGER NOT NULL CHECK (min_increment > 0),
+    anti_snipe_seconds INTEGER NOT NULL DEFAULT 0 CHECK (anti_snipe_seconds >= 0),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    top_bid_id INTEGER,
+    order_id INTEGER REFERENCES orders(id),
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    settled_at TIMESTAMP WITH TIME ZONE,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_auctions_open_ends_at ON auctions(ends_at) WHERE status = 'open';
+
+-- каждая ставка резервирует сумму холдом, холд перебитой ставки освобождается
+CREATE TABLE auction_bids (
+    id SERIAL PRIMARY KEY,
+    auction_id INTEGER NOT NULL REFERENCES auctions(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    hold_id INTEGER NOT NULL REFERENCES balance_holds(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_auction_bids_auction ON auction_bids(auction_id, amount DESC);
+
+ALTER TABLE auctions ADD CONSTRAINT auctions_top_bid_fk FOREIGN KEY (top_bid_id) REFERENCES auction_bids(id);
+
+-- покупка, оплаченная списанием холда: деньги в выписке - это hold_capture, а не сама покупка
+ALTER TABLE purchases ADD COLUMN hold_id INTEGER REFERENCES balance_holds(id);
@@ -0,0 +1,43 @@
+-- seed_hash публикуется при создании, seed раскрывается при розыгрыше: победителей можно пересчитать по seed и списку билетов
+CREATE TABLE raffles (
+    id SERIAL PRIMARY KEY,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    ticket_price INTEGER NOT NULL CHECK (ticket_price > 0),
+    max_tickets_per_user INTEGER NOT NULL DEFAULT 0 CHECK (max_tickets_per_user >= 0),
+    prizes INTEGER NOT NULL CHECK (prizes > 0),
+    draw_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    seed VARCHAR(64) NOT NULL,
+    seed_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    tickets_sold INTEGER NOT NULL DEFAULT 0,
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    drawn_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_raffles_open_draw_at ON raffles(draw_at) WHERE status = 'open';
+
+-- одна покупка билетов: номера first_ticket .. first_ticket + quantity - 1
+CREATE TABLE raffle_entries (
+    id SERIAL PRIMARY KEY,
+    raffle_id INTEGER NOT NULL REFERENCES raffles(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    first_ticket INTEGER NOT NULL CHECK (first_ticket > 0),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    refunded_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (raffle_id, first_ticket)
+);
+
+CREATE INDEX idx_raffle_entries_user ON raffle_entries(raffle_id, user_id);
+CREATE INDEX idx_raffle_entries_user_created_at ON raffle_entries(user_id, created_at);
+
+CREATE TABLE raffle_winners (
+    raffle_id INTEGER NOT NULL REFERENCES raffles(id),
+    place INTEGER NOT NULL,
+    ticket INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    order_id INTEGER NOT NULL REFERENCES orders(id),
+    PRIMARY KEY (raffle_id, place)
+);
@@ -0,0 +1,30 @@
+-- target - цена quantity штук на момент создания; взносы резервируются холдами и списываются разом при сборе суммы
+CREATE TABLE group_buys (
+    id SERIAL PRIMARY KEY,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    currency VARCHAR(32) NOT NULL REFERENCES currencies(code),
+    target INTEGER NOT NULL CHECK (target > 0),
+    creator_id INTEGER NOT NULL REFERENCES users(id),
+    deadline TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    order_id INTEGER REFERENCES orders(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    closed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_group_buys_open_deadline ON group_buys(deadline) WHERE status = 'open';
+
+CREATE TABLE group_buy_pledges (
+    id SERIAL PRIMARY KEY,
+    group_buy_id INTEGER NOT NULL REFERENCES group_buys(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    hold_id INTEGER NOT NULL REFERENCES balance_holds(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_group_buy_pledges_group_buy ON group_buy_pledges(group_buy_id);
+
+-- покупка, оплаченная взносами участников: деньги в выписке - это hold_capture каждого участника
+ALTER TABLE purchases ADD COLUMN group_buy_id INTEGER REFERENCES group_buys(id);
@@ -0,0 +1,23 @@
+-- один отзыв пользователя на товар; скрытые модератором отзывы не показываются и не входят в рейтинг
+CREATE TABLE merch_reviews (
+    id SERIAL PRIMARY KEY,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body VARCHAR(500) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'published',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    moderated_by INTEGER REFERENCES users(id),
+    moderated_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (merch_id, user_id)
+);
+
+CREATE INDEX idx_merch_reviews_status ON merch_reviews(status, created_at);
+
+-- агрегаты для каталога: обновляются приращениями в транзакции изменения отзыва
+CREATE TABLE merch_review_stats (
+    merch_id INTEGER PRIMARY KEY REFERENCES merch(id),
+    review_count INTEGER NOT NULL DEFAULT 0 CHECK (review_count >= 0),
+    rating_sum INTEGER NOT NULL DEFAULT 0 CHECK (rating_sum >= 0)
+);
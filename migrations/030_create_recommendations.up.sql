@@ -0,0 +1,17 @@
+-- рекомендации пересчитываются фоновой задачей целиком; покупки отмененных заказов не учитываются.
+-- buyers - число разных пользователей, купивших оба товара (или товар в команде)
+CREATE TABLE merch_also_bought (
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    related_merch_id INTEGER NOT NULL REFERENCES merch(id),
+    buyers INTEGER NOT NULL CHECK (buyers > 0),
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (merch_id, related_merch_id)
+);
+
+CREATE TABLE team_popular_merch (
+    team VARCHAR(50) NOT NULL,
+    merch_id INTEGER NOT NULL REFERENCES merch(id),
+    buyers INTEGER NOT NULL CHECK (buyers > 0),
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (team, merch_id)
+);
@@ -0,0 +1,15 @@
+-- набор - товар, составленный из других товаров; своего остатка у набора нет, он считается по составляющим
+ALTER TABLE merch ADD COLUMN is_bundle BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE merch_bundle_components (
+    bundle_id INTEGER NOT NULL REFERENCES merch(id),
+    component_id INTEGER NOT NULL REFERENCES merch(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (bundle_id, component_id),
+    CHECK (bundle_id <> component_id)
+);
+
+CREATE INDEX idx_merch_bundle_components_component ON merch_bundle_components(component_id);
+
+-- состав купленного набора: покупки составляющих с нулевой ценой ссылаются на покупку набора
+ALTER TABLE purchases ADD COLUMN bundle_purchase_id INTEGER REFERENCES purchases(id);
@@ -0,0 +1,21 @@
+-- уведомления об изменениях каталога: каждый экземпляр приложения слушает канал и сбрасывает кэш.
+-- Списание и возврат остатков (UPDATE только stock) уведомлений не шлют, иначе кэш сбрасывался бы на каждой покупке
+CREATE FUNCTION notify_merch_catalog() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('merch_catalog', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER merch_catalog_changed
+    AFTER INSERT OR DELETE OR UPDATE OF name, price, currency, category, description, tags, attributes, active,
+        retired_at, available_from, available_until, drop_cap, is_bundle ON merch
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_merch_catalog();
+
+CREATE TRIGGER merch_prices_changed
+    AFTER INSERT OR UPDATE OR DELETE ON merch_prices
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_merch_catalog();
+
+CREATE TRIGGER merch_review_stats_changed
+    AFTER INSERT OR UPDATE OR DELETE ON merch_review_stats
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_merch_catalog();
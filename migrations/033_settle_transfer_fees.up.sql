@@ -0,0 +1,8 @@
+-- комиссия зачисляется на системный счет отдельной задачей, а не в транзакции перевода:
+-- иначе все платные переводы выстраиваются в очередь за блокировкой одной строки users.
+-- fee_settled_at - когда комиссия зачислена, NULL - еще не зачислена
+ALTER TABLE coin_transactions ADD COLUMN fee_settled_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE coin_transactions SET fee_settled_at = created_at WHERE fee > 0;
+
+CREATE INDEX idx_coin_transactions_unsettled_fee ON coin_transactions (id) WHERE fee > 0 AND fee_settled_at IS NULL;
@@ -0,0 +1,4 @@
+-- приз розыгрыша: бесплатная покупка, которую нельзя отменить как обычный заказ
+ALTER TABLE purchases ADD COLUMN raffle_id INTEGER REFERENCES raffles(id);
+
+UPDATE purchases p SET raffle_id = w.raffle_id FROM raffle_winners w WHERE p.order_id = w.order_id;
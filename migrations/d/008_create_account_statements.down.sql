@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS account_statements_immutable ON account_statements;
+DROP FUNCTION IF EXISTS forbid_statement_changes();
+DROP TABLE IF EXISTS account_statements;
@@ -0,0 +1,4 @@
+ALTER TABLE coin_transactions
+    DROP COLUMN IF EXISTS fee_account_id,
+    DROP COLUMN IF EXISTS fee;
+DELETE FROM users WHERE username = 'system:fees';
@@ -0,0 +1,5 @@
+ALTER TABLE coin_transactions DROP COLUMN IF EXISTS currency;
+ALTER TABLE purchases DROP COLUMN IF EXISTS currency;
+ALTER TABLE merch DROP COLUMN IF EXISTS currency;
+DROP TABLE IF EXISTS wallets;
+DROP TABLE IF EXISTS currencies;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS balance_holds;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
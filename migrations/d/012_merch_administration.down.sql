@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS merch_audit;
+DROP INDEX IF EXISTS idx_purchases_merch_id;
+ALTER TABLE purchases DROP COLUMN IF EXISTS merch_id;
+ALTER TABLE merch
+    DROP CONSTRAINT IF EXISTS merch_price_positive,
+    ALTER COLUMN price DROP NOT NULL,
+    DROP COLUMN IF EXISTS active,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS retired_at;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS cart_items;
+DROP INDEX IF EXISTS idx_purchases_order_id;
+ALTER TABLE purchases
+    DROP COLUMN IF EXISTS order_id,
+    DROP COLUMN IF EXISTS quantity;
+DROP TABLE IF EXISTS orders;
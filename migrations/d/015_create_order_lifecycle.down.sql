@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS order_status_history;
+DROP INDEX IF EXISTS idx_orders_status;
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_status_check,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,6 @@
+ALTER TABLE purchases
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS promo_code_id;
+DROP TABLE IF EXISTS promo_code_redemptions;
+DROP TABLE IF EXISTS promo_codes;
+ALTER TABLE merch DROP COLUMN IF EXISTS category;
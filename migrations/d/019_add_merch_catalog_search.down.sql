@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_purchases_merch_id;
+DROP INDEX IF EXISTS idx_merch_category;
+DROP INDEX IF EXISTS idx_merch_tags;
+DROP INDEX IF EXISTS idx_merch_search_vector;
+ALTER TABLE merch
+    DROP COLUMN IF EXISTS search_vector,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS description;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS merch_images;
+ALTER TABLE merch DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,10 @@
+DELETE FROM cart_items WHERE variant_id <> 0;
+ALTER TABLE cart_items DROP CONSTRAINT cart_items_pkey;
+ALTER TABLE cart_items ADD PRIMARY KEY (user_id, merch_id);
+ALTER TABLE cart_items DROP COLUMN IF EXISTS variant_id;
+
+ALTER TABLE purchases
+    DROP COLUMN IF EXISTS variant_id,
+    DROP COLUMN IF EXISTS sku;
+
+DROP TABLE IF EXISTS merch_variants;
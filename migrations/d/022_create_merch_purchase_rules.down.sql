@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_purchases_user_merch_date;
+DROP TABLE IF EXISTS merch_purchase_rules;
+ALTER TABLE users DROP COLUMN IF EXISTS team;
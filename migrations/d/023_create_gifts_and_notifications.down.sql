@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS notifications;
+DROP INDEX IF EXISTS idx_purchases_gifted_by;
+ALTER TABLE purchases
+    DROP COLUMN IF EXISTS gifted_by,
+    DROP COLUMN IF EXISTS gift_message;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS wishlist_items;
+ALTER TABLE users DROP COLUMN IF EXISTS wishlist_shared;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS merch_drop_shards;
+ALTER TABLE merch DROP CONSTRAINT IF EXISTS merch_availability_window;
+ALTER TABLE merch DROP COLUMN IF EXISTS drop_cap;
+ALTER TABLE merch DROP COLUMN IF EXISTS available_until;
+ALTER TABLE merch DROP COLUMN IF EXISTS available_from;
@@ -0,0 +1,4 @@
+ALTER TABLE purchases DROP COLUMN IF EXISTS hold_id;
+ALTER TABLE auctions DROP CONSTRAINT IF EXISTS auctions_top_bid_fk;
+DROP TABLE IF EXISTS auction_bids;
+DROP TABLE IF EXISTS auctions;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS raffle_winners;
+DROP TABLE IF EXISTS raffle_entries;
+DROP TABLE IF EXISTS raffles;
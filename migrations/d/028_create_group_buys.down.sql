@@ -0,0 +1,3 @@
+ALTER TABLE purchases DROP COLUMN IF EXISTS group_buy_id;
+DROP TABLE IF EXISTS group_buy_pledges;
+DROP TABLE IF EXISTS group_buys;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS merch_review_stats;
+DROP TABLE IF EXISTS merch_reviews;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS team_popular_merch;
+DROP TABLE IF EXISTS merch_also_bought;
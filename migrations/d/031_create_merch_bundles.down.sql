@@ -0,0 +1,3 @@
+ALTER TABLE purchases DROP COLUMN IF EXISTS bundle_purchase_id;
+DROP TABLE IF EXISTS merch_bundle_components;
+ALTER TABLE merch DROP COLUMN IF EXISTS is_bundle;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS merch_review_stats_changed ON merch_review_stats;
+DROP TRIGGER IF EXISTS merch_prices_changed ON merch_prices;
+DROP TRIGGER IF EXISTS merch_catalog_changed ON merch;
+DROP FUNCTION IF EXISTS notify_merch_catalog();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_coin_transactions_unsettled_fee;
+ALTER TABLE coin_transactions DROP COLUMN IF EXISTS fee_settled_at;